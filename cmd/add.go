@@ -1,67 +1,163 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
+	"strings"
 
+	"devex/cmd/i18n"
 	"devex/cmd/project"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	addPath string
+	addPath   string
+	addDryRun bool
+	addLangs  []string
+
+	// 批量执行
+	addRepos     string
+	addGlob      string
+	addWorkspace string
+	addJobs      int
+
+	// 添加后创建分支和提交
+	addGit project.GitCommitOptions
+
+	// 生成的 CI 配置
+	addCI project.CIOptions
 )
 
 var addCmd = &cobra.Command{
 	Use:   "add",
-	Short: "添加代码审查功能",
-	Long: `为项目添加代码审查功能，包含以下功能：
-  - 代码风格检查配置
-  - 代码敏感信息检查工具
-  - 代码审查模板
-  - github CI配置
-
-示例：
-  # 为当前目录的项目添加功能
-  devex add
-
-  # 为指定路径的项目添加功能
-  devex add --path /path/to/project`,
+	Short: i18n.T("cmd.add.short"),
+	Long:  i18n.T("cmd.add.long"),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("为项目添加代码审查功能\n")
-		fmt.Printf("项目路径：%s\n", addPath)
+		for _, lang := range addLangs {
+			if !IsLanguageSupported(lang) {
+				failf(project.ErrUsage, "cmd.languages.unsupported_list", lang, GetSupportedLanguagesText())
+			}
+		}
+
+		if addGit.Message != "" && !addGit.Commit {
+			failf(project.ErrUsage, "cmd.add.message_without_commit")
+		}
+		if err := addCI.Validate(); err != nil {
+			fail(err)
+		}
 
-		// 使用add命令专用的初始化器
-		initializer, err := project.NewInitializerForAdd(addPath)
+		if addRepos != "" || addGlob != "" {
+			if cmd.Flags().Changed("path") {
+				failf(project.ErrUsage, "cmd.add.batch.path_conflict")
+			}
+			runAddBatch()
+			return
+		}
+
+		reporter.Info(i18n.T("cmd.add.start"))
+		reporter.Info(i18n.T("cmd.add.path", addPath))
+
+		// 写入文件前检查暂存区、分支和提交信息，不满足时不做任何修改
+		gitOpts := addGit
+		if gitOpts.Enabled() && !addDryRun {
+			var err error
+			if gitOpts, err = project.PrepareCommit(addPath, addGit); err != nil {
+				fail(err)
+			}
+		}
+
+		// 使用add命令专用的初始化器，未指定语言时自动检测
+		initializer, err := project.NewAddInitializer(addPath, addLangs, reporter)
 		if err != nil {
-			fmt.Printf("错误：%s\n", err)
-			os.Exit(1)
+			fail(err)
 		}
+		initializer.SetCI(addCI)
 
 		// 执行添加代码审查功能的步骤
-		steps := []struct {
-			name string
-			fn   func() error
-		}{
-			{"复制模板文件", initializer.CopyTemplateFiles},
-			{"安装Git钩子", initializer.InstallGitHooks},
-		}
+		steps := addSteps(initializer)
 
-		for _, step := range steps {
-			if err := step.fn(); err != nil {
-				fmt.Printf("错误：%s失败：%s\n", step.name, err)
-				os.Exit(1)
+		if addDryRun {
+			plan := project.NewPlan(addPath)
+			initializer.SetDryRun(plan)
+			if err := planSteps(steps, plan); err != nil {
+				fail(err)
 			}
+			return
+		}
+
+		journal := project.NewJournal()
+		initializer.SetJournal(journal)
+		if gitOpts.Enabled() {
+			steps = append(steps, commitStep(addPath, initializer, journal, reporter, gitOpts, nil))
+		}
+		if err := runSteps(steps, journal); err != nil {
+			fail(err)
 		}
 
 		initializer.ShowNextSteps()
 	},
 }
 
+// addSteps add 命令的步骤
+func addSteps(initializer project.Initializer) []step {
+	return []step{
+		{i18n.T("cmd.steps.copy_templates"), initializer.CopyTemplateFiles},
+		{i18n.T("cmd.steps.install_hooks"), initializer.InstallGitHooks},
+	}
+}
+
+// commitStep 创建分支并提交 devex 写入的文件的步骤，gitOpts 需已通过 project.PrepareCommit 检查
+// 有无法合并的文件或没有变更时不创建分支和提交；提交成功后调用 onCommit（可以为空）
+func commitStep(repo string, initializer *project.AddInitializer, journal *project.Journal, r project.Reporter, gitOpts project.GitCommitOptions, onCommit func(commit string)) step {
+	return step{i18n.T("cmd.steps.commit"), func() error {
+		// 有冲突的仓库需要先手动处理旁路文件
+		if len(initializer.Conflicts()) > 0 {
+			r.Hint(i18n.T("cmd.add.conflict_not_committed"))
+			return nil
+		}
+		if len(journal.Changed()) == 0 {
+			r.Hint(i18n.T("cmd.add.nothing_to_commit"))
+			return nil
+		}
+
+		r.Progress("📝", i18n.T("cmd.add.committing"))
+		commit, err := project.CommitFiles(repo, initializer.WrittenFiles(), gitOpts, r.Output())
+		if err != nil {
+			return err
+		}
+		if gitOpts.Branch != "" {
+			r.Success(i18n.T("cmd.add.branch_created", gitOpts.Branch))
+		}
+		if commit != "" {
+			r.Success(i18n.T("cmd.add.committed", commit, gitOpts.Message))
+		}
+		if onCommit != nil {
+			onCommit(commit)
+		}
+		return nil
+	}}
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
 
 	// 添加命令行选项
-	addCmd.Flags().StringVarP(&addPath, "path", "p", ".", "项目路径")
+	addCmd.Flags().StringVarP(&addPath, "path", "p", ".", i18n.T("cmd.flags.path"))
+	addCmd.Flags().StringSliceVarP(&addLangs, "lang", "l", nil, i18n.T("cmd.add.flag.lang"))
+	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, i18n.T("cmd.flags.dry_run"))
+	bindConfig(addCmd.Flags(), "path", "path")
+	bindConfig(addCmd.Flags(), "lang", "lang")
+
+	addCmd.Flags().StringVar(&addRepos, "repos", "", i18n.T("cmd.add.flag.repos"))
+	addCmd.Flags().StringVar(&addGlob, "glob", "", i18n.T("cmd.add.flag.glob"))
+	addCmd.Flags().StringVar(&addWorkspace, "workspace", ".", i18n.T("cmd.add.flag.workspace"))
+	addCmd.Flags().IntVarP(&addJobs, "jobs", "j", 4, i18n.T("cmd.add.flag.jobs"))
+	addCmd.Flags().StringVar(&addGit.Branch, "branch", "", i18n.T("cmd.add.flag.branch"))
+	addCmd.Flags().BoolVar(&addGit.Commit, "commit", false, i18n.T("cmd.add.flag.commit"))
+	addCmd.Flags().StringVarP(&addGit.Message, "message", "m", "", i18n.T("cmd.add.flag.message"))
+	addCmd.Flags().StringVar(&addCI.Provider, "ci", project.CINone, i18n.T("cmd.flags.ci", strings.Join(project.GetCIProviders(), ", ")))
+	addCmd.Flags().StringVar(&addCI.DefaultBranch, "ci-branch", "", i18n.T("cmd.flags.ci_branch"))
+	addCmd.Flags().StringVar(&addCI.Version, "ci-version", "", i18n.T("cmd.flags.ci_version"))
+	bindConfig(addCmd.Flags(), "ci", "ci.provider")
+	bindConfig(addCmd.Flags(), "ci-branch", "ci.branch")
+	bindConfig(addCmd.Flags(), "ci-version", "ci.version")
 }
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+)
+
+// runAddBatch 对 --repos 列表和 --glob 匹配到的仓库并发执行 add，各仓库的失败互不影响，最后输出汇总表
+func runAddBatch() {
+	if addJobs < 1 {
+		failf(project.ErrUsage, "cmd.add.batch.bad_jobs", addJobs)
+	}
+
+	repos, skipped, err := batchRepos()
+	if err != nil {
+		fail(err)
+	}
+	if len(repos) == 0 && len(skipped) == 0 {
+		failf(project.ErrUsage, "cmd.add.batch.no_repos")
+	}
+
+	// 模板源只获取一次，各仓库共用
+	if err := project.PrepareTemplates(); err != nil {
+		fail(err)
+	}
+
+	reporter.Info(i18n.T("cmd.add.batch.start", len(repos), addJobs))
+
+	journals := &batchJournals{active: make(map[*project.Journal]string)}
+	stop := journals.rollbackOnInterrupt()
+	defer stop()
+
+	var outputMu sync.Mutex
+	results := project.RunBatch(repos, addJobs, func(repo string) project.BatchResult {
+		var buf bytes.Buffer
+		r := project.ForRepo(reporter, repo, &buf)
+		result := addRepo(repo, r, &buf, journals)
+
+		// 文本输出按仓库整体输出，避免并发时交错
+		if !jsonOutput() {
+			outputMu.Lock()
+			fmt.Printf("\n📁 %s\n", repo)
+			os.Stdout.Write(buf.Bytes())
+			outputMu.Unlock()
+		}
+		return result
+	})
+	results = append(results, skipped...)
+
+	if jsonOutput() {
+		reporter.Result("batch", results)
+	} else {
+		project.PrintBatchSummary(os.Stdout, results)
+	}
+	if err := project.BatchFailure(results); err != nil {
+		fail(err)
+	}
+}
+
+// batchRepos 合并 --repos 列表和 --glob 匹配到的仓库，同时返回 --glob 匹配到但不是 Git 仓库的路径
+func batchRepos() ([]string, []project.BatchResult, error) {
+	var repos []string
+	var skipped []project.BatchResult
+	if addRepos != "" {
+		listed, err := project.ReadRepoList(addRepos)
+		if err != nil {
+			return nil, nil, err
+		}
+		repos = append(repos, listed...)
+	}
+	if addGlob != "" {
+		matched, notRepos, err := project.GlobRepos(addWorkspace, addGlob)
+		if err != nil {
+			return nil, nil, err
+		}
+		repos = append(repos, matched...)
+		skipped = notRepos
+	}
+	return project.UniqueRepos(repos), skipped, nil
+}
+
+// addRepo 对单个仓库执行 add，输出通过 r，文本输出中的预演计划写入 w
+// 失败时该仓库的修改已回滚，错误记录在结果中而不是退出
+func addRepo(repo string, r project.Reporter, w io.Writer, journals *batchJournals) project.BatchResult {
+	result := project.BatchResult{Repo: repo}
+	failed := func(err error) project.BatchResult {
+		result.Status = project.BatchFailed
+		result.Code = project.ErrorCodeOf(err)
+		result.Error = err.Error()
+		r.Error(result.Code, err)
+		return result
+	}
+
+	if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+		return failed(project.Errorf(project.ErrConfig, "cmd.add.batch.not_dir", repo))
+	}
+	initializer, err := project.NewAddInitializer(repo, addLangs, r)
+	if err != nil {
+		return failed(err)
+	}
+	// 未指定分支时按各仓库的默认分支生成 CI 配置
+	initializer.SetCI(addCI)
+	steps := addSteps(initializer)
+
+	if addDryRun {
+		plan := project.NewPlan(repo)
+		initializer.SetDryRun(plan)
+		if err := execPlan(r, w, steps, plan); err != nil {
+			return failed(err)
+		}
+		for _, change := range plan.Changes {
+			if change.Kind != project.ChangeUnchanged {
+				result.Files = append(result.Files, change.Path)
+			}
+		}
+		return batchStatus(result, repo, initializer.Conflicts())
+	}
+
+	journal := project.NewJournal()
+	initializer.SetJournal(journal)
+	journals.add(journal, repo)
+	defer journals.remove(journal)
+
+	if addGit.Enabled() {
+		// 各仓库的暂存区和提交信息规范不同，分别检查
+		gitOpts, err := project.PrepareCommit(repo, addGit)
+		if err != nil {
+			return failed(err)
+		}
+		steps = append(steps, commitStep(repo, initializer, journal, r, gitOpts, func(commit string) {
+			result.Branch = gitOpts.Branch
+			result.Commit = commit
+		}))
+	}
+
+	if err := execSteps(r, steps, journal); err != nil {
+		return failed(err)
+	}
+	for _, path := range journal.Changed() {
+		result.Files = append(result.Files, relToRepo(repo, path))
+	}
+	return batchStatus(result, repo, initializer.Conflicts())
+}
+
+// batchStatus 根据写入的文件和冲突确定仓库的结果
+func batchStatus(result project.BatchResult, repo string, conflicts []project.FileConflict) project.BatchResult {
+	for _, conflict := range conflicts {
+		result.Conflicts = append(result.Conflicts, relToRepo(repo, conflict.Path))
+	}
+	switch {
+	case len(result.Conflicts) > 0:
+		result.Status = project.BatchConflicted
+	case len(result.Files) == 0:
+		result.Status = project.BatchSkipped
+	default:
+		result.Status = project.BatchApplied
+	}
+	return result
+}
+
+// relToRepo 获取相对于仓库根目录的路径，用于输出
+func relToRepo(repo, path string) string {
+	if rel, err := filepath.Rel(repo, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// batchJournals 批量执行中正在处理的仓库的变更日志，收到中断信号时统一回滚
+type batchJournals struct {
+	mu     sync.Mutex
+	active map[*project.Journal]string // 变更日志及所属的仓库
+}
+
+func (b *batchJournals) add(journal *project.Journal, repo string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.active[journal] = repo
+}
+
+func (b *batchJournals) remove(journal *project.Journal) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.active, journal)
+}
+
+// rollbackOnInterrupt 收到中断信号（Ctrl-C）时回滚所有正在处理的仓库并退出，已完成的仓库保持不变
+// 返回的函数用于停止监听
+func (b *batchJournals) rollbackOnInterrupt() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			reporter.Info("\n⚠️  " + i18n.T("cmd.steps.interrupted"))
+			b.mu.Lock()
+			// 各仓库缓存的文本输出不再输出，回滚结果直接输出到终端
+			for journal, repo := range b.active {
+				if !jsonOutput() {
+					fmt.Printf("📁 %s\n", repo)
+				}
+				rollback(project.ForRepo(reporter, repo, os.Stdout), journal)
+			}
+			b.mu.Unlock()
+			failf(project.ErrInterrupted, "cmd.steps.aborted")
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
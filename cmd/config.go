@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"devex/cmd/i18n"
 	"devex/cmd/project"
 )
 
@@ -48,11 +49,11 @@ func IsLanguageSupported(lang string) bool {
 
 // GetSupportedLanguagesText 返回支持的语言列表文本
 func GetSupportedLanguagesText() string {
-	text := "支持的编程语言：\n"
+	text := i18n.T("cmd.languages.title") + "\n"
 	languages := getSupportedLanguages()
 
-	for key, lang := range languages {
-		text += fmt.Sprintf("  - %-8s %s\n", key+":", lang.Name)
+	for _, key := range project.GetSupportedLanguages() {
+		text += fmt.Sprintf("  - %-8s %s\n", key+":", languages[key].Name)
 	}
 	return text
 }
@@ -63,5 +64,5 @@ func GetLanguageConfig(lang string) (Language, error) {
 	if config, ok := languages[lang]; ok {
 		return config, nil
 	}
-	return Language{}, fmt.Errorf("不支持的编程语言: %s", lang)
+	return Language{}, i18n.Errorf("cmd.languages.unsupported", lang)
 }
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configKeyAnnotation 命令行参数对应的配置项，未指定参数时使用配置值
+const configKeyAnnotation = "devex_config_key"
+
+var (
+	configRepo bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: i18n.T("cmd.config.short"),
+	Long:  i18n.T("cmd.config.long"),
+}
+
+var configGetCmd = &cobra.Command{
+	Use:         "get",
+	Annotations: map[string]string{argsAnnotation: "cmd.config.args.key"},
+	Short:       i18n.T("cmd.config.get.short"),
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value, origin, err := project.CurrentSettings().Lookup(args[0])
+		if err != nil {
+			fail(err)
+		}
+		if jsonOutput() {
+			reporter.Result("config", configEntry{Key: args[0], Value: value, Origin: origin})
+			return
+		}
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:         "set",
+	Annotations: map[string]string{argsAnnotation: "cmd.config.args.key cmd.config.args.value"},
+	Short:       i18n.T("cmd.config.set.short"),
+	Args:        cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := configTargetFile()
+		if err != nil {
+			fail(err)
+		}
+		if err := file.Set(args[0], args[1]); err != nil {
+			fail(err)
+		}
+		if err := file.Save(); err != nil {
+			fail(err)
+		}
+		reporter.Success(i18n.T("cmd.config.written", file.Path))
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:         "unset",
+	Annotations: map[string]string{argsAnnotation: "cmd.config.args.key"},
+	Short:       i18n.T("cmd.config.unset.short"),
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := project.LookupConfigKey(args[0]); err != nil {
+			fail(err)
+		}
+		file, err := configTargetFile()
+		if err != nil {
+			fail(err)
+		}
+		if !file.Unset(args[0]) {
+			reporter.Info(i18n.T("cmd.config.not_set", file.Path, args[0]))
+			return
+		}
+		if err := file.Save(); err != nil {
+			fail(err)
+		}
+		reporter.Success(i18n.T("cmd.config.removed", file.Path, args[0]))
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("cmd.config.list.short"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		settings := project.CurrentSettings()
+
+		var names []string
+		for _, key := range project.ConfigKeys() {
+			names = append(names, key.Name)
+		}
+		names = append(names, settings.ValueKeys()...)
+
+		if jsonOutput() {
+			var entries []configEntry
+			var lookupErr error
+			for _, name := range names {
+				value, origin, err := settings.Lookup(name)
+				if err != nil {
+					lookupErr = err
+					continue
+				}
+				entries = append(entries, configEntry{Key: name, Value: value, Origin: origin})
+			}
+			reporter.Result("config", entries)
+			if lookupErr != nil {
+				fail(project.WithDefaultCode(project.ErrConfig, lookupErr))
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("cmd.config.header"))
+		failed := false
+		for _, name := range names {
+			key, _ := project.LookupConfigKey(name)
+			value, origin, err := settings.Lookup(name)
+			if err != nil {
+				fmt.Fprintf(w, "%s\t\t\t❌ %s\n", name, err)
+				failed = true
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, value, configOriginText(key, origin), key.Describe())
+		}
+		w.Flush()
+
+		fmt.Println("\n" + i18n.T("cmd.config.user_path", settings.User.Path))
+		fmt.Println(i18n.T("cmd.config.repo_path", settings.Repo.Path))
+		if failed {
+			os.Exit(project.ExitCode(project.ErrConfig))
+		}
+	},
+}
+
+// configEntry JSON 输出中的配置项
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// configOriginText 配置来源的说明
+func configOriginText(key project.ConfigKey, origin string) string {
+	switch origin {
+	case project.OriginEnv:
+		return i18n.T("cmd.config.origin.env", key.Env)
+	case project.OriginRepo:
+		return i18n.T("cmd.config.origin.repo")
+	case project.OriginUser:
+		return i18n.T("cmd.config.origin.user")
+	default:
+		return i18n.T("cmd.config.origin.builtin")
+	}
+}
+
+// configTargetFile config set/unset 修改的配置文件
+func configTargetFile() (*project.ConfigFile, error) {
+	if configRepo {
+		return project.CurrentSettings().Repo, nil
+	}
+	return project.CurrentSettings().User, nil
+}
+
+// bindConfig 把命令行参数绑定到配置项，未指定参数时使用环境变量或配置文件中的值
+func bindConfig(flags *pflag.FlagSet, flag, key string) {
+	if err := flags.SetAnnotation(flag, configKeyAnnotation, []string{key}); err != nil {
+		panic(err)
+	}
+}
+
+// loadSettings 加载配置，并把配置值填入命令中未指定的参数
+func loadSettings(cmd *cobra.Command) error {
+	dir := "."
+	if f := cmd.Flags().Lookup("path"); f != nil && f.Changed {
+		dir = f.Value.String()
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	settings, err := project.LoadSettings(dir)
+	if err != nil {
+		return err
+	}
+
+	var applyErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		keys := f.Annotations[configKeyAnnotation]
+		if applyErr != nil || len(keys) == 0 || f.Changed {
+			return
+		}
+		value, origin, err := settings.Lookup(keys[0])
+		if err != nil {
+			applyErr = err
+			return
+		}
+		if origin == project.OriginBuiltin || value == "" {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			applyErr = i18n.Errorf("cmd.config.flag_value", keys[0], f.Name, err)
+		}
+	})
+	return applyErr
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd)
+
+	configCmd.PersistentFlags().BoolVar(&configRepo, "repo", false, i18n.T("cmd.config.flag.repo"))
+}
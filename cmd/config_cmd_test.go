@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"devex/cmd/project"
+)
+
+// TestBoundFlagTypes 绑定到配置项的参数类型与配置项类型一致，列表配置项对应可重复、逗号分隔的参数
+func TestBoundFlagTypes(t *testing.T) {
+	want := map[string]string{
+		project.ConfigTypeString: "string",
+		project.ConfigTypeBool:   "bool",
+		project.ConfigTypeInt:    "int",
+		project.ConfigTypeList:   "stringSlice",
+	}
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		check := func(f *pflag.Flag) {
+			keys := f.Annotations[configKeyAnnotation]
+			if len(keys) == 0 {
+				return
+			}
+			key, err := project.LookupConfigKey(keys[0])
+			if err != nil {
+				t.Errorf("%s --%s: %v", cmd.CommandPath(), f.Name, err)
+				return
+			}
+			if got := f.Value.Type(); got != want[key.Type] {
+				t.Errorf("%s --%s is %s, config key %s is %s", cmd.CommandPath(), f.Name, got, key.Name, key.Type)
+			}
+		}
+		cmd.LocalFlags().VisitAll(check)
+		for _, sub := range cmd.Commands() {
+			visit(sub)
+		}
+	}
+	visit(rootCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorPath  string
+	doctorLangs []string
+	doctorJSON  bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: i18n.T("cmd.doctor.short"),
+	Long:  i18n.T("cmd.doctor.long"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// --json 是 --output json 的别名
+		if doctorJSON {
+			if err := setOutput(project.OutputJSON); err != nil {
+				fail(err)
+			}
+		}
+		for _, lang := range doctorLangs {
+			if !IsLanguageSupported(lang) {
+				failf(project.ErrUsage, "cmd.languages.unsupported", lang)
+			}
+		}
+
+		results, err := project.NewDoctor(doctorPath, doctorLangs).Run()
+		if err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			reporter.Result("doctor", results)
+		} else {
+			printDoctorResults(results)
+		}
+
+		if project.HasFailures(results) {
+			exitFindings(i18n.T("cmd.doctor.failed"))
+		}
+	},
+}
+
+// printDoctorResults 以表格形式输出诊断结果
+func printDoctorResults(results []project.CheckResult) {
+	labels := map[project.CheckStatus]string{
+		project.CheckPass: "PASS",
+		project.CheckWarn: "WARN",
+		project.CheckFail: "FAIL",
+	}
+	counts := map[project.CheckStatus]int{}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("cmd.doctor.header"))
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", labels[result.Status], result.Category, result.Name, result.Detail)
+	}
+	w.Flush()
+
+	fmt.Println("\n" + i18n.T("cmd.doctor.summary",
+		counts[project.CheckPass], counts[project.CheckWarn], counts[project.CheckFail]))
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&doctorPath, "path", "p", ".", i18n.T("cmd.flags.path"))
+	doctorCmd.Flags().StringSliceVar(&doctorLangs, "lang", nil, i18n.T("cmd.doctor.flag.lang"))
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, i18n.T("cmd.doctor.flag.json"))
+	bindConfig(doctorCmd.Flags(), "path", "path")
+	bindConfig(doctorCmd.Flags(), "lang", "lang")
+}
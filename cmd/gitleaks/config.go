@@ -0,0 +1,430 @@
+// Package gitleaks 提供 .gitleaks.toml 配置文件的读取、合并与写回，以及不依赖 gitleaks 可执行文件的内置扫描器
+// 只支持 devex 模板用到的配置子集，不支持的字段解析时忽略（见 UnknownKeys），
+// 写回时在原文上局部修改（见 patch.go），注释、顺序和不支持的字段都会保留
+package gitleaks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"devex/cmd/i18n"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config .gitleaks.toml 配置
+type Config struct {
+	Title     string     `toml:"title"`
+	Extend    *Extend    `toml:"extend"`
+	Rules     []Rule     `toml:"rules"`
+	Allowlist *Allowlist `toml:"allowlist"`
+	Devex     *Devex     `toml:"devex"`
+
+	header  string   // 第一个配置段之前的注释，写回时保留在文件开头
+	unknown []string // 不支持的字段，解析时忽略
+	source  []byte   // 解析的原文，写回时在原文上局部修改
+	orig    *Config  // 解析原文得到的配置，写回时与当前配置比较找出修改
+}
+
+// Extend [extend] 配置段
+type Extend struct {
+	UseDefault    bool     `toml:"useDefault"`
+	Path          string   `toml:"path"`
+	DisabledRules []string `toml:"disabledRules"`
+}
+
+// Rule [[rules]] 检测规则
+type Rule struct {
+	ID          string     `toml:"id"`
+	Description string     `toml:"description"`
+	Regex       string     `toml:"regex"`
+	SecretGroup int        `toml:"secretGroup"`
+	Entropy     float64    `toml:"entropy"`
+	Keywords    []string   `toml:"keywords"`
+	Path        string     `toml:"path"`
+	Tags        []string   `toml:"tags"`
+	Allowlist   *Allowlist `toml:"allowlist"`
+}
+
+// Allowlist 全局或规则级别的白名单
+type Allowlist struct {
+	Description string   `toml:"description"`
+	RegexTarget string   `toml:"regexTarget"`
+	Regexes     []string `toml:"regexes"`
+	Paths       []string `toml:"paths"`
+	Commits     []string `toml:"commits"`
+	StopWords   []string `toml:"stopwords"`
+}
+
+// Devex [devex] 配置段，记录 devex secrets ignore 添加的忽略项，gitleaks 不读取该配置段
+type Devex struct {
+	Ignores []Ignore `toml:"ignores"`
+}
+
+// Ignore 一条忽略记录，实际生效的内容写在 [allowlist]、规则的 allowlist、[extend] disabledRules 或 .gitleaksignore 中
+type Ignore struct {
+	Kind    string `toml:"kind" json:"kind"`                 // path、rule 或 fingerprint
+	Value   string `toml:"value" json:"value"`               // 路径正则、规则 id 或指纹
+	Rule    string `toml:"rule" json:"rule,omitempty"`       // 只对该规则生效的路径忽略
+	Reason  string `toml:"reason" json:"reason"`             // 忽略原因
+	Expires string `toml:"expires" json:"expires,omitempty"` // 过期日期 YYYY-MM-DD，为空表示不过期
+	Added   string `toml:"added" json:"added"`               // 添加日期 YYYY-MM-DD
+	// Preexisting 添加前 gitleaks 配置中已有相同的 allowlist 路径或禁用规则，删除忽略项时保留
+	Preexisting bool `toml:"preexisting,omitempty" json:"preexisting,omitempty"`
+}
+
+// Parse 解析配置内容，不支持的字段忽略并记录在 UnknownKeys 中
+func Parse(data []byte) (*Config, error) {
+	cfg, undecoded, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range undecoded {
+		cfg.unknown = append(cfg.unknown, key.String())
+	}
+
+	// 再解析一次作为原始配置，写回时与修改后的配置比较
+	cfg.orig, _, _ = decode(data)
+	cfg.source = data
+	return cfg, nil
+}
+
+// decode 解析配置内容，返回未解析的字段
+func decode(data []byte) (*Config, []toml.Key, error) {
+	var cfg Config
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return nil, nil, i18n.Errorf("gitleaks.parse_failed", err)
+	}
+	cfg.header = headerComments(data)
+	return &cfg, meta.Undecoded(), nil
+}
+
+// UnknownKeys 配置中不支持的字段，内置扫描器会忽略它们，写回时原样保留
+func (c *Config) UnknownKeys() []string {
+	return c.unknown
+}
+
+// Load 从文件读取配置
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Save 把配置写回文件，从文件读取的配置只修改发生变化的部分
+func (c *Config) Save(path string) error {
+	data, err := c.Patch()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Rule 根据 id 查找规则
+func (c *Config) Rule(id string) *Rule {
+	for i := range c.Rules {
+		if c.Rules[i].ID == id {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Merge 把 other 中的内容合并到当前配置
+// 已有规则保持不变，只追加缺少的规则；白名单各列表取并集
+func (c *Config) Merge(other *Config) {
+	if c.Title == "" {
+		c.Title = other.Title
+	}
+	if c.header == "" {
+		c.header = other.header
+	}
+
+	if other.Extend != nil {
+		if c.Extend == nil {
+			c.Extend = &Extend{}
+		}
+		c.Extend.UseDefault = c.Extend.UseDefault || other.Extend.UseDefault
+		if c.Extend.Path == "" {
+			c.Extend.Path = other.Extend.Path
+		}
+		c.Extend.DisabledRules = union(c.Extend.DisabledRules, other.Extend.DisabledRules)
+	}
+
+	for _, rule := range other.Rules {
+		if c.Rule(rule.ID) == nil {
+			c.Rules = append(c.Rules, rule)
+		}
+	}
+
+	if other.Allowlist != nil {
+		if c.Allowlist == nil {
+			c.Allowlist = &Allowlist{}
+		}
+		c.Allowlist.merge(other.Allowlist)
+	}
+
+	if other.Devex != nil {
+		if c.Devex == nil {
+			c.Devex = &Devex{}
+		}
+		for _, ignore := range other.Devex.Ignores {
+			if c.Devex.Ignore(ignore.Kind, ignore.Value, ignore.Rule) == nil {
+				c.Devex.Ignores = append(c.Devex.Ignores, ignore)
+			}
+		}
+	}
+}
+
+// Ignore 查找忽略记录
+func (d *Devex) Ignore(kind, value, rule string) *Ignore {
+	for i := range d.Ignores {
+		if d.Ignores[i].Kind == kind && d.Ignores[i].Value == value && d.Ignores[i].Rule == rule {
+			return &d.Ignores[i]
+		}
+	}
+	return nil
+}
+
+func (a *Allowlist) merge(other *Allowlist) {
+	if a.Description == "" {
+		a.Description = other.Description
+	}
+	if a.RegexTarget == "" {
+		a.RegexTarget = other.RegexTarget
+	}
+	a.Regexes = union(a.Regexes, other.Regexes)
+	a.Paths = union(a.Paths, other.Paths)
+	a.Commits = union(a.Commits, other.Commits)
+	a.StopWords = union(a.StopWords, other.StopWords)
+}
+
+// union 合并两个字符串列表并去重，保持原有顺序
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			seen[s] = true
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// Encode 把配置完整编码为 TOML 文本，不保留原文中的注释和不支持的字段
+// 正则等字符串优先使用 ”' 字面量，保持与手写配置一致的可读性
+func (c *Config) Encode() []byte {
+	var buf bytes.Buffer
+
+	if c.header != "" {
+		buf.WriteString(c.header)
+		buf.WriteString("\n")
+	}
+	buf.Write(c.encodeBody())
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n')
+}
+
+// encodeBody 编码文件开头注释之外的内容
+func (c *Config) encodeBody() []byte {
+	var buf bytes.Buffer
+
+	if c.Title != "" {
+		writeKey(&buf, "title", quote(c.Title))
+		buf.WriteString("\n")
+	}
+
+	if c.Extend != nil {
+		buf.WriteString("[extend]\n")
+		c.Extend.encode(&buf)
+		buf.WriteString("\n")
+	}
+
+	for _, rule := range c.Rules {
+		rule.encode(&buf)
+		buf.WriteString("\n")
+	}
+
+	if c.Allowlist != nil {
+		buf.WriteString("[allowlist]\n")
+		c.Allowlist.encode(&buf)
+		buf.WriteString("\n")
+	}
+
+	if c.Devex != nil {
+		for _, ignore := range c.Devex.Ignores {
+			buf.WriteString("[[devex.ignores]]\n")
+			ignore.encode(&buf)
+			buf.WriteString("\n")
+		}
+	}
+
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n')
+}
+
+func (e *Extend) encode(buf *bytes.Buffer) {
+	writeKey(buf, "useDefault", strconv.FormatBool(e.UseDefault))
+	if e.Path != "" {
+		writeKey(buf, "path", quote(e.Path))
+	}
+	if len(e.DisabledRules) > 0 {
+		writeKey(buf, "disabledRules", inlineArray(e.DisabledRules))
+	}
+}
+
+func (r *Rule) encode(buf *bytes.Buffer) {
+	buf.WriteString("[[rules]]\n")
+	writeKey(buf, "id", quote(r.ID))
+	if r.Description != "" {
+		writeKey(buf, "description", quote(r.Description))
+	}
+	if r.Regex != "" {
+		writeKey(buf, "regex", literal(r.Regex))
+	}
+	if r.SecretGroup != 0 {
+		writeKey(buf, "secretGroup", strconv.Itoa(r.SecretGroup))
+	}
+	if r.Entropy != 0 {
+		writeKey(buf, "entropy", strconv.FormatFloat(r.Entropy, 'f', -1, 64))
+	}
+	if r.Path != "" {
+		writeKey(buf, "path", literal(r.Path))
+	}
+	if len(r.Keywords) > 0 {
+		writeKey(buf, "keywords", inlineArray(r.Keywords))
+	}
+	if len(r.Tags) > 0 {
+		writeKey(buf, "tags", inlineArray(r.Tags))
+	}
+	if r.Allowlist != nil {
+		buf.WriteString("\n[rules.allowlist]\n")
+		r.Allowlist.encode(buf)
+	}
+}
+
+func (a *Allowlist) encode(buf *bytes.Buffer) {
+	if a.Description != "" {
+		writeKey(buf, "description", quote(a.Description))
+	}
+	if a.RegexTarget != "" {
+		writeKey(buf, "regexTarget", quote(a.RegexTarget))
+	}
+	lists := []struct {
+		key    string
+		values []string
+	}{
+		{"regexes", a.Regexes},
+		{"paths", a.Paths},
+		{"commits", a.Commits},
+		{"stopwords", a.StopWords},
+	}
+	for _, list := range lists {
+		if len(list.values) > 0 {
+			writeKey(buf, list.key, multilineArray(list.values))
+		}
+	}
+}
+
+func (i *Ignore) encode(buf *bytes.Buffer) {
+	writeKey(buf, "kind", quote(i.Kind))
+	writeKey(buf, "value", literal(i.Value))
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"rule", i.Rule},
+		{"reason", i.Reason},
+		{"expires", i.Expires},
+		{"added", i.Added},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			writeKey(buf, field.key, quote(field.value))
+		}
+	}
+	if i.Preexisting {
+		writeKey(buf, "preexisting", "true")
+	}
+}
+
+func writeKey(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + " = " + value + "\n")
+}
+
+// quote 编码为 TOML 基本字符串
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// literal 尽量编码为 ”' 多行字面量字符串，无法表示时退回基本字符串
+func literal(s string) string {
+	if strings.Contains(s, "'''") || strings.HasSuffix(s, "'") || strings.ContainsAny(s, "\r\n") {
+		return quote(s)
+	}
+	return "'''" + s + "'''"
+}
+
+func inlineArray(values []string) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = quote(v)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func multilineArray(values []string) string {
+	var sb strings.Builder
+	sb.WriteString("[\n")
+	for i, v := range values {
+		sb.WriteString("    " + literal(v))
+		if i < len(values)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// headerComments 提取第一个配置段（[xxx]）之前的注释
+func headerComments(data []byte) string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,368 @@
+package gitleaks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "supported", data: "title = \"x\"\n[extend]\nuseDefault = true\n", want: nil},
+		{
+			name: "unknown keys",
+			data: "version = 2\n[[rules]]\nid = \"a\"\nregex = 'a'\nsecretGroup = 1\nkeywords = [\"a\"]\n[rules.allowlist]\ncondition = \"AND\"\n",
+			want: []string{"version", "rules.allowlist.condition"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.UnknownKeys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// patchSource 带注释、行尾注释和不支持字段的配置，用于检查局部修改
+const patchSource = `# project secrets config
+title = "demo"
+
+[extend]
+useDefault = true # built-in rules
+disabledRules = ["generic-api-key"]
+
+# team rules
+[[rules]]
+id = "token"
+regex = '''tok_[a-z0-9]{8}'''
+keywords = ["tok_"]
+condition = "AND" # not supported by devex
+
+[rules.allowlist]
+paths = [
+    '''fixtures/''', # test data
+]
+
+[[rules]]
+id = "key"
+regex = '''key_[a-z0-9]{8}'''
+
+[allowlist]
+paths = ['''^vendor/''']
+
+[[devex.ignores]]
+kind = "path"
+value = '''^docs/'''
+reason = "examples"
+`
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		edit    func(c *Config)
+		want    string // 为空表示与 source 相同
+		wantErr bool
+	}{
+		{name: "unchanged", source: patchSource, edit: func(c *Config) {}},
+		{
+			name:   "append to multiline array",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Rule("token").Allowlist.Paths = append(c.Rule("token").Allowlist.Paths, "^testdata/")
+			},
+			want: strings.Replace(patchSource, "    '''fixtures/''', # test data\n", "    '''fixtures/''', # test data\n    '''^testdata/''',\n", 1),
+		},
+		{
+			name:   "append to inline array",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "^build/")
+				c.Extend.DisabledRules = append(c.Extend.DisabledRules, "jwt")
+			},
+			want: strings.NewReplacer(
+				"paths = ['''^vendor/''']", "paths = ['''^vendor/''', '''^build/''']",
+				`disabledRules = ["generic-api-key"]`, `disabledRules = ["generic-api-key", "jwt"]`,
+			).Replace(patchSource),
+		},
+		{
+			name:   "add rule allowlist",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Rule("key").Allowlist = &Allowlist{Paths: []string{".*"}}
+			},
+			want: strings.Replace(patchSource, "regex = '''key_[a-z0-9]{8}'''\n", "regex = '''key_[a-z0-9]{8}'''\n\n[rules.allowlist]\npaths = [\n    '''.*'''\n]\n", 1),
+		},
+		{
+			name:   "remove rule allowlist",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Rule("token").Allowlist = nil
+			},
+			want: strings.Replace(patchSource, "[rules.allowlist]\npaths = [\n    '''fixtures/''', # test data\n]\n\n", "", 1),
+		},
+		{
+			name:   "append and remove ignores",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Devex.Ignores = []Ignore{{Kind: "path", Value: "^tmp/", Reason: "scratch", Expires: "2030-01-01"}}
+			},
+			want: strings.Replace(patchSource, "[[devex.ignores]]\nkind = \"path\"\nvalue = '''^docs/'''\nreason = \"examples\"\n",
+				"[[devex.ignores]]\nkind = \"path\"\nvalue = '''^tmp/'''\nreason = \"scratch\"\nexpires = \"2030-01-01\"\n", 1),
+		},
+		{
+			name:   "update ignore",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Devex.Ignores[0].Reason = "docs examples"
+			},
+			want: strings.Replace(patchSource, `reason = "examples"`, `reason = "docs examples"`, 1),
+		},
+		{
+			name:   "create allowlist and ignores",
+			source: "# header\n[extend]\nuseDefault = true\n",
+			edit: func(c *Config) {
+				c.Allowlist = &Allowlist{Paths: []string{"^a/"}}
+				c.Devex = &Devex{Ignores: []Ignore{{Kind: "path", Value: "^a/"}}}
+			},
+			want: "# header\n[extend]\nuseDefault = true\n\n[allowlist]\npaths = [\n    '''^a/'''\n]\n\n[[devex.ignores]]\nkind = \"path\"\nvalue = '''^a/'''\n",
+		},
+		{
+			name:   "append rule after last rule",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Rules = append(c.Rules, Rule{ID: "new", Regex: "new_[0-9]+", Tags: []string{"x"}})
+			},
+			want: strings.Replace(patchSource, "regex = '''key_[a-z0-9]{8}'''\n", "regex = '''key_[a-z0-9]{8}'''\n\n[[rules]]\nid = \"new\"\nregex = '''new_[0-9]+'''\ntags = [\"x\"]\n", 1),
+		},
+		{
+			name:   "change scalars keeps trailing comments",
+			source: patchSource,
+			edit: func(c *Config) {
+				c.Title = "renamed"
+				c.Extend.UseDefault = false
+			},
+			want: strings.NewReplacer(`title = "demo"`, `title = "renamed"`, "useDefault = true # built-in rules", "useDefault = false # built-in rules").Replace(patchSource),
+		},
+		{
+			name:    "remove rule",
+			source:  patchSource,
+			edit:    func(c *Config) { c.Rules = c.Rules[:1] },
+			wantErr: true,
+		},
+		{
+			name:    "change rule regex",
+			source:  patchSource,
+			edit:    func(c *Config) { c.Rules[1].Regex = "other" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(cfg)
+			got, err := cfg.Patch()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.source
+			}
+			if string(got) != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestPatchEdgeCases 带引号的键、带点的键、内联表、多行字符串和行尾注释都按 TOML 语法处理
+func TestPatchEdgeCases(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		edit   func(c *Config)
+		want   string
+	}{
+		{
+			name:   "quoted keys",
+			source: "[ \"extend\" ]\n\"useDefault\" = true\n\n[allowlist]\n'paths' = ['a']\n",
+			edit: func(c *Config) {
+				c.Extend.UseDefault = false
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "b")
+			},
+			want: "[ \"extend\" ]\n\"useDefault\" = false\n\n[allowlist]\n'paths' = ['a', '''b''']\n",
+		},
+		{
+			name:   "quoted key containing a dot",
+			source: "\"extend.useDefault\" = \"not the extend table\"\n\n[extend]\nuseDefault = true\n",
+			edit:   func(c *Config) { c.Extend.UseDefault = false },
+			want:   "\"extend.useDefault\" = \"not the extend table\"\n\n[extend]\nuseDefault = false\n",
+		},
+		{
+			name:   "dotted header with spaces",
+			source: "[[rules]]\nid = \"a\"\nregex = 'a'\n\n[ rules . allowlist ]\npaths = ['x']\n",
+			edit: func(c *Config) {
+				c.Rules[0].Allowlist.Paths = append(c.Rules[0].Allowlist.Paths, "y")
+			},
+			want: "[[rules]]\nid = \"a\"\nregex = 'a'\n\n[ rules . allowlist ]\npaths = ['x', '''y''']\n",
+		},
+		{
+			name:   "dotted keys at the root",
+			source: "extend.useDefault = true\nallowlist.paths = ['a']\n",
+			edit: func(c *Config) {
+				c.Extend.DisabledRules = []string{"jwt"}
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "b")
+			},
+			want: "extend.useDefault = true\nextend.disabledRules = [\"jwt\"]\nallowlist.paths = ['a', '''b''']\n",
+		},
+		{
+			name:   "dotted keys in a rule",
+			source: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist.paths = ['x']\n",
+			edit: func(c *Config) {
+				c.Rules[0].Allowlist.Paths = append(c.Rules[0].Allowlist.Paths, "y")
+				c.Rules[0].Allowlist.StopWords = []string{"example"}
+			},
+			want: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist.paths = ['x', '''y''']\nallowlist.stopwords = [\n    '''example'''\n]\n",
+		},
+		{
+			name:   "remove dotted table",
+			source: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist.paths = ['x']\nallowlist.stopwords = ['y']\nkeywords = ['a']\n",
+			edit:   func(c *Config) { c.Rules[0].Allowlist = nil },
+			want:   "[[rules]]\nid = \"a\"\nregex = 'a'\nkeywords = ['a']\n",
+		},
+		{
+			name:   "inline table keeps unknown members and comment",
+			source: "allowlist = { paths = ['a'], condition = \"OR\" } # shared\n",
+			edit: func(c *Config) {
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "b")
+				c.Allowlist.StopWords = []string{"example"}
+			},
+			want: "allowlist = { paths = ['''a''', '''b'''], condition = \"OR\", stopwords = ['''example'''] } # shared\n",
+		},
+		{
+			name:   "inline rule allowlist",
+			source: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist = {stopwords=[\"x\"]}\n",
+			edit: func(c *Config) {
+				c.Rules[0].Allowlist.Paths = []string{"p"}
+			},
+			want: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist = { stopwords=[\"x\"], paths = ['''p'''] }\n",
+		},
+		{
+			name:   "remove inline rule allowlist",
+			source: "[[rules]]\nid = \"a\"\nregex = 'a'\nallowlist = { paths = ['p'] }\nkeywords = ['a']\n",
+			edit:   func(c *Config) { c.Rules[0].Allowlist = nil },
+			want:   "[[rules]]\nid = \"a\"\nregex = 'a'\nkeywords = ['a']\n",
+		},
+		{
+			name:   "multi-line strings",
+			source: "[[rules]]\nid = \"a\"\ndescription = \"\"\"\nnot = a key\n[not.a.header]\n# not a comment \\\"\"\" still\n\"\"\"\nregex = '''\n(?x) a # ]\n'''\n\n[allowlist]\npaths = ['a']\n",
+			edit: func(c *Config) {
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "b")
+			},
+			want: "[[rules]]\nid = \"a\"\ndescription = \"\"\"\nnot = a key\n[not.a.header]\n# not a comment \\\"\"\" still\n\"\"\"\nregex = '''\n(?x) a # ]\n'''\n\n[allowlist]\npaths = ['a', '''b''']\n",
+		},
+		{
+			name:   "comments after values",
+			source: "title = \"x\" # name\n\n[allowlist]\npaths = ['a'] # ['b']\nstopwords = [ # words\n    'x', # first\n] # end\n",
+			edit: func(c *Config) {
+				c.Title = "y"
+				c.Allowlist.Paths = append(c.Allowlist.Paths, "c")
+				c.Allowlist.StopWords = append(c.Allowlist.StopWords, "z")
+			},
+			want: "title = \"y\" # name\n\n[allowlist]\npaths = ['a', '''c'''] # ['b']\nstopwords = [ # words\n    'x', # first\n    '''z''',\n] # end\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(cfg)
+			got, err := cfg.Patch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPatchTemplateConfig 在内置模板的配置上添加忽略项，原有内容逐行保留
+func TestPatchTemplateConfig(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("..", "..", "template", "global_config", ".gitleaks.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := cfg.Rule("chinese-comments")
+	if rule.Allowlist == nil {
+		rule.Allowlist = &Allowlist{}
+	}
+	rule.Allowlist.Paths = append(rule.Allowlist.Paths, "^docs/")
+	cfg.Devex = &Devex{Ignores: []Ignore{{Kind: "path", Value: "^docs/", Rule: "chinese-comments"}}}
+
+	got, err := cfg.Patch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), strings.SplitN(string(source), "[[rules]]", 2)[0]) {
+		t.Errorf("header comments changed")
+	}
+	gotLines := strings.Split(string(got), "\n")
+	i := 0
+	for _, line := range strings.Split(strings.TrimRight(string(source), "\n"), "\n") {
+		for i < len(gotLines) && gotLines[i] != line {
+			i++
+		}
+		if i == len(gotLines) {
+			t.Fatalf("line %q is missing or out of order", line)
+		}
+	}
+}
+
+func TestMergeThenPatch(t *testing.T) {
+	user, err := Parse([]byte("# mine\n[extend]\nuseDefault = true\n\n[[rules]]\nid = \"a\"\nregex = 'a' # keep\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := Parse([]byte("# template\n[[rules]]\nid = \"a\"\nregex = 'b'\n\n[[rules]]\nid = \"b\"\nregex = 'b'\n\n[allowlist]\nstopwords = [\"example\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Merge(template)
+	got, err := user.Patch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# mine\n[extend]\nuseDefault = true\n\n[[rules]]\nid = \"a\"\nregex = 'a' # keep\n\n[[rules]]\nid = \"b\"\nregex = '''b'''\n\n[allowlist]\nstopwords = [\n    '''example'''\n]\n"
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,373 @@
+package gitleaks
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devex/cmd/i18n"
+)
+
+// allowComment 行内包含该注释时忽略该行的发现，与 gitleaks 一致
+const allowComment = "gitleaks:allow"
+
+//go:embed default.toml
+var defaultConfigData []byte
+
+// DefaultConfig [extend] useDefault = true 时使用的内置规则
+func DefaultConfig() (*Config, error) {
+	cfg, err := Parse(defaultConfigData)
+	if err != nil {
+		return nil, i18n.Errorf("gitleaks.builtin_rules", err)
+	}
+	return cfg, nil
+}
+
+// Fragment 需要扫描的一段内容，通常是一个文件
+type Fragment struct {
+	Raw       string
+	FilePath  string
+	CommitSHA string
+}
+
+// Detector 不依赖 gitleaks 可执行文件的内置扫描器
+// 支持规则的 regex、secretGroup、entropy、keywords、path 和 allowlist，以及全局 [allowlist] 和 [extend] useDefault
+type Detector struct {
+	rules     []*compiledRule
+	allowlist *compiledAllowlist
+}
+
+type compiledRule struct {
+	Rule
+	regex     *regexp.Regexp
+	path      *regexp.Regexp
+	keywords  []string
+	allowlist *compiledAllowlist
+}
+
+type compiledAllowlist struct {
+	regexTarget string
+	regexes     []*regexp.Regexp
+	paths       []*regexp.Regexp
+	commits     []string
+	stopWords   []string
+}
+
+// NewDetector 根据配置创建扫描器，配置中的正则表达式无法编译时返回错误
+func NewDetector(cfg *Config) (*Detector, error) {
+	rules, allowlist, err := effectiveRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Detector{}
+	if d.allowlist, err = compileAllowlist(allowlist); err != nil {
+		return nil, fmt.Errorf("[allowlist]: %w", err)
+	}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, i18n.Errorf("gitleaks.rule_error", rule.ID, err)
+		}
+		d.rules = append(d.rules, compiled)
+	}
+	return d, nil
+}
+
+// effectiveRules 展开 [extend]：useDefault 时加入内置规则（同 id 以当前配置为准），再去掉 disabledRules
+func effectiveRules(cfg *Config) ([]Rule, *Allowlist, error) {
+	rules := append([]Rule(nil), cfg.Rules...)
+	allowlist := &Allowlist{}
+	if cfg.Allowlist != nil {
+		allowlist.merge(cfg.Allowlist)
+	}
+	if cfg.Extend == nil {
+		return rules, allowlist, nil
+	}
+
+	if cfg.Extend.Path != "" {
+		return nil, nil, i18n.Errorf("gitleaks.extend_path")
+	}
+	if cfg.Extend.UseDefault {
+		defaults, err := DefaultConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rule := range defaults.Rules {
+			if cfg.Rule(rule.ID) == nil {
+				rules = append(rules, rule)
+			}
+		}
+		if defaults.Allowlist != nil {
+			allowlist.merge(defaults.Allowlist)
+		}
+	}
+
+	if len(cfg.Extend.DisabledRules) > 0 {
+		disabled := make(map[string]bool)
+		for _, id := range cfg.Extend.DisabledRules {
+			disabled[id] = true
+		}
+		var enabled []Rule
+		for _, rule := range rules {
+			if !disabled[rule.ID] {
+				enabled = append(enabled, rule)
+			}
+		}
+		rules = enabled
+	}
+	return rules, allowlist, nil
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	if rule.Regex == "" && rule.Path == "" {
+		return nil, i18n.Errorf("gitleaks.empty_rule")
+	}
+
+	compiled := &compiledRule{Rule: rule}
+	var err error
+	if rule.Regex != "" {
+		if compiled.regex, err = regexp.Compile(rule.Regex); err != nil {
+			return nil, fmt.Errorf("regex: %w", err)
+		}
+		if rule.SecretGroup > compiled.regex.NumSubexp() {
+			return nil, i18n.Errorf("gitleaks.secret_group", rule.SecretGroup, compiled.regex.NumSubexp())
+		}
+	}
+	if rule.Path != "" {
+		if compiled.path, err = regexp.Compile(rule.Path); err != nil {
+			return nil, fmt.Errorf("path: %w", err)
+		}
+	}
+	for _, keyword := range rule.Keywords {
+		compiled.keywords = append(compiled.keywords, strings.ToLower(keyword))
+	}
+	if rule.Allowlist != nil {
+		if compiled.allowlist, err = compileAllowlist(rule.Allowlist); err != nil {
+			return nil, fmt.Errorf("allowlist: %w", err)
+		}
+	}
+	return compiled, nil
+}
+
+func compileAllowlist(a *Allowlist) (*compiledAllowlist, error) {
+	compiled := &compiledAllowlist{regexTarget: a.RegexTarget, commits: a.Commits}
+	switch a.RegexTarget {
+	case "", "secret", "match", "line":
+	default:
+		return nil, i18n.Errorf("gitleaks.regex_target", a.RegexTarget)
+	}
+	for _, pattern := range a.Regexes {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("regexes: %w", err)
+		}
+		compiled.regexes = append(compiled.regexes, re)
+	}
+	for _, pattern := range a.Paths {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("paths: %w", err)
+		}
+		compiled.paths = append(compiled.paths, re)
+	}
+	for _, word := range a.StopWords {
+		compiled.stopWords = append(compiled.stopWords, strings.ToLower(word))
+	}
+	return compiled, nil
+}
+
+// Detect 扫描一段内容，返回按位置排序的发现
+func (d *Detector) Detect(fragment Fragment) []Finding {
+	if d.allowlist.commitAllowed(fragment.CommitSHA) || d.allowlist.pathAllowed(fragment.FilePath) {
+		return nil
+	}
+
+	var findings []Finding
+	lower := strings.ToLower(fragment.Raw)
+	for _, rule := range d.rules {
+		findings = append(findings, d.detectRule(rule, fragment, lower)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].StartLine != findings[j].StartLine {
+			return findings[i].StartLine < findings[j].StartLine
+		}
+		return findings[i].StartColumn < findings[j].StartColumn
+	})
+	return findings
+}
+
+func (d *Detector) detectRule(rule *compiledRule, fragment Fragment, lower string) []Finding {
+	if rule.allowlist != nil && (rule.allowlist.commitAllowed(fragment.CommitSHA) || rule.allowlist.pathAllowed(fragment.FilePath)) {
+		return nil
+	}
+	if rule.path != nil && !rule.path.MatchString(fragment.FilePath) {
+		return nil
+	}
+
+	// 只有 path 的规则匹配文件本身
+	if rule.regex == nil {
+		return []Finding{newFinding(rule, fragment, "file detected: "+fragment.FilePath, "", 0, 0)}
+	}
+
+	if len(rule.keywords) > 0 && !containsAny(lower, rule.keywords) {
+		return nil
+	}
+
+	var findings []Finding
+	for _, loc := range rule.regex.FindAllStringSubmatchIndex(fragment.Raw, -1) {
+		match := fragment.Raw[loc[0]:loc[1]]
+		secret := secretOf(fragment.Raw, loc, rule.SecretGroup, match)
+
+		line := lineAt(fragment.Raw, loc[0], loc[1])
+		if strings.Contains(line, allowComment) {
+			continue
+		}
+		entropy := shannonEntropy(secret)
+		if rule.Entropy != 0 && entropy <= rule.Entropy {
+			continue
+		}
+		if d.allowlist.matchAllowed(match, secret, line) ||
+			(rule.allowlist != nil && rule.allowlist.matchAllowed(match, secret, line)) {
+			continue
+		}
+
+		finding := newFinding(rule, fragment, strings.TrimSpace(match), secret, loc[0], loc[1])
+		finding.Entropy = float32(entropy)
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// secretOf 获取密钥：指定 secretGroup 时使用该分组，否则与 gitleaks 一样使用第一个非空分组，没有分组时使用整个匹配
+func secretOf(raw string, loc []int, secretGroup int, match string) string {
+	if secretGroup > 0 {
+		if loc[2*secretGroup] >= 0 {
+			return raw[loc[2*secretGroup]:loc[2*secretGroup+1]]
+		}
+		return match
+	}
+	for i := 2; i+1 < len(loc); i += 2 {
+		if loc[i] >= 0 && loc[i+1] > loc[i] {
+			return raw[loc[i]:loc[i+1]]
+		}
+	}
+	return match
+}
+
+// newFinding 根据匹配位置创建发现，行号和列号从 1 开始
+func newFinding(rule *compiledRule, fragment Fragment, match, secret string, start, end int) Finding {
+	finding := Finding{
+		RuleID:      rule.ID,
+		Description: rule.Description,
+		Match:       match,
+		Secret:      secret,
+		File:        fragment.FilePath,
+		Commit:      fragment.CommitSHA,
+		Tags:        rule.Tags,
+	}
+	if finding.Tags == nil {
+		finding.Tags = []string{}
+	}
+	if end > start {
+		finding.StartLine, finding.StartColumn = position(fragment.Raw, start)
+		finding.EndLine, finding.EndColumn = position(fragment.Raw, end-1)
+	}
+	finding.Fingerprint = fingerprint(finding)
+	return finding
+}
+
+// fingerprint 与 gitleaks 相同的指纹格式：[commit:]file:rule:line
+func fingerprint(f Finding) string {
+	fp := fmt.Sprintf("%s:%s:%d", f.File, f.RuleID, f.StartLine)
+	if f.Commit != "" {
+		fp = f.Commit + ":" + fp
+	}
+	return fp
+}
+
+// position 计算偏移量所在的行号和列号
+func position(raw string, offset int) (int, int) {
+	before := raw[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - (strings.LastIndex(before, "\n") + 1) + 1
+	return line, column
+}
+
+// lineAt 获取匹配内容所在的完整行（匹配跨行时包含所有行）
+func lineAt(raw string, start, end int) string {
+	lineStart := strings.LastIndex(raw[:start], "\n") + 1
+	lineEnd := len(raw)
+	if i := strings.Index(raw[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+	return raw[lineStart:lineEnd]
+}
+
+func (a *compiledAllowlist) commitAllowed(commit string) bool {
+	if commit == "" {
+		return false
+	}
+	for _, c := range a.commits {
+		if c == commit {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *compiledAllowlist) pathAllowed(path string) bool {
+	for _, re := range a.paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAllowed 按 regexTarget 检查白名单正则，并检查密钥中是否包含 stopwords
+func (a *compiledAllowlist) matchAllowed(match, secret, line string) bool {
+	target := secret
+	switch a.regexTarget {
+	case "match":
+		target = match
+	case "line":
+		target = line
+	}
+	for _, re := range a.regexes {
+		if re.MatchString(target) {
+			return true
+		}
+	}
+	return containsAny(strings.ToLower(secret), a.stopWords)
+}
+
+func containsAny(s string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy 计算字符串的香农熵
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	length := float64(len([]rune(s)))
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
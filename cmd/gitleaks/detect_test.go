@@ -0,0 +1,228 @@
+package gitleaks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tokenRule := Rule{ID: "token", Regex: `(token)\s*=\s*"([a-z0-9]{8})"`, SecretGroup: 2, Keywords: []string{"TOKEN"}}
+	tests := []struct {
+		name     string
+		config   *Config
+		fragment Fragment
+		want     []string // 发现的 RuleID:Secret，按位置排序
+	}{
+		{
+			name:     "secret group",
+			config:   &Config{Rules: []Rule{tokenRule}},
+			fragment: Fragment{Raw: `token = "abcd1234"`, FilePath: "a.go"},
+			want:     []string{"token:abcd1234"},
+		},
+		{
+			name:     "first non-empty group by default",
+			config:   &Config{Rules: []Rule{{ID: "key", Regex: `key_(x)?([0-9]+)`}}},
+			fragment: Fragment{Raw: "key_123"},
+			want:     []string{"key:123"},
+		},
+		{
+			name:     "keyword missing",
+			config:   &Config{Rules: []Rule{{ID: "token", Regex: `[a-z0-9]{8}`, Keywords: []string{"token"}}}},
+			fragment: Fragment{Raw: "abcd1234"},
+			want:     nil,
+		},
+		{
+			name:     "gitleaks allow comment",
+			config:   &Config{Rules: []Rule{tokenRule}},
+			fragment: Fragment{Raw: "token = \"abcd1234\" // gitleaks:allow\ntoken = \"efgh5678\""},
+			want:     []string{"token:efgh5678"},
+		},
+		{
+			name:     "entropy threshold",
+			config:   &Config{Rules: []Rule{{ID: "hex", Regex: `[0-9a-f]{8}`, Entropy: 2.5}}},
+			fragment: Fragment{Raw: "aaaaaaaa 0123abcd"},
+			want:     []string{"hex:0123abcd"},
+		},
+		{
+			name:     "rule path",
+			config:   &Config{Rules: []Rule{{ID: "env", Regex: `SECRET=\S+`, Path: `\.env$`}}},
+			fragment: Fragment{Raw: "SECRET=x", FilePath: "main.go"},
+			want:     nil,
+		},
+		{
+			name:     "path only rule",
+			config:   &Config{Rules: []Rule{{ID: "pem", Path: `\.pem$`}}},
+			fragment: Fragment{Raw: "anything", FilePath: "certs/key.pem"},
+			want:     []string{"pem:"},
+		},
+		{
+			name:     "global allowlist path",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{Paths: []string{`^testdata/`}}},
+			fragment: Fragment{Raw: `token = "abcd1234"`, FilePath: "testdata/a.go"},
+			want:     nil,
+		},
+		{
+			name:     "global allowlist commit",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{Commits: []string{"abc"}}},
+			fragment: Fragment{Raw: `token = "abcd1234"`, CommitSHA: "abc"},
+			want:     nil,
+		},
+		{
+			name: "rule allowlist path",
+			config: &Config{Rules: []Rule{
+				{ID: "token", Regex: tokenRule.Regex, SecretGroup: 2, Allowlist: &Allowlist{Paths: []string{`_test\.go$`}}},
+				{ID: "any", Regex: `abcd1234`},
+			}},
+			fragment: Fragment{Raw: `token = "abcd1234"`, FilePath: "a_test.go"},
+			want:     []string{"any:abcd1234"},
+		},
+		{
+			name:     "stopwords",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{StopWords: []string{"ABCD"}}},
+			fragment: Fragment{Raw: `token = "abcd1234"`},
+			want:     nil,
+		},
+		{
+			name:     "allowlist regex matches the secret by default",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{Regexes: []string{`^token`}}},
+			fragment: Fragment{Raw: `token = "abcd1234"`},
+			want:     []string{"token:abcd1234"},
+		},
+		{
+			name:     "allowlist regex target match",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{RegexTarget: "match", Regexes: []string{`^token`}}},
+			fragment: Fragment{Raw: `token = "abcd1234"`},
+			want:     nil,
+		},
+		{
+			name:     "allowlist regex target line",
+			config:   &Config{Rules: []Rule{tokenRule}, Allowlist: &Allowlist{RegexTarget: "line", Regexes: []string{`// example$`}}},
+			fragment: Fragment{Raw: `token = "abcd1234" // example`},
+			want:     nil,
+		},
+		{
+			name: "disabled rules",
+			config: &Config{
+				Extend: &Extend{DisabledRules: []string{"token"}},
+				Rules:  []Rule{tokenRule, {ID: "other", Regex: `abcd1234`}},
+			},
+			fragment: Fragment{Raw: `token = "abcd1234"`},
+			want:     []string{"other:abcd1234"},
+		},
+		{
+			name:     "findings sorted by position",
+			config:   &Config{Rules: []Rule{{ID: "b", Regex: `bbb`}, {ID: "a", Regex: `aaa`}}},
+			fragment: Fragment{Raw: "aaa\nbbb aaa"},
+			want:     []string{"a:aaa", "b:bbb", "a:aaa"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDetector(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range d.Detect(tt.fragment) {
+				got = append(got, f.RuleID+":"+f.Secret)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFindingPosition(t *testing.T) {
+	d, err := NewDetector(&Config{Rules: []Rule{{ID: "key", Regex: `key_[0-9]+`, Tags: []string{"t"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := d.Detect(Fragment{Raw: "x\n  key_42\n", FilePath: "a.txt", CommitSHA: "abc"})
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v", findings)
+	}
+	f := findings[0]
+	if f.StartLine != 2 || f.StartColumn != 3 || f.EndLine != 2 || f.EndColumn != 8 {
+		t.Errorf("position = %d:%d-%d:%d", f.StartLine, f.StartColumn, f.EndLine, f.EndColumn)
+	}
+	if f.Fingerprint != "abc:a.txt:key:2" {
+		t.Errorf("fingerprint = %s", f.Fingerprint)
+	}
+}
+
+func TestNewDetectorErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{name: "bad regex", config: &Config{Rules: []Rule{{ID: "x", Regex: `(`}}}},
+		{name: "empty rule", config: &Config{Rules: []Rule{{ID: "x"}}}},
+		{name: "secret group out of range", config: &Config{Rules: []Rule{{ID: "x", Regex: `(a)`, SecretGroup: 2}}}},
+		{name: "bad regex target", config: &Config{Allowlist: &Allowlist{RegexTarget: "file"}}},
+		{name: "extend path", config: &Config{Extend: &Extend{Path: "base.toml"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDetector(tt.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestTemplateRules 内置模板配置中的规则
+func TestTemplateRules(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "template", "global_config", ".gitleaks.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := cfg.UnknownKeys(); len(keys) > 0 {
+		t.Errorf("template config has unsupported keys %v", keys)
+	}
+	d, err := NewDetector(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		raw    string
+		wantID string // 为空表示没有发现
+	}{
+		{name: "chinese comment", raw: "let a = 1 // 初始化\n", wantID: "chinese-characters"},
+		{name: "agora app id", raw: `AGORA_APP_ID = "0123456789abcdef0123456789abcdef"`, wantID: "agora-app-id-pattern"},
+		{name: "english only", raw: "let a = 1 // init\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := d.Detect(Fragment{Raw: tt.raw, FilePath: "main.swift"})
+			if tt.wantID == "" {
+				if len(findings) > 0 {
+					t.Errorf("unexpected findings %+v", findings)
+				}
+				return
+			}
+			for _, f := range findings {
+				if f.RuleID == tt.wantID {
+					return
+				}
+			}
+			t.Errorf("no %s finding in %+v", tt.wantID, findings)
+		})
+	}
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := cfg.UnknownKeys(); len(keys) > 0 {
+		t.Errorf("built-in rules have unsupported keys %v", keys)
+	}
+	if _, err := NewDetector(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
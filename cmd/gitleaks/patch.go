@@ -0,0 +1,1063 @@
+package gitleaks
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"devex/cmd/i18n"
+)
+
+// 写回配置时不重新编码整个文件，而是把当前配置与解析原文得到的配置比较，只修改发生变化的键和配置段：
+//   - 列表只是追加了元素时（如 devex secrets ignore 添加路径），把新元素插入到原数组的末尾
+//   - 其他变化的键整体替换，缺少的键、配置段和规则插入到对应位置
+//   - 删除的 [[devex.ignores]]、[rules.allowlist] 等配置段整体删除
+//   - 键按 TOML 语法解析，支持带引号的键和带点的键；表的字段可以写在配置段、上级配置段的带点键或内联表中，
+//     修改时沿用原来的写法，内联表只重写其中发生变化的成员
+// 修改后的文本会重新解析并与当前配置比较，不一致时返回错误，不会写入错误的内容
+
+// tomlDocument 按行保存的 TOML 文本
+type tomlDocument struct {
+	lines []string
+}
+
+// tomlStatement 一个键值对，占据 [start, end) 行
+type tomlStatement struct {
+	key        string // 规范化的键，带点的键以 . 连接，不是裸键的部分加引号
+	keyText    string // 原文中的键，修改值时原样保留
+	indent     string
+	start, end int
+	comment    string         // 值之后的行尾注释
+	inline     bool           // 值为内联表
+	array      *arrayPosition // 值为数组时的位置
+}
+
+// arrayPosition 数组值中用于追加元素的位置
+type arrayPosition struct {
+	empty               bool // 数组中没有元素
+	closeLine, closeCol int  // 结束的 ]
+	lastLine, lastCol   int  // ] 之前最后一个有效字符（元素或逗号）
+}
+
+// tomlSection 一个配置段，根配置段没有标题
+type tomlSection struct {
+	name  string // 规范化的名称，与 tomlStatement.key 相同
+	array bool
+	start int // 标题所在行，根配置段为 0
+	end   int // 最后一个键值对之后的行，没有键值对时为标题的下一行
+	keys  []tomlStatement
+}
+
+// fullName 配置段中键值对的完整名称
+func (s *tomlSection) fullName(stmt *tomlStatement) string {
+	return joinKey(s.name, stmt.key)
+}
+
+// joinKey 连接两段规范化的键，任一段为空时返回另一段
+func joinKey(prefix, key string) string {
+	switch {
+	case prefix == "":
+		return key
+	case key == "":
+		return prefix
+	}
+	return prefix + "." + key
+}
+
+func newTomlDocument(data []byte) *tomlDocument {
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	return &tomlDocument{lines: strings.Split(text, "\n")}
+}
+
+// bytes 文档内容，以换行结尾
+func (d *tomlDocument) bytes() []byte {
+	return []byte(strings.Join(d.lines, "\n") + "\n")
+}
+
+// sections 按顺序列出配置段，第一个为根配置段
+func (d *tomlDocument) sections() ([]tomlSection, error) {
+	sections := []tomlSection{{}}
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			name, array, ok := parseHeader(trimmed)
+			if !ok {
+				return nil, i18n.Errorf("gitleaks.patch_unsupported_line", i+1, trimmed)
+			}
+			sections = append(sections, tomlSection{name: name, array: array, start: i, end: i + 1})
+			continue
+		}
+
+		keyStart := len(line) - len(strings.TrimLeft(line, " \t"))
+		path, keyEnd, ok := scanKey(line, keyStart)
+		eq := skipSpace(line, keyEnd)
+		if !ok || eq >= len(line) || line[eq] != '=' {
+			return nil, i18n.Errorf("gitleaks.patch_unsupported_line", i+1, trimmed)
+		}
+		stmt := tomlStatement{
+			key:     keyName(path),
+			keyText: strings.TrimSpace(line[keyStart:keyEnd]),
+			indent:  line[:keyStart],
+			start:   i,
+		}
+		var lexer valueLexer
+		for j := i; j < len(d.lines); j++ {
+			from := 0
+			if j == i {
+				from = eq + 1
+			}
+			lexer.scan(j, d.lines[j], from)
+			if lexer.done() {
+				stmt.end = j + 1
+				break
+			}
+		}
+		if stmt.end == 0 {
+			return nil, i18n.Errorf("gitleaks.patch_unsupported_line", i+1, trimmed)
+		}
+		stmt.array = lexer.arrayPosition()
+		stmt.inline = lexer.table
+		stmt.comment = lexer.comment
+
+		section := &sections[len(sections)-1]
+		section.keys = append(section.keys, stmt)
+		section.end = stmt.end
+		i = stmt.end - 1
+	}
+	return sections, nil
+}
+
+// parseHeader 解析配置段标题 [name] 或 [[name]]，返回规范化的名称
+func parseHeader(s string) (string, bool, bool) {
+	array := strings.HasPrefix(s, "[[")
+	open, close := "[", "]"
+	if array {
+		open, close = "[[", "]]"
+	}
+	path, k, ok := scanKey(s, len(open))
+	if !ok {
+		return "", false, false
+	}
+	k = skipSpace(s, k)
+	if !strings.HasPrefix(s[k:], close) {
+		return "", false, false
+	}
+	rest := strings.TrimSpace(s[k+len(close):])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", false, false
+	}
+	return keyName(path), array, true
+}
+
+// scanKey 从 s[k] 开始读取键，支持裸键、"基本字符串"、'字面量' 以及以 . 连接的带点键，返回键的各部分和键之后的位置
+func scanKey(s string, k int) ([]string, int, bool) {
+	var path []string
+	for {
+		k = skipSpace(s, k)
+		if k >= len(s) {
+			return nil, k, false
+		}
+		var part string
+		switch s[k] {
+		case '"':
+			end := k + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, k, false
+			}
+			// TOML 基本字符串的转义是 Go 字符串转义的子集（\e 除外），无法解码时使用原文
+			var err error
+			if part, err = strconv.Unquote(s[k : end+1]); err != nil {
+				part = s[k+1 : end]
+			}
+			k = end + 1
+		case '\'':
+			end := strings.IndexByte(s[k+1:], '\'')
+			if end < 0 {
+				return nil, k, false
+			}
+			part = s[k+1 : k+1+end]
+			k += end + 2
+		default:
+			start := k
+			for k < len(s) && isBareKeyChar(s[k]) {
+				k++
+			}
+			if k == start {
+				return nil, k, false
+			}
+			part = s[start:k]
+		}
+		path = append(path, part)
+
+		k = skipSpace(s, k)
+		if k < len(s) && s[k] == '.' {
+			k++
+			continue
+		}
+		return path, k, true
+	}
+}
+
+// keyName 把键的各部分编码为规范化的名称，不是裸键的部分加引号
+func keyName(path []string) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		parts[i] = part
+		if part == "" || strings.IndexFunc(part, func(r rune) bool { return r > 0x7f || !isBareKeyChar(byte(r)) }) >= 0 {
+			parts[i] = quote(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func skipSpace(s string, k int) int {
+	for k < len(s) && (s[k] == ' ' || s[k] == '\t') {
+		k++
+	}
+	return k
+}
+
+// replace 用 lines 替换 [start, end) 行
+func (d *tomlDocument) replace(start, end int, lines []string) {
+	updated := make([]string, 0, len(d.lines)-(end-start)+len(lines))
+	updated = append(updated, d.lines[:start]...)
+	updated = append(updated, lines...)
+	d.lines = append(updated, d.lines[end:]...)
+}
+
+// insertBlock 在 at 行之前插入一段内容，与前后的内容之间各保留一个空行
+func (d *tomlDocument) insertBlock(at int, lines []string) {
+	block := append([]string(nil), lines...)
+	if at > 0 && strings.TrimSpace(d.lines[at-1]) != "" {
+		block = append([]string{""}, block...)
+	}
+	if at < len(d.lines) && strings.TrimSpace(d.lines[at]) != "" {
+		block = append(block, "")
+	}
+	d.replace(at, at, block)
+}
+
+// removeBlock 删除 [start, end) 行，并去掉因此多出的空行
+func (d *tomlDocument) removeBlock(start, end int) {
+	d.replace(start, end, nil)
+	blank := func(i int) bool { return i < 0 || i >= len(d.lines) || strings.TrimSpace(d.lines[i]) == "" }
+	if start < len(d.lines) && blank(start) && blank(start-1) {
+		d.replace(start, start+1, nil)
+	} else if start == len(d.lines) && start > 0 && blank(start-1) {
+		d.replace(start-1, start, nil)
+	}
+}
+
+// valueLexer 逐字符扫描键值对的值，跳过字符串和注释，判断值在哪一行结束
+type valueLexer struct {
+	multiline string // 所在多行字符串的引号（""" 或 '''），不在多行字符串中时为空
+	depth     int    // [ 和 { 的嵌套层数
+	started   bool
+	array     bool // 值为数组
+	table     bool // 值为内联表
+
+	openLine, openCol   int
+	closeLine, closeCol int
+	lastLine, lastCol   int
+	closed              bool
+	comment             string // 值结束所在行的行尾注释
+}
+
+func (l *valueLexer) done() bool {
+	return l.started && l.multiline == "" && l.depth == 0
+}
+
+func (l *valueLexer) mark(line, col int) {
+	l.lastLine, l.lastCol = line, col
+}
+
+// scan 扫描一行，from 为值在该行中的起始位置
+func (l *valueLexer) scan(line int, s string, from int) {
+	for k := from; k < len(s); k++ {
+		if l.multiline != "" {
+			if l.multiline == `"""` && s[k] == '\\' {
+				k++
+				continue
+			}
+			if strings.HasPrefix(s[k:], l.multiline) {
+				// 结束引号之后紧跟的引号属于字符串内容
+				for k+3 < len(s) && s[k+3] == l.multiline[0] {
+					k++
+				}
+				k += 2
+				l.multiline = ""
+				l.mark(line, k)
+			}
+			continue
+		}
+
+		c := s[k]
+		if c == ' ' || c == '\t' || c == '\r' {
+			continue
+		}
+		if c == '#' {
+			if l.done() {
+				l.comment = s[k:]
+			}
+			return
+		}
+		first := !l.started
+		l.started = true
+
+		switch {
+		case strings.HasPrefix(s[k:], `"""`), strings.HasPrefix(s[k:], `'''`):
+			l.multiline = s[k : k+3]
+			k += 2
+		case c == '"':
+			for k++; k < len(s) && s[k] != '"'; k++ {
+				if s[k] == '\\' {
+					k++
+				}
+			}
+			l.mark(line, k)
+		case c == '\'':
+			if end := strings.IndexByte(s[k+1:], '\''); end >= 0 {
+				k += end + 1
+			} else {
+				k = len(s)
+			}
+			l.mark(line, k)
+		case c == '[' || c == '{':
+			if first && c == '[' {
+				l.array = true
+				l.openLine, l.openCol = line, k
+			}
+			l.table = l.table || first && c == '{'
+			l.depth++
+			l.mark(line, k)
+		case c == ']' || c == '}':
+			l.depth--
+			if l.depth == 0 && l.array && !l.closed {
+				l.closed = true
+				l.closeLine, l.closeCol = line, k
+			} else {
+				l.mark(line, k)
+			}
+		default:
+			l.mark(line, k)
+		}
+	}
+}
+
+// arrayPosition 值为数组时返回追加元素的位置
+func (l *valueLexer) arrayPosition() *arrayPosition {
+	if !l.array || !l.closed {
+		return nil
+	}
+	return &arrayPosition{
+		empty:     l.lastLine == l.openLine && l.lastCol == l.openCol,
+		closeLine: l.closeLine,
+		closeCol:  l.closeCol,
+		lastLine:  l.lastLine,
+		lastCol:   l.lastCol,
+	}
+}
+
+// tomlField 配置段中的一个字段
+type tomlField struct {
+	key     string
+	value   string              // 编码后的值，为空表示没有该字段
+	list    []string            // 列表字段的元素
+	isList  bool                // 是否为列表字段
+	element func(string) string // 列表元素的编码方式
+}
+
+// encodeList 编码列表，multiline 为 true 时每行一个元素
+func (f tomlField) encodeList(multiline bool) string {
+	if !multiline {
+		items := make([]string, len(f.list))
+		for i, v := range f.list {
+			items[i] = f.element(v)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	var sb strings.Builder
+	sb.WriteString("[\n")
+	for i, v := range f.list {
+		sb.WriteString("    " + f.element(v))
+		if i < len(f.list)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// inline 编码为内联表中的成员，列表写在一行中
+func (f tomlField) inline() string {
+	value := f.value
+	if f.isList {
+		value = f.encodeList(false)
+	}
+	return f.key + " = " + value
+}
+
+func scalarField(key, value string) tomlField {
+	return tomlField{key: key, value: value}
+}
+
+func listField(key string, values []string, element func(string) string, multiline bool) tomlField {
+	f := tomlField{key: key, list: values, isList: true, element: element}
+	if len(values) > 0 {
+		f.value = f.encodeList(multiline)
+	}
+	return f
+}
+
+func (e *Extend) fields() []tomlField {
+	var path string
+	if e.Path != "" {
+		path = quote(e.Path)
+	}
+	return []tomlField{
+		scalarField("useDefault", strconv.FormatBool(e.UseDefault)),
+		scalarField("path", path),
+		listField("disabledRules", e.DisabledRules, quote, false),
+	}
+}
+
+func (a *Allowlist) fields() []tomlField {
+	var description, regexTarget string
+	if a.Description != "" {
+		description = quote(a.Description)
+	}
+	if a.RegexTarget != "" {
+		regexTarget = quote(a.RegexTarget)
+	}
+	return []tomlField{
+		scalarField("description", description),
+		scalarField("regexTarget", regexTarget),
+		listField("regexes", a.Regexes, literal, true),
+		listField("paths", a.Paths, literal, true),
+		listField("commits", a.Commits, literal, true),
+		listField("stopwords", a.StopWords, literal, true),
+	}
+}
+
+// namedSection 第 n 个（从 0 开始）名为 name 的配置段，返回下标，不存在时返回 -1
+func namedSection(name string, n int) func(sections []tomlSection) int {
+	return func(sections []tomlSection) int {
+		for i := 1; i < len(sections); i++ {
+			if sections[i].name == name {
+				if n == 0 {
+					return i
+				}
+				n--
+			}
+		}
+		return -1
+	}
+}
+
+// ruleBlock 第 n 条规则的 [[rules]] 及其子配置段（[rules.xxx]）的下标范围 [first, last]
+func ruleBlock(sections []tomlSection, n int) (int, int) {
+	first := namedSection("rules", n)(sections)
+	if first < 0 {
+		return -1, -1
+	}
+	last := first
+	for last+1 < len(sections) && strings.HasPrefix(sections[last+1].name, "rules.") {
+		last++
+	}
+	return first, last
+}
+
+// tomlTable 查找范围内的一个表。表的字段可以写在 [name] 配置段中，也可以以带点键（name.key = ...）
+// 写在上级配置段中，或者整体写成内联表（name = {...}）
+type tomlTable struct {
+	name     string        // 规范化的表名，根表为空
+	sections []tomlSection // 查找范围内的配置段
+	header   *tomlSection  // [name] 配置段，根表为根配置段，不存在时为 nil
+}
+
+// tableLocator 在配置段列表中确定表的查找范围，范围不存在时返回 nil
+type tableLocator func(sections []tomlSection) *tomlTable
+
+func newTomlTable(name string, sections []tomlSection) *tomlTable {
+	t := &tomlTable{name: name, sections: sections}
+	for i := range sections {
+		if sections[i].name == name && !sections[i].array {
+			t.header = &sections[i]
+			break
+		}
+	}
+	return t
+}
+
+// namedTable 整个文档中名为 name 的表
+func namedTable(name string) tableLocator {
+	return func(sections []tomlSection) *tomlTable {
+		return newTomlTable(name, sections)
+	}
+}
+
+// ruleAllowlist 第 n 条规则的 allowlist，范围为该规则的 [[rules]] 及其子配置段
+func ruleAllowlist(n int) tableLocator {
+	return func(sections []tomlSection) *tomlTable {
+		first, last := ruleBlock(sections, n)
+		if first < 0 {
+			return nil
+		}
+		return newTomlTable("rules.allowlist", sections[first:last+1])
+	}
+}
+
+// find 查找完整名称为 name 的键值对及其所在的配置段
+func (t *tomlTable) find(name string) (*tomlSection, *tomlStatement) {
+	for i := range t.sections {
+		section := &t.sections[i]
+		for j := range section.keys {
+			if section.fullName(&section.keys[j]) == name {
+				return section, &section.keys[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// field 查找表中的字段
+func (t *tomlTable) field(key string) *tomlStatement {
+	_, stmt := t.find(joinKey(t.name, key))
+	return stmt
+}
+
+// inline 表写成内联表时返回对应的键值对
+func (t *tomlTable) inline() *tomlStatement {
+	if t.name == "" {
+		return nil
+	}
+	if _, stmt := t.find(t.name); stmt != nil && stmt.inline {
+		return stmt
+	}
+	return nil
+}
+
+// dottedField 以带点键写在上级配置段中的字段
+type dottedField struct {
+	stmt   *tomlStatement
+	prefix string // 键中表示表的部分，如 [rules] 中 allowlist.paths 的 allowlist
+}
+
+// dotted 以带点键写在上级配置段中的字段，按在文档中的顺序，不包括子表的字段
+func (t *tomlTable) dotted() []dottedField {
+	var fields []dottedField
+	for i := range t.sections {
+		section := &t.sections[i]
+		if len(section.name) >= len(t.name) {
+			continue
+		}
+		for j := range section.keys {
+			stmt := &section.keys[j]
+			rest, ok := strings.CutPrefix(section.fullName(stmt), t.name+".")
+			if !ok {
+				continue
+			}
+			if path, end, ok := scanKey(rest, 0); ok && len(path) == 1 && end == len(rest) {
+				fields = append(fields, dottedField{stmt: stmt, prefix: strings.TrimSuffix(stmt.key, "."+rest)})
+			}
+		}
+	}
+	return fields
+}
+
+// tomlPatcher 在文档上应用修改
+type tomlPatcher struct {
+	doc *tomlDocument
+}
+
+// table 查找表，每次修改后行号都会变化，所以每次都重新扫描
+func (p *tomlPatcher) table(locate tableLocator) (*tomlTable, error) {
+	sections, err := p.doc.sections()
+	if err != nil {
+		return nil, err
+	}
+	return locate(sections), nil
+}
+
+// patchTable 修改表中的字段：old、new 为 nil 表示表不存在
+// 新建的表写成 header 配置段，插入到 insertAt 返回的行之前
+func (p *tomlPatcher) patchTable(header string, locate tableLocator, old, new []tomlField, insertAt func() (int, error)) error {
+	switch {
+	case old == nil && new == nil:
+		return nil
+	case new == nil:
+		t, err := p.table(locate)
+		if err != nil || t == nil {
+			return err
+		}
+		if t.header != nil {
+			p.doc.removeBlock(t.header.start, t.header.end)
+		} else if stmt := t.inline(); stmt != nil {
+			p.doc.replace(stmt.start, stmt.end, nil)
+		} else {
+			// 从后往前删除，前面键值对的行号不受影响
+			fields := t.dotted()
+			for i := len(fields) - 1; i >= 0; i-- {
+				p.doc.replace(fields[i].stmt.start, fields[i].stmt.end, nil)
+			}
+		}
+		return nil
+	case old == nil:
+		at, err := insertAt()
+		if err != nil {
+			return err
+		}
+		lines := []string{header}
+		for _, field := range new {
+			if field.value != "" {
+				lines = append(lines, strings.Split(field.key+" = "+field.value, "\n")...)
+			}
+		}
+		p.doc.insertBlock(at, lines)
+		return nil
+	}
+
+	var changed []int
+	for i, field := range new {
+		if field.value != old[i].value || !reflect.DeepEqual(field.list, old[i].list) {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// 内联表不能跨行追加元素，整体重写其中发生变化的字段
+	t, err := p.table(locate)
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		if stmt := t.inline(); stmt != nil {
+			return p.rewriteInline(stmt, old, new)
+		}
+	}
+
+	for _, i := range changed {
+		if err := p.setField(locate, old[i], new[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField 修改表中的一个字段
+func (p *tomlPatcher) setField(locate tableLocator, old, new tomlField) error {
+	t, err := p.table(locate)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return i18n.Errorf("gitleaks.patch_missing_section", new.key)
+	}
+	stmt := t.field(new.key)
+
+	switch {
+	case new.value == "":
+		if stmt != nil {
+			p.doc.replace(stmt.start, stmt.end, nil)
+		}
+		return nil
+	case stmt == nil:
+		return p.insertField(t, new)
+	}
+
+	// 只追加了元素时插入到原数组末尾，保留原数组的格式和注释
+	if new.isList && stmt.array != nil && len(new.list) > len(old.list) && reflect.DeepEqual(new.list[:len(old.list)], old.list) {
+		p.appendElements(stmt, new.list[len(old.list):], new.element)
+		return nil
+	}
+
+	value := new.value
+	if new.isList {
+		value = new.encodeList(stmt.end-stmt.start > 1)
+	}
+	if stmt.comment != "" {
+		value += " " + stmt.comment
+	}
+	lines := strings.Split(stmt.indent+stmt.keyText+" = "+value, "\n")
+	p.doc.replace(stmt.start, stmt.end, lines)
+	return nil
+}
+
+// insertField 添加表中缺少的字段：表有配置段时加在配置段末尾，
+// 以带点键定义时加在最后一个带点键之后，并使用相同的写法
+func (p *tomlPatcher) insertField(t *tomlTable, field tomlField) error {
+	if t.header != nil {
+		p.doc.replace(t.header.end, t.header.end, strings.Split(field.key+" = "+field.value, "\n"))
+		return nil
+	}
+	fields := t.dotted()
+	if len(fields) == 0 {
+		return i18n.Errorf("gitleaks.patch_missing_section", joinKey(t.name, field.key))
+	}
+	last := fields[len(fields)-1]
+	lines := strings.Split(last.stmt.indent+joinKey(last.prefix, field.key)+" = "+field.value, "\n")
+	p.doc.replace(last.stmt.end, last.stmt.end, lines)
+	return nil
+}
+
+// rewriteInline 重写内联表中发生变化的字段，其他成员（包括不支持的字段）和行尾注释原样保留
+func (p *tomlPatcher) rewriteInline(stmt *tomlStatement, old, new []tomlField) error {
+	text := strings.Join(p.doc.lines[stmt.start:stmt.end], "\n")
+	k := skipSpace(text, len(stmt.indent)+len(stmt.keyText))
+	k = skipSpace(text, k+1) // =
+	if k >= len(text) || text[k] != '{' {
+		return i18n.Errorf("gitleaks.patch_unsupported_line", stmt.start+1, strings.TrimSpace(text))
+	}
+	open := k
+
+	var members []string
+	used := make(map[string]bool)
+	for k++; ; {
+		k = skipSpace(text, k)
+		if k < len(text) && text[k] == '}' {
+			break
+		}
+		path, end, ok := scanKey(text, k)
+		eq := skipSpace(text, end)
+		if !ok || eq >= len(text) || text[eq] != '=' {
+			return i18n.Errorf("gitleaks.patch_unsupported_line", stmt.start+1, strings.TrimSpace(text))
+		}
+		valueEnd := inlineValueEnd(text, eq+1)
+		member := strings.TrimSpace(text[k:valueEnd])
+		for i, field := range new {
+			if field.key != keyName(path) {
+				continue
+			}
+			used[field.key] = true
+			if field.value != old[i].value || !reflect.DeepEqual(field.list, old[i].list) {
+				member = field.inline()
+			}
+		}
+		if member != "" {
+			members = append(members, member)
+		}
+		k = valueEnd
+		if k >= len(text) {
+			return i18n.Errorf("gitleaks.patch_unsupported_line", stmt.start+1, strings.TrimSpace(text))
+		}
+		if text[k] == ',' {
+			k++
+		}
+	}
+	for _, field := range new {
+		if !used[field.key] && field.value != "" {
+			members = append(members, field.inline())
+		}
+	}
+
+	value := "{}"
+	if len(members) > 0 {
+		value = "{ " + strings.Join(members, ", ") + " }"
+	}
+	p.doc.replace(stmt.start, stmt.end, strings.Split(text[:open]+value+text[k+1:], "\n"))
+	return nil
+}
+
+// inlineValueEnd 内联表中从 s[k] 开始的值之后的位置，即同一层级的 , 或 }
+func inlineValueEnd(s string, k int) int {
+	depth := 0
+	for ; k < len(s); k++ {
+		switch c := s[k]; {
+		case strings.HasPrefix(s[k:], `"""`), strings.HasPrefix(s[k:], `'''`):
+			quote := s[k : k+3]
+			end := k + 3
+			for end < len(s) && !strings.HasPrefix(s[end:], quote) {
+				if quote == `"""` && s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			k = end + 2
+		case c == '"':
+			for k++; k < len(s) && s[k] != '"'; k++ {
+				if s[k] == '\\' {
+					k++
+				}
+			}
+		case c == '\'':
+			if end := strings.IndexByte(s[k+1:], '\''); end >= 0 {
+				k += end + 1
+			}
+		case c == '#':
+			// 数组可以跨行，其中的注释到行尾结束
+			if end := strings.IndexByte(s[k:], '\n'); end >= 0 {
+				k += end
+			} else {
+				k = len(s)
+			}
+		case c == '[' || c == '{':
+			depth++
+		case (c == ',' || c == '}') && depth == 0:
+			return k
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return k
+}
+
+// appendElements 把元素追加到数组末尾
+func (p *tomlPatcher) appendElements(stmt *tomlStatement, elements []string, encode func(string) string) {
+	pos := stmt.array
+	encoded := make([]string, len(elements))
+	for i, e := range elements {
+		encoded[i] = encode(e)
+	}
+	lines := p.doc.lines
+
+	// 单行数组：在 ] 之前插入
+	if pos.closeLine == stmt.start || strings.TrimSpace(lines[pos.closeLine][:pos.closeCol]) != "" {
+		line := lines[pos.closeLine]
+		insert := strings.Join(encoded, ", ")
+		if !pos.empty {
+			last := lines[pos.lastLine]
+			if last[pos.lastCol] == ',' {
+				insert = " " + insert
+			} else {
+				insert = ", " + insert
+			}
+		}
+		lines[pos.closeLine] = strings.TrimRight(line[:pos.closeCol], " ") + insert + line[pos.closeCol:]
+		return
+	}
+
+	// 多行数组：] 单独一行，每个元素一行，缩进与最后一个元素相同
+	indent := "    "
+	trailingComma := false
+	if !pos.empty {
+		last := lines[pos.lastLine]
+		indent = last[:len(last)-len(strings.TrimLeft(last, " \t"))]
+		if last[pos.lastCol] == ',' {
+			trailingComma = true
+		} else {
+			lines[pos.lastLine] = last[:pos.lastCol+1] + "," + last[pos.lastCol+1:]
+		}
+	}
+	added := make([]string, len(encoded))
+	for i, e := range encoded {
+		added[i] = indent + e
+		if i < len(encoded)-1 || trailingComma {
+			added[i] += ","
+		}
+	}
+	p.doc.replace(pos.closeLine, pos.closeLine, added)
+}
+
+// Patch 把修改应用到解析的原文上，只改动发生变化的部分；不是从文本解析的配置完整编码
+func (c *Config) Patch() ([]byte, error) {
+	if c.source == nil || c.orig == nil {
+		return c.Encode(), nil
+	}
+	p := &tomlPatcher{doc: newTomlDocument(c.source)}
+	if err := p.apply(c.orig, c); err != nil {
+		return nil, i18n.Errorf("gitleaks.patch_failed", err)
+	}
+	data := p.doc.bytes()
+
+	// 校验修改结果：重新解析后必须与当前配置一致
+	check, _, err := decode(data)
+	if err != nil {
+		return nil, i18n.Errorf("gitleaks.patch_failed", err)
+	}
+	if !bytes.Equal(check.encodeBody(), c.encodeBody()) {
+		return nil, i18n.Errorf("gitleaks.patch_failed", i18n.T("gitleaks.patch_mismatch"))
+	}
+	return data, nil
+}
+
+// endOfFile 新配置段插入到文件末尾
+func (p *tomlPatcher) endOfFile() (int, error) {
+	return len(p.doc.lines), nil
+}
+
+// apply 比较原始配置和当前配置，按顺序修改文件开头注释、title、[extend]、规则、[allowlist] 和 [[devex.ignores]]
+func (p *tomlPatcher) apply(orig, c *Config) error {
+	if orig.header == "" && c.header != "" {
+		p.doc.insertBlock(0, strings.Split(c.header, "\n"))
+	}
+
+	if c.Title != orig.Title {
+		var title string
+		if c.Title != "" {
+			title = quote(c.Title)
+		}
+		root := namedTable("")
+		t, err := p.table(root)
+		if err != nil {
+			return err
+		}
+		if t.field("title") == nil && title != "" {
+			// 根配置段没有键值对时插入到第一个配置段之前
+			at := len(p.doc.lines)
+			if len(t.header.keys) > 0 {
+				at = t.header.end
+			} else if len(t.sections) > 1 {
+				at = t.sections[1].start
+			}
+			p.doc.insertBlock(at, []string{"title = " + title})
+		} else if err := p.setField(root, scalarField("title", quote(orig.Title)), scalarField("title", title)); err != nil {
+			return err
+		}
+	}
+
+	var oldExtend, newExtend []tomlField
+	if orig.Extend != nil {
+		oldExtend = orig.Extend.fields()
+	}
+	if c.Extend != nil {
+		newExtend = c.Extend.fields()
+	}
+	if err := p.patchTable("[extend]", namedTable("extend"), oldExtend, newExtend, p.endOfFile); err != nil {
+		return err
+	}
+
+	if err := p.patchRules(orig.Rules, c.Rules); err != nil {
+		return err
+	}
+
+	var oldAllowlist, newAllowlist []tomlField
+	if orig.Allowlist != nil {
+		oldAllowlist = orig.Allowlist.fields()
+	}
+	if c.Allowlist != nil {
+		newAllowlist = c.Allowlist.fields()
+	}
+	if err := p.patchTable("[allowlist]", namedTable("allowlist"), oldAllowlist, newAllowlist, p.endOfFile); err != nil {
+		return err
+	}
+
+	return p.patchIgnores(orig.Devex, c.Devex)
+}
+
+// patchRules 修改规则的 allowlist 并追加新规则，不支持删除规则或修改规则本身
+func (p *tomlPatcher) patchRules(orig, rules []Rule) error {
+	if len(rules) < len(orig) {
+		return i18n.Errorf("gitleaks.patch_rules")
+	}
+	for i := range orig {
+		before, after := orig[i], rules[i]
+		before.Allowlist, after.Allowlist = nil, nil
+		if !reflect.DeepEqual(before, after) {
+			return i18n.Errorf("gitleaks.patch_rules")
+		}
+
+		var oldFields, newFields []tomlField
+		if orig[i].Allowlist != nil {
+			oldFields = orig[i].Allowlist.fields()
+		}
+		if rules[i].Allowlist != nil {
+			newFields = rules[i].Allowlist.fields()
+		}
+		n := i
+		insertAt := func() (int, error) {
+			sections, err := p.doc.sections()
+			if err != nil {
+				return 0, err
+			}
+			_, last := ruleBlock(sections, n)
+			if last < 0 {
+				return 0, i18n.Errorf("gitleaks.patch_missing_section", "rules")
+			}
+			return sections[last].end, nil
+		}
+		if err := p.patchTable("[rules.allowlist]", ruleAllowlist(n), oldFields, newFields, insertAt); err != nil {
+			return err
+		}
+	}
+
+	// 新规则插入到最后一条规则之后，没有规则时插入到文件末尾
+	for i := len(orig); i < len(rules); i++ {
+		sections, err := p.doc.sections()
+		if err != nil {
+			return err
+		}
+		at := len(p.doc.lines)
+		if i > 0 {
+			if _, last := ruleBlock(sections, i-1); last >= 0 {
+				at = sections[last].end
+			}
+		}
+		var buf bytes.Buffer
+		rules[i].encode(&buf)
+		p.doc.insertBlock(at, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"))
+	}
+	return nil
+}
+
+// patchIgnores 按忽略项的类型、值和规则对应原有的 [[devex.ignores]]，删除、更新或追加
+func (p *tomlPatcher) patchIgnores(orig, current *Devex) error {
+	var before, after []Ignore
+	if orig != nil {
+		before = orig.Ignores
+	}
+	if current != nil {
+		after = current.Ignores
+	}
+
+	sections, err := p.doc.sections()
+	if err != nil {
+		return err
+	}
+	if len(before) > 0 && namedSection("devex.ignores", len(before)-1)(sections) < 0 {
+		return i18n.Errorf("gitleaks.patch_missing_section", "devex.ignores")
+	}
+
+	find := func(ignores []Ignore, ignore Ignore) *Ignore {
+		d := Devex{Ignores: ignores}
+		return d.Ignore(ignore.Kind, ignore.Value, ignore.Rule)
+	}
+
+	// 从后往前处理，删除配置段不影响前面配置段的序号
+	for i := len(before) - 1; i >= 0; i-- {
+		updated := find(after, before[i])
+		if updated != nil && *updated == before[i] {
+			continue
+		}
+		sections, err := p.doc.sections()
+		if err != nil {
+			return err
+		}
+		section := &sections[namedSection("devex.ignores", i)(sections)]
+		if updated == nil {
+			p.doc.removeBlock(section.start, section.end)
+			continue
+		}
+		var buf bytes.Buffer
+		updated.encode(&buf)
+		p.doc.replace(section.start+1, section.end, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"))
+	}
+
+	for _, ignore := range after {
+		if find(before, ignore) != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		buf.WriteString("[[devex.ignores]]\n")
+		ignore.encode(&buf)
+		p.doc.insertBlock(len(p.doc.lines), strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"))
+	}
+	return nil
+}
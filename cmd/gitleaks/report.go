@@ -0,0 +1,65 @@
+package gitleaks
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+)
+
+// RedactedSecret 脱敏后的密钥，与 gitleaks --redact 的输出一致
+const RedactedSecret = "REDACTED"
+
+// Finding 扫描发现，字段与 gitleaks JSON 报告一致
+type Finding struct {
+	RuleID      string
+	Description string
+	StartLine   int
+	EndLine     int
+	StartColumn int
+	EndColumn   int
+	Match       string
+	Secret      string
+	File        string
+	SymlinkFile string
+	Commit      string
+	Entropy     float32
+	Author      string
+	Email       string
+	Date        string
+	Message     string
+	Tags        []string
+	Fingerprint string
+}
+
+// Redacted 返回隐藏了密钥的发现，Match 中的密钥同样替换为 REDACTED
+func (f Finding) Redacted() Finding {
+	if f.Secret != "" {
+		f.Match = strings.ReplaceAll(f.Match, f.Secret, RedactedSecret)
+	}
+	f.Secret = RedactedSecret
+	return f
+}
+
+// WriteJSON 以 gitleaks JSON 报告格式输出发现
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(findings)
+}
+
+// WriteJSONFile 把 gitleaks JSON 报告写入文件
+func WriteJSONFile(path string, findings []Finding) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteJSON(f, findings); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cobraErrors cobra 和 pflag 解析命令行时的英文错误，按界面语言重新生成
+var cobraErrors = []struct {
+	pattern *regexp.Regexp
+	key     string
+}{
+	{regexp.MustCompile(`^unknown command (".*?") for (".*?")`), "cmd.errors.unknown_command"},
+	{regexp.MustCompile(`^invalid argument (".*") for (".*") flag: (.*)$`), "cmd.errors.invalid_flag_value"},
+	{regexp.MustCompile(`^invalid argument (".*?") for (".*?")`), "cmd.errors.invalid_argument"},
+	{regexp.MustCompile(`^requires at least (\d+) arg\(s\), only received (\d+)$`), "cmd.errors.min_args"},
+	{regexp.MustCompile(`^accepts at most (\d+) arg\(s\), received (\d+)$`), "cmd.errors.max_args"},
+	{regexp.MustCompile(`^accepts (\d+) arg\(s\), received (\d+)$`), "cmd.errors.exact_args"},
+	{regexp.MustCompile(`^accepts between (\d+) and (\d+) arg\(s\), received (\d+)$`), "cmd.errors.range_args"},
+	{regexp.MustCompile(`^required flag\(s\) (.*) not set$`), "cmd.errors.required_flags"},
+	{regexp.MustCompile(`^unknown flag: (.*)$`), "cmd.errors.unknown_flag"},
+	{regexp.MustCompile(`^unknown shorthand flag: (.*) in (.*)$`), "cmd.errors.unknown_shorthand"},
+	{regexp.MustCompile(`^flag needs an argument: (.*)$`), "cmd.errors.flag_needs_argument"},
+	{regexp.MustCompile(`^bad flag syntax: (.*)$`), "cmd.errors.bad_flag_syntax"},
+}
+
+// cobraSuggestions cobra 在未知命令的错误后附加的建议
+const cobraSuggestions = "\n\nDid you mean this?\n"
+
+// localizeCobraError 按界面语言重新生成 cobra 的错误信息，其他错误保持不变
+func localizeCobraError(err error) error {
+	message, suggestions, _ := strings.Cut(err.Error(), cobraSuggestions)
+	for _, e := range cobraErrors {
+		match := e.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		args := make([]interface{}, len(match)-1)
+		for i, group := range match[1:] {
+			args[i] = group
+		}
+		message = i18n.T(e.key, args...)
+		if suggestions != "" {
+			message += "\n\n" + i18n.T("cmd.errors.suggestions") + "\n" + suggestions
+		}
+		return errors.New(message)
+	}
+	return err
+}
+
+// localFlagUsages 参数说明，默认值的标注按界面语言输出
+func localFlagUsages(flags *pflag.FlagSet) string {
+	return strings.ReplaceAll(flags.FlagUsages(), " (default ", " ("+i18n.T("cmd.root.flag_default")+" ")
+}
+
+// argsAnnotation 命令参数占位符的消息键名，多个键名以空格分隔，localizeCommands 按界面语言拼接到 Use 中
+const argsAnnotation = "devex.args"
+
+// resolveUILang 按 --lang-ui 参数和配置 ui.lang（含环境变量 DEVEX_LANG_UI）确定界面语言，都没有时为空
+// 命令行参数此时还没有解析，直接从 args 中查找
+func resolveUILang(args []string) string {
+	if lang := uiLangArg(args); lang != "" {
+		return lang
+	}
+	if s, err := project.ReadSettings("."); err == nil {
+		return s.Get("ui.lang")
+	}
+	return ""
+}
+
+// uiLangArg 从命令行参数中找出 --lang-ui 的值
+func uiLangArg(args []string) string {
+	flag := "--" + project.UILangFlag
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, flag+"="); ok {
+			return value
+		}
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// localizeCommands 按界面语言重新生成所有命令的帮助文字
+// 命令的说明和参数说明在包初始化时按系统语言生成，这里按确定后的界面语言重新生成，并设置 help 参数和 help 命令的说明
+func localizeCommands(root *cobra.Command) {
+	root.SetUsageTemplate(i18n.T("cmd.root.usage_template") + "\n")
+	root.InitDefaultHelpCmd()
+	for _, c := range root.Commands() {
+		if c.Name() == "help" {
+			c.Short = i18n.T("cmd.help.short")
+			c.Long = i18n.T("cmd.help.long", root.Name())
+		}
+	}
+
+	localizeFlag := func(f *pflag.Flag) {
+		f.Usage = i18n.Retranslate(f.Usage)
+	}
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.Short = i18n.Retranslate(c.Short)
+		c.Long = i18n.Retranslate(c.Long)
+		c.Example = i18n.Retranslate(c.Example)
+		if keys := c.Annotations[argsAnnotation]; keys != "" {
+			use := c.Name()
+			for _, key := range strings.Fields(keys) {
+				use += " " + i18n.T(key)
+			}
+			c.Use = use
+		}
+		c.LocalNonPersistentFlags().VisitAll(localizeFlag)
+		c.PersistentFlags().VisitAll(localizeFlag)
+
+		c.InitDefaultHelpFlag()
+		if f := c.Flags().Lookup("help"); f != nil {
+			f.Usage = i18n.T("cmd.root.flag.help", c.Name())
+		}
+		for _, child := range c.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+	i18n.StopRecording()
+}
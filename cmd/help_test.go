@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"devex/cmd/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+func TestUILangArg(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "separate value", args: []string{"add", "--lang-ui", "zh-CN"}, want: "zh-CN"},
+		{name: "equals", args: []string{"--lang-ui=en", "doctor"}, want: "en"},
+		{name: "missing value", args: []string{"doctor", "--lang-ui"}},
+		{name: "after --", args: []string{"hook", "commit-msg", "--", "--lang-ui", "zh-CN"}},
+		{name: "not set", args: []string{"add", "--lang", "swift"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uiLangArg(tt.args); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLocalizeCommands 包初始化时按系统语言生成的帮助文字按确定后的界面语言重新生成
+func TestLocalizeCommands(t *testing.T) {
+	defer i18n.SetLocale(i18n.Locale())
+	i18n.SetLocale(i18n.English)
+
+	var value string
+	root := &cobra.Command{Use: "devex", Short: i18n.T("cmd.root.short")}
+	child := &cobra.Command{
+		Use:         "get",
+		Annotations: map[string]string{argsAnnotation: "cmd.config.args.key"},
+		Short:       i18n.T("cmd.config.get.short"),
+		Run:         func(cmd *cobra.Command, args []string) {},
+	}
+	child.Flags().StringVar(&value, "ci", "", i18n.T("cmd.flags.ci", "github, none"))
+	root.PersistentFlags().StringVar(&value, "output", "", i18n.T("cmd.root.flag.output"))
+	root.AddCommand(child)
+
+	i18n.SetLocale(i18n.Chinese)
+	localizeCommands(root)
+
+	checks := map[string][2]string{
+		"root short":  {root.Short, i18n.T("cmd.root.short")},
+		"child short": {child.Short, i18n.T("cmd.config.get.short")},
+		"child use":   {child.Use, "get " + i18n.T("cmd.config.args.key")},
+		"flag":        {child.Flags().Lookup("ci").Usage, i18n.T("cmd.flags.ci", "github, none")},
+		"persistent":  {root.PersistentFlags().Lookup("output").Usage, i18n.T("cmd.root.flag.output")},
+	}
+	for name, check := range checks {
+		if check[0] != check[1] {
+			t.Errorf("%s = %q, want %q", name, check[0], check[1])
+		}
+	}
+}
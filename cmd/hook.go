@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var hookCleanup string
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: i18n.T("cmd.hook.short"),
+	Long:  i18n.T("cmd.hook.long"),
+}
+
+var hookCommitMsgCmd = &cobra.Command{
+	Use:         "commit-msg",
+	Annotations: map[string]string{argsAnnotation: "cmd.hook.commit_msg.args"},
+	Short:       i18n.T("cmd.hook.commit_msg.short"),
+	Long:        i18n.T("cmd.hook.commit_msg.long"),
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, err := project.NewCommitMsgPolicy(project.CurrentSettings())
+		if err != nil {
+			fail(err)
+		}
+
+		stripComments, err := project.CommitMsgStripsComments(hookCleanup)
+		if err != nil {
+			fail(err)
+		}
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			failf(project.ErrIO, "cmd.hook.read_failed", err)
+		}
+
+		message := project.CleanCommitMessage(string(raw), stripComments)
+		if project.IsIgnoredCommitMsg(message) {
+			return
+		}
+
+		violations := policy.Check(message)
+		if len(violations) == 0 {
+			return
+		}
+		if jsonOutput() {
+			reporter.Result("commit_msg", violations)
+			exitFindings(i18n.T("cmd.hook.invalid"))
+		}
+
+		fmt.Println("❌ " + i18n.T("cmd.hook.invalid_title"))
+		for _, v := range violations {
+			fmt.Printf("  - [%s] %s\n", v.Rule, v.Message)
+		}
+		if message != "" {
+			fmt.Println("\n" + i18n.T("cmd.hook.message"))
+			for _, line := range strings.Split(message, "\n") {
+				fmt.Printf("  | %s\n", line)
+			}
+		}
+		fmt.Println("\n💡 " + i18n.T("cmd.hook.hint"))
+		exitFindings(i18n.T("cmd.hook.invalid"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookCommitMsgCmd)
+
+	hookCommitMsgCmd.Flags().StringVar(&hookCleanup, "cleanup", project.CleanupAuto, i18n.T("cmd.hook.commit_msg.flag.cleanup"))
+}
@@ -0,0 +1,175 @@
+// Package i18n 界面文字的多语言支持
+//
+// 所有面向用户的文字（命令帮助、进度信息、错误信息）都按键名保存在 locales 下的消息目录中，
+// 每种语言一个 YAML 文件，键名以点分隔，如 cmd.add.short。
+// 界面语言按以下优先级确定：--lang-ui 参数 > DEVEX_LANG_UI 环境变量 > 配置 ui.lang > LC_ALL > LC_MESSAGES > LANG，
+// 前两项由调用方通过 SetLocale 设置，这里只负责系统语言环境变量
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的界面语言
+const (
+	English = "en"
+	Chinese = "zh-CN"
+)
+
+// DefaultLocale 无法确定界面语言或消息缺失时使用的语言
+const DefaultLocale = English
+
+//go:embed locales/*.yaml
+var localeFS embed.FS
+
+// catalogs 各语言的消息目录
+var catalogs = map[string]map[string]string{}
+
+// locale 当前的界面语言
+var locale = DefaultLocale
+
+// message T 的键名和参数
+type message struct {
+	key  string
+	args []interface{}
+}
+
+// initTexts 调用 StopRecording 之前 T 生成的文字及其键名和参数
+// 命令的帮助文字在包初始化时按系统语言生成，早于确定界面语言，Retranslate 按确定后的界面语言重新生成
+var initTexts = map[string]message{}
+
+// recording 是否记录 T 生成的文字
+var recording = true
+
+func init() {
+	for _, name := range []string{English, Chinese} {
+		data, err := localeFS.ReadFile("locales/" + name + ".yaml")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %v", name, err))
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", name, err))
+		}
+		catalogs[name] = messages
+	}
+	locale = FromEnv()
+}
+
+// Locales 支持的界面语言
+func Locales() []string {
+	var names []string
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Normalize 将语言名称规范为支持的界面语言，如 zh、zh_CN.UTF-8 为 zh-CN，en_US 为 en
+// 不支持的语言返回 false
+func Normalize(lang string) (string, bool) {
+	lang = strings.TrimSpace(lang)
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	switch {
+	case lang == "zh" || strings.HasPrefix(lang, "zh-"):
+		return Chinese, true
+	case lang == "en" || strings.HasPrefix(lang, "en-"), lang == "c", lang == "posix":
+		return English, true
+	}
+	return "", false
+}
+
+// FromEnv 按系统语言环境变量 LC_ALL、LC_MESSAGES、LANG 确定界面语言
+// 第一个非空的变量决定语言，不支持的语言使用英文
+func FromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if lang, ok := Normalize(value); ok {
+				return lang
+			}
+			return DefaultLocale
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale 设置界面语言，lang 为空时按系统语言环境变量确定
+func SetLocale(lang string) error {
+	if lang == "" {
+		locale = FromEnv()
+		return nil
+	}
+	normalized, ok := Normalize(lang)
+	if !ok {
+		return fmt.Errorf(T("i18n.unsupported"), lang, strings.Join(Locales(), ", "))
+	}
+	locale = normalized
+	return nil
+}
+
+// Locale 当前的界面语言
+func Locale() string {
+	return locale
+}
+
+// lookup 查找消息，当前语言缺失时使用英文，都缺失时返回键名本身
+func lookup(key string) string {
+	if message, ok := catalogs[locale][key]; ok {
+		return message
+	}
+	if message, ok := catalogs[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
+
+// Has 消息目录中是否有该键
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLocale][key]
+	return ok
+}
+
+// T 获取当前语言的消息，有参数时按 fmt.Sprintf 格式化
+func T(key string, args ...interface{}) string {
+	text := lookup(key)
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+	if recording {
+		initTexts[text] = message{key: key, args: args}
+	}
+	return text
+}
+
+// Retranslate 按当前界面语言重新生成 T 生成的文字，不是 T 生成的文字原样返回
+func Retranslate(text string) string {
+	m, ok := initTexts[text]
+	if !ok {
+		return text
+	}
+	if len(m.args) == 0 {
+		return lookup(m.key)
+	}
+	return fmt.Sprintf(lookup(m.key), m.args...)
+}
+
+// StopRecording 不再记录 T 生成的文字，确定界面语言并重新生成帮助文字后调用
+func StopRecording() {
+	recording = false
+	initTexts = map[string]message{}
+}
+
+// Errorf 以当前语言的消息创建错误，格式与 fmt.Errorf 相同，可以用 %w 包装错误
+func Errorf(key string, args ...interface{}) error {
+	return fmt.Errorf(lookup(key), args...)
+}
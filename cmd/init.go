@@ -1,10 +1,11 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"devex/cmd/i18n"
 	"devex/cmd/project"
 
 	"github.com/spf13/cobra"
@@ -15,30 +16,22 @@ var (
 	initNoGit   bool
 	initNoCheck bool
 	initRemote  string
+	initLangs   []string
+	initSets    []string
+	initValues  string
+	initCI      project.CIOptions
+	initTrust   bool
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
-	Short: "初始化新项目",
-	Long: `初始化一个新的项目，包含：
-  - Git 仓库初始化
-  - 代码审查配置，包含代码敏感信息检查工具，代码风格检查工具，代码审查模板
-  - github CI配置
-  - 项目最佳实践模板
-
-示例：
-  # 通过远程仓库初始化项目
-  devex init --remote https://github.com/username/myapp.git
-
-  # 指定路径初始化（目录会自动以仓库名命名）
-  devex init --remote https://github.com/username/myapp.git --path /your/parent/dir
-`,
-	Args: cobra.NoArgs,
+	Short: i18n.T("cmd.init.short"),
+	Long:  i18n.T("cmd.init.long"),
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		// 远程仓库模式：必须指定remote
 		if initRemote == "" {
-			fmt.Println("错误：必须指定 --remote 远程仓库地址")
-			os.Exit(1)
+			failf(project.ErrUsage, "cmd.init.no_remote")
 		}
 
 		// 解析项目名和路径
@@ -48,37 +41,78 @@ var initCmd = &cobra.Command{
 		}
 		projectPath := filepath.Join(initPath, projectName)
 		if _, err := os.Stat(projectPath); !os.IsNotExist(err) {
-			fmt.Printf("错误：目录已存在：%s\n", projectPath)
-			os.Exit(1)
+			failf(project.ErrConflict, "cmd.init.dir_exists", projectPath)
 		}
 
-		fmt.Printf("初始化项目：%s\n", projectName)
+		values, err := loadTemplateValues(initValues, initSets)
+		if err != nil {
+			fail(project.WithCode(project.ErrUsage, err))
+		}
+
+		// lang 配置项是列表（add 可以同时添加多种语言），init 只生成一种语言的项目
+		if len(initLangs) > 1 {
+			failf(project.ErrUsage, "cmd.init.single_lang", strings.Join(initLangs, ", "))
+		}
+		var initLang string
+		if len(initLangs) == 1 {
+			initLang = initLangs[0]
+		}
+
+		// 终端中未指定语言时提示选择，JSON 输出用于自动化，不提示
+		prompter := project.NewTerminalPrompter()
+		if jsonOutput() {
+			prompter = nil
+		}
+		if initLang == "" && prompter != nil {
+			languages := append([]string{"none"}, project.GetSupportedLanguages()...)
+			if initLang, err = prompter.Choose(i18n.T("cmd.init.choose_lang"), languages, "none"); err != nil {
+				fail(err)
+			}
+			if initLang == "none" {
+				initLang = ""
+			}
+		}
+		if initLang == "" && len(values) > 0 {
+			reporter.Warn(project.WarnIgnoredInput, i18n.T("cmd.init.values_ignored"))
+		}
+
+		if initLang != "" && !IsLanguageSupported(initLang) {
+			failf(project.ErrUsage, "cmd.languages.unsupported_list", initLang, GetSupportedLanguagesText())
+		}
+		if err := initCI.Validate(); err != nil {
+			fail(err)
+		}
+
+		reporter.Info(i18n.T("cmd.init.start", projectName))
 
 		// 使用init命令专用的初始化器
-		initializer, err := project.NewInitializerForInit(projectName, projectPath, initNoGit, initNoCheck, initRemote)
+		initializer, err := project.NewInitializerForInit(initLang, projectName, projectPath, initNoGit, initNoCheck, initRemote)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fail(err)
 		}
+		initializer.SetReporter(reporter)
+		initializer.SetCI(initCI)
+		project.SetTemplateTrusted(initTrust)
 
-		steps := []struct {
-			name string
-			fn   func() error
-		}{
-			{"克隆远程仓库", initializer.CloneRepository},
-			{"复制模板文件", initializer.CopyTemplateFiles},
-			// {"配置代码审查", initializer.ConfigureCodeReview},
-			// {"创建项目文件", initializer.CreateProject},
-			// {"初始化依赖", initializer.InitDependencies},
-			{"安装 Git 钩子", initializer.InstallGitHooks},
+		// 在执行任何步骤前确定模板变量，缺少必需的变量时直接失败
+		if err := initializer.SetTemplateValues(values, prompter); err != nil {
+			fail(project.WithDefaultCode(project.ErrConfig, err))
 		}
 
-		for _, step := range steps {
-			if err := step.fn(); err != nil {
-				fmt.Printf("错误：%s失败：%s\n", step.name, err)
-				os.RemoveAll(projectPath)
-				os.Exit(1)
-			}
+		steps := []step{
+			{i18n.T("cmd.steps.clone"), initializer.CloneRepository},
+			{i18n.T("cmd.steps.copy_templates"), initializer.CopyTemplateFiles},
+			{i18n.T("cmd.steps.configure_review"), initializer.ConfigureCodeReview},
+			{i18n.T("cmd.steps.create_project"), initializer.CreateProject},
+			{i18n.T("cmd.steps.init_dependencies"), initializer.InitDependencies},
+			{i18n.T("cmd.steps.install_hooks"), initializer.InstallGitHooks},
+		}
+
+		// 失败或中断时回滚，删除克隆的项目目录
+		journal := project.NewJournal()
+		initializer.SetJournal(journal)
+		if err := runSteps(steps, journal); err != nil {
+			fail(err)
 		}
 
 		initializer.ShowNextSteps()
@@ -88,10 +122,21 @@ var initCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(initCmd)
 
-	initCmd.Flags().StringVarP(&initRemote, "remote", "r", "", "远程仓库地址 (必需)")
-	initCmd.Flags().StringVarP(&initPath, "path", "p", ".", "项目路径")
+	initCmd.Flags().StringVarP(&initRemote, "remote", "r", "", i18n.T("cmd.init.flag.remote"))
+	initCmd.Flags().StringVarP(&initPath, "path", "p", ".", i18n.T("cmd.flags.path"))
+	initCmd.Flags().StringSliceVarP(&initLangs, "lang", "l", nil, i18n.T("cmd.init.flag.lang", strings.Join(project.GetSupportedLanguages(), ", ")))
+	initCmd.Flags().StringArrayVar(&initSets, "set", nil, i18n.T("cmd.init.flag.set"))
+	initCmd.Flags().StringVar(&initValues, "values", "", i18n.T("cmd.init.flag.values"))
 	// initCmd.Flags().BoolVar(&initNoGit, "no-git", false, "不初始化 Git 仓库")
-	initCmd.Flags().BoolVar(&initNoCheck, "no-check", false, "不添加代码审查配置")
+	initCmd.Flags().BoolVar(&initNoCheck, "no-check", false, i18n.T("cmd.init.flag.no_check"))
+	initCmd.Flags().StringVar(&initCI.Provider, "ci", project.CINone, i18n.T("cmd.flags.ci", strings.Join(project.GetCIProviders(), ", ")))
+	initCmd.Flags().StringVar(&initCI.DefaultBranch, "ci-branch", "", i18n.T("cmd.flags.ci_branch"))
+	initCmd.Flags().StringVar(&initCI.Version, "ci-version", "", i18n.T("cmd.flags.ci_version"))
+	initCmd.Flags().BoolVar(&initTrust, "trust-template", false, i18n.T("cmd.init.flag.trust_template"))
+	bindConfig(initCmd.Flags(), "lang", "lang")
+	bindConfig(initCmd.Flags(), "ci", "ci.provider")
+	bindConfig(initCmd.Flags(), "ci-branch", "ci.branch")
+	bindConfig(initCmd.Flags(), "ci-version", "ci.version")
 
 	initCmd.MarkFlagRequired("remote")
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"devex/cmd/project"
+)
+
+// outputFormat 命令输出格式：text 或 json（JSON Lines）
+var outputFormat string
+
+// reporter 当前命令的输出，初始化器等通过它报告进度
+var reporter project.Reporter = project.CurrentReporter()
+
+// setOutput 按输出格式创建 reporter，同时作为 project 包的默认输出
+func setOutput(format string) error {
+	r, err := project.NewReporter(format)
+	if err != nil {
+		return err
+	}
+	outputFormat = format
+	reporter = r
+	project.SetReporter(r)
+	return nil
+}
+
+// jsonOutput 是否以 JSON Lines 输出，命令的结果通过 reporter.Result 输出而不是打印文本
+func jsonOutput() bool {
+	return outputFormat == project.OutputJSON
+}
+
+// fail 输出错误并以错误类别对应的退出码退出
+func fail(err error) {
+	code := project.ErrorCodeOf(err)
+	reporter.Error(code, err)
+	os.Exit(project.ExitCode(code))
+}
+
+// failf 以指定的错误码和消息目录中 key 对应的消息输出错误并退出
+func failf(code project.ErrorCode, key string, args ...interface{}) {
+	fail(project.Errorf(code, key, args...))
+}
+
+// exitFindings 检查发现问题（敏感信息、不合规的提交信息、诊断失败项）时退出
+// 文本输出中问题已由命令打印，不再重复输出错误
+func exitFindings(message string) {
+	if jsonOutput() {
+		reporter.Error(project.ErrFindings, errors.New(message))
+	}
+	os.Exit(project.ExitCode(project.ErrFindings))
+}
@@ -1,8 +1,11 @@
 package project
 
 import (
-	"fmt"
+	"io/fs"
 	"path/filepath"
+	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // AddInitializer 代码审查功能添加器
@@ -12,26 +15,73 @@ type AddInitializer struct {
 }
 
 // NewAddInitializer 创建代码审查功能添加器
-func NewAddInitializer(projectPath string) (*AddInitializer, error) {
-	globalConfigPath, err := getTemplatePath("global_config")
+// languages 为空时根据项目中的标记文件自动检测语言，每种语言的 config 目录叠加在全局配置之上
+// reporter 为检测结果和后续步骤的输出，为空时使用默认的 Reporter
+func NewAddInitializer(projectPath string, languages []string, reporter Reporter) (*AddInitializer, error) {
+	templateFS, globalConfigPath, err := getTemplatePath("global_config")
 	if err != nil {
-		return nil, fmt.Errorf("无法找到模板路径: %w", err)
+		return nil, i18n.Errorf("factory.template_not_found", err)
+	}
+
+	if reporter == nil {
+		reporter = defaultReporter
+	}
+	if len(languages) == 0 {
+		if languages, err = detectAddLanguages(projectPath, reporter); err != nil {
+			return nil, err
+		}
+	}
+
+	var layers []string
+	for _, lang := range languages {
+		config, err := GetLanguageConfig(lang)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fs.Stat(templateFS, config.ConfigPath); err != nil {
+			return nil, i18n.Errorf("add_initializer.no_config", config.DisplayName, config.ConfigPath)
+		}
+		layers = append(layers, config.ConfigPath)
 	}
 
 	return &AddInitializer{
 		BaseInitializer: BaseInitializer{
+			TemplateFS:       templateFS,
 			ProjectName:      filepath.Base(projectPath), // 使用目录名作为项目名
 			FilePath:         projectPath,
 			GlobalConfigPath: globalConfigPath,
-			ConfigPath:       "template/config",
-			TemplateCodePath: "template/code",
+			ConfigLayers:     layers,
 			NoGit:            false, // add命令默认不跳过Git
 			NoCheck:          false, // add命令默认启用检查
 			RemoteURL:        "",    // add命令不需要远程URL
+			reporter:         reporter,
 		},
 	}, nil
 }
 
+// detectAddLanguages 检测项目语言，返回有对应配置的语言
+func detectAddLanguages(projectPath string, reporter Reporter) ([]string, error) {
+	detected, err := DetectLanguages(projectPath)
+	if err != nil {
+		return nil, Errorf(ErrIO, "add_initializer.detect_failed", err)
+	}
+
+	reporter.Progress("🔎", i18n.T("add_initializer.detecting"))
+	var languages []string
+	for _, d := range detected {
+		if d.Supported {
+			reporter.Detail(i18n.T("add_initializer.detected", d.Language, strings.Join(d.Markers, ", ")))
+			languages = append(languages, d.Language)
+		} else {
+			reporter.Detail(i18n.T("add_initializer.detected_no_template", d.Language, strings.Join(d.Markers, ", ")))
+		}
+	}
+	if len(detected) == 0 {
+		reporter.Detail(i18n.T("add_initializer.none_detected"))
+	}
+	return languages, nil
+}
+
 // CloneRepository add命令不需要克隆仓库
 func (a *AddInitializer) CloneRepository() error {
 	return nil
@@ -39,32 +89,32 @@ func (a *AddInitializer) CloneRepository() error {
 
 // CreateProject 检测现有项目，对于add命令不需要创建项目
 func (a *AddInitializer) CreateProject() error {
-	fmt.Println("📂 检测项目...")
-	fmt.Println("  - 分析项目结构")
-	fmt.Println("  - 准备添加代码审查支持")
+	a.report().Progress("📂", i18n.T("add_initializer.checking"))
+	a.report().Detail(i18n.T("add_initializer.analyzing"))
+	a.report().Detail(i18n.T("add_initializer.preparing"))
 	return nil
 }
 
 // InitDependencies 检查现有依赖，对于add命令不需要初始化依赖
 func (a *AddInitializer) InitDependencies() error {
-	fmt.Println("📥 检查项目依赖...")
-	fmt.Println("  - 检查项目配置")
-	fmt.Println("  - 验证基础环境")
+	a.report().Progress("📥", i18n.T("add_initializer.checking_deps"))
+	a.report().Detail(i18n.T("add_initializer.checking_config"))
+	a.report().Detail(i18n.T("add_initializer.verifying_env"))
 	return nil
 }
 
 // ConfigureCodeReview 添加代码审查配置
 func (a *AddInitializer) ConfigureCodeReview() error {
 	if a.NoCheck {
-		fmt.Println("⏭️  跳过代码审查配置 (使用了--no-check参数)")
+		a.report().Progress("⏭️ ", i18n.T("add_initializer.skip_review"))
 		return nil
 	}
 
-	fmt.Println("🔍 添加代码审查配置...")
-	fmt.Println("  - 添加通用代码检查配置")
-	fmt.Println("  - 设置Git钩子")
-	fmt.Println("  - 配置基础CI")
-	fmt.Println("  - 添加审查模板")
+	a.report().Progress("🔍", i18n.T("add_initializer.adding_review"))
+	a.report().Detail(i18n.T("add_initializer.adding_common"))
+	a.report().Detail(i18n.T("add_initializer.setting_hooks"))
+	a.report().Detail(i18n.T("add_initializer.configuring_ci"))
+	a.report().Detail(i18n.T("add_initializer.adding_templates"))
 	return nil
 }
 
@@ -72,8 +122,8 @@ func (a *AddInitializer) ConfigureCodeReview() error {
 func (a *AddInitializer) ShowNextSteps() {
 	a.BaseInitializer.ShowNextSteps()
 
-	fmt.Println("\n代码审查配置完成，下一步：")
-	fmt.Println("1. 安装相应的代码检查工具")
-	fmt.Println("2. 运行代码检查命令")
-	fmt.Println("3. 启用Git钩子检查")
+	a.report().Info("\n" + i18n.T("add_initializer.next"))
+	a.report().Info(i18n.T("add_initializer.next_1"))
+	a.report().Info(i18n.T("add_initializer.next_2"))
+	a.report().Info(i18n.T("add_initializer.next_3"))
 }
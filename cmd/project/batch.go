@@ -0,0 +1,212 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"devex/cmd/i18n"
+)
+
+// BatchStatus 批量执行中单个仓库的结果
+type BatchStatus string
+
+const (
+	BatchApplied    BatchStatus = "applied"    // 写入了变更
+	BatchSkipped    BatchStatus = "skipped"    // 已是最新，或匹配到的路径不是 Git 仓库（见 Reason）
+	BatchConflicted BatchStatus = "conflicted" // 部分文件无法合并，写入了 .devex-new 旁路文件
+	BatchFailed     BatchStatus = "failed"     // 执行失败，该仓库的修改已回滚
+)
+
+// batchStatuses 汇总中各结果的顺序
+var batchStatuses = []BatchStatus{BatchApplied, BatchSkipped, BatchConflicted, BatchFailed}
+
+// BatchResult 批量执行中单个仓库的结果
+type BatchResult struct {
+	Repo      string      `json:"repo"`
+	Status    BatchStatus `json:"status"`
+	Files     []string    `json:"files,omitempty"`     // 写入的文件，相对于仓库根目录
+	Conflicts []string    `json:"conflicts,omitempty"` // 无法合并的文件
+	Branch    string      `json:"branch,omitempty"`    // 创建的分支
+	Commit    string      `json:"commit,omitempty"`    // 创建的提交
+	Code      ErrorCode   `json:"code,omitempty"`      // 失败时的错误码
+	Error     string      `json:"error,omitempty"`     // 失败原因
+	Reason    string      `json:"reason,omitempty"`    // 跳过原因，已是最新时为空
+}
+
+// ReadRepoList 读取仓库列表文件，每行一个仓库路径，忽略空行和 # 开头的注释
+// 相对路径相对于列表文件所在的目录
+func ReadRepoList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Errorf(ErrIO, "batch.read_list_failed", path, err)
+	}
+	defer file.Close()
+
+	var repos []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+		repos = append(repos, filepath.Clean(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, Errorf(ErrIO, "batch.read_list_failed", path, err)
+	}
+	return repos, nil
+}
+
+// GlobRepos 在工作区目录中按通配符查找 Git 仓库
+// 匹配到的文件和非仓库目录不会处理，作为跳过的结果返回，在汇总中列出原因
+func GlobRepos(workspace, pattern string) ([]string, []BatchResult, error) {
+	matches, err := filepath.Glob(filepath.Join(workspace, pattern))
+	if err != nil {
+		return nil, nil, Errorf(ErrUsage, "batch.bad_glob", pattern, err)
+	}
+
+	var repos []string
+	var skipped []BatchResult
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || !info.IsDir() {
+			skipped = append(skipped, BatchResult{Repo: match, Status: BatchSkipped, Reason: i18n.T("batch.skip.not_dir")})
+			continue
+		}
+		// .git 为目录（普通仓库）或文件（worktree、submodule）
+		if _, err := os.Stat(filepath.Join(match, ".git")); err != nil {
+			skipped = append(skipped, BatchResult{Repo: match, Status: BatchSkipped, Reason: i18n.T("batch.skip.not_git")})
+			continue
+		}
+		repos = append(repos, match)
+	}
+	return repos, skipped, nil
+}
+
+// UniqueRepos 去掉重复的仓库，保持原有顺序
+func UniqueRepos(repos []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, repo := range repos {
+		key := repo
+		if abs, err := filepath.Abs(repo); err == nil {
+			key = abs
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, repo)
+	}
+	return unique
+}
+
+// RunBatch 以最多 workers 个并发处理各仓库，结果按 repos 的顺序返回
+// 单个仓库的 panic 会被记录为该仓库失败，不影响其他仓库
+func RunBatch(repos []string, workers int, fn func(repo string) BatchResult) []BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]BatchResult, len(repos))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runBatchJob(repos[i], fn)
+			}
+		}()
+	}
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// runBatchJob 处理单个仓库，panic 转为失败结果
+func runBatchJob(repo string, fn func(repo string) BatchResult) (result BatchResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = BatchResult{Repo: repo, Status: BatchFailed, Code: ErrInternal, Error: fmt.Sprint(r)}
+		}
+	}()
+	return fn(repo)
+}
+
+// BatchFailure 第一个失败仓库的错误，没有失败时为空
+func BatchFailure(results []BatchResult) error {
+	var failed []string
+	code := ErrInternal
+	for _, result := range results {
+		if result.Status != BatchFailed {
+			continue
+		}
+		if len(failed) == 0 {
+			code = result.Code
+		}
+		failed = append(failed, result.Repo)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return Errorf(code, "batch.failed", len(failed), strings.Join(failed, ", "))
+}
+
+// PrintBatchSummary 输出批量执行的汇总表
+func PrintBatchSummary(w io.Writer, results []BatchResult) {
+	fmt.Fprintln(w, "\n📊 "+i18n.T("batch.summary"))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, i18n.T("batch.header"))
+	counts := make(map[BatchStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Repo, i18n.T("batch.status."+string(result.Status)), batchDetail(result))
+	}
+	tw.Flush()
+
+	var totals []string
+	for _, status := range batchStatuses {
+		totals = append(totals, i18n.T("batch.count", i18n.T("batch.status."+string(status)), counts[status]))
+	}
+	fmt.Fprintln(w, i18n.T("batch.total", len(results), strings.Join(totals, i18n.T("batch.separator"))))
+}
+
+// batchDetail 汇总表中单个仓库的说明
+func batchDetail(result BatchResult) string {
+	switch result.Status {
+	case BatchFailed:
+		// 多行的错误信息只保留第一行
+		message, _, _ := strings.Cut(result.Error, "\n")
+		return message
+	case BatchConflicted:
+		return i18n.T("batch.detail.conflicts", strings.Join(result.Conflicts, ", "))
+	case BatchSkipped:
+		if result.Reason != "" {
+			return result.Reason
+		}
+		return i18n.T("batch.detail.up_to_date")
+	}
+
+	detail := i18n.T("batch.detail.files", len(result.Files))
+	if result.Branch != "" {
+		detail += i18n.T("batch.separator") + i18n.T("batch.detail.branch", result.Branch)
+	}
+	if result.Commit != "" {
+		detail += i18n.T("batch.separator") + i18n.T("batch.detail.commit", result.Commit)
+	}
+	return detail
+}
@@ -0,0 +1,94 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"devex/cmd/i18n"
+)
+
+func TestGlobRepos(t *testing.T) {
+	old := i18n.Locale()
+	t.Cleanup(func() { i18n.SetLocale(old) })
+	if err := i18n.SetLocale("en"); err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := t.TempDir()
+	mkdir(t, filepath.Join(workspace, "ios-app", ".git"))
+	writeTestFile(t, filepath.Join(workspace, "ios-worktree", ".git"), "gitdir: ../ios-app/.git/worktrees/w\n")
+	mkdir(t, filepath.Join(workspace, "ios-docs"))
+	writeTestFile(t, filepath.Join(workspace, "ios-notes.txt"), "notes\n")
+	mkdir(t, filepath.Join(workspace, "android-app", ".git"))
+
+	tests := []struct {
+		pattern     string
+		wantRepos   []string
+		wantSkipped []BatchResult
+		wantErr     bool
+	}{
+		{
+			pattern:   "ios-*",
+			wantRepos: []string{filepath.Join(workspace, "ios-app"), filepath.Join(workspace, "ios-worktree")},
+			wantSkipped: []BatchResult{
+				{Repo: filepath.Join(workspace, "ios-docs"), Status: BatchSkipped, Reason: "not a Git repository"},
+				{Repo: filepath.Join(workspace, "ios-notes.txt"), Status: BatchSkipped, Reason: "not a directory"},
+			},
+		},
+		{pattern: "android-*", wantRepos: []string{filepath.Join(workspace, "android-app")}},
+		{pattern: "web-*"},
+		{pattern: "[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			repos, skipped, err := GlobRepos(workspace, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(repos, tt.wantRepos) {
+				t.Errorf("repos = %v, want %v", repos, tt.wantRepos)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %+v, want %+v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestBatchSkippedReason(t *testing.T) {
+	old := i18n.Locale()
+	t.Cleanup(func() { i18n.SetLocale(old) })
+	if err := i18n.SetLocale("en"); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []BatchResult{
+		{Repo: "app", Status: BatchSkipped},
+		{Repo: "docs", Status: BatchSkipped, Reason: "not a Git repository"},
+	}
+
+	var buf bytes.Buffer
+	PrintBatchSummary(&buf, results)
+	wantSummary := `
+📊 Batch results:
+REPOSITORY  RESULT   DETAILS
+app         skipped  up to date
+docs        skipped  not a Git repository
+2 repositories: applied 0, skipped 2, conflicted 0, failed 0
+`
+	if buf.String() != wantSummary {
+		t.Errorf("summary:\n%s\nwant:\n%s", buf.String(), wantSummary)
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"repo":"app","status":"skipped"},{"repo":"docs","status":"skipped","reason":"not a Git repository"}]`
+	if string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+}
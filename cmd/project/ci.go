@@ -0,0 +1,197 @@
+package project
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devex/cmd/i18n"
+)
+
+// CI 平台
+const (
+	CIGitHub = "github" // GitHub Actions，生成 .github/workflows/devex.yml
+	CINone   = "none"   // 不生成 CI 配置
+)
+
+// ciTemplateRoot CI 模板层的目录，每个 CI 平台一个子目录，如 ci/github
+const ciTemplateRoot = "ci"
+
+// ciBranchPattern 工作流触发分支允许的字符，支持 GitHub 的通配符写法（如 release/*）
+var ciBranchPattern = regexp.MustCompile(`^[A-Za-z0-9._/*-]+$`)
+
+// releaseVersionPattern 发布版本的标签，如 v1.2.3、v1.2.3-rc.1
+// git describe 生成的开发版本（如 v0.1.0-5-gabc123-dirty）和 dev 没有对应的发布，CI 无法安装
+var releaseVersionPattern = regexp.MustCompile(`^v(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-(alpha|beta|rc)(\.[0-9]+)?)?$`)
+
+// CIOptions 生成 CI 配置的选项，写入 .devex.lock 以便升级时按相同的选项重新生成
+type CIOptions struct {
+	Provider      string `json:"provider"`          // CI 平台，为空或 none 时不生成
+	DefaultBranch string `json:"default_branch"`    // 工作流触发的分支，为空时在复制模板时检测项目的默认分支
+	Version       string `json:"version,omitempty"` // CI 中安装的 devex 版本，为空时使用生成工作流的 devex 版本
+}
+
+// Enabled 是否需要生成 CI 配置
+func (o CIOptions) Enabled() bool {
+	return o.Provider != "" && o.Provider != CINone
+}
+
+// Layer CI 模板层的目录（相对于模板根目录）
+func (o CIOptions) Layer() string {
+	return path.Join(ciTemplateRoot, o.Provider)
+}
+
+// InstallVersion CI 中安装的 devex 版本
+// 没有指定版本时使用当前 devex 的版本，版本不是发布版本时返回错误，避免生成无法安装 devex 的工作流
+func (o CIOptions) InstallVersion() (string, error) {
+	if o.Version != "" {
+		if err := validateCIVersion(o.Version); err != nil {
+			return "", WithCode(ErrUsage, err)
+		}
+		return o.Version, nil
+	}
+	if !releaseVersionPattern.MatchString(Version) {
+		return "", Errorf(ErrUsage, "ci.version_required", Version)
+	}
+	return Version, nil
+}
+
+// Validate 校验 CI 平台、分支名和安装的 devex 版本
+func (o CIOptions) Validate() error {
+	if o.Provider != "" {
+		if err := validateCIProvider(o.Provider); err != nil {
+			return WithCode(ErrUsage, err)
+		}
+	}
+	if o.DefaultBranch != "" {
+		if err := validateCIBranch(o.DefaultBranch); err != nil {
+			return WithCode(ErrUsage, err)
+		}
+	}
+	if o.Version != "" {
+		if err := validateCIVersion(o.Version); err != nil {
+			return WithCode(ErrUsage, err)
+		}
+	}
+	if o.Enabled() {
+		if _, err := o.InstallVersion(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCIProviders 获取支持的 CI 平台
+func GetCIProviders() []string {
+	return []string{CIGitHub, CINone}
+}
+
+// validateCIProvider 校验 CI 平台
+func validateCIProvider(value string) error {
+	for _, provider := range GetCIProviders() {
+		if value == provider {
+			return nil
+		}
+	}
+	return i18n.Errorf("ci.unsupported", value, strings.Join(GetCIProviders(), ", "))
+}
+
+// validateCIBranch 校验工作流触发的分支名
+func validateCIBranch(value string) error {
+	if !ciBranchPattern.MatchString(value) {
+		return i18n.Errorf("ci.bad_branch", value)
+	}
+	return nil
+}
+
+// validateCIVersion 校验 CI 中安装的 devex 版本，必须是发布版本的标签
+func validateCIVersion(value string) error {
+	if !releaseVersionPattern.MatchString(value) {
+		return i18n.Errorf("ci.bad_version", value)
+	}
+	return nil
+}
+
+// DetectDefaultBranch 检测项目的默认分支
+// 依次使用远程仓库 origin 的 HEAD、本地的 main 或 master 分支、init.defaultBranch 和当前分支，都没有时为 main
+func DetectDefaultBranch(repo string) string {
+	if out, err := runGit(repo, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(strings.TrimSpace(out), "origin/"); branch != "" {
+			return branch
+		}
+	}
+	for _, branch := range []string{"main", "master"} {
+		if _, err := runGit(repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+			return branch
+		}
+	}
+	if out, err := runGit(repo, "config", "init.defaultBranch"); err == nil && strings.TrimSpace(out) != "" {
+		return strings.TrimSpace(out)
+	}
+	if out, err := runGit(repo, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil && strings.TrimSpace(out) != "" {
+		return strings.TrimSpace(out)
+	}
+	return "main"
+}
+
+// collectCITemplates 渲染 CI 模板层中的文件
+// 模板变量为 DefaultBranch、Version（CI 中安装的 devex 版本，见 InstallVersion）、Languages（逗号分隔），以及每种支持的语言一个变量（如 Swift），叠加了该语言配置层时为 true
+func collectCITemplates(fsys fs.FS, ci CIOptions, layers []string) ([]templateFile, error) {
+	if info, err := fs.Stat(fsys, ci.Layer()); err != nil || !info.IsDir() {
+		return nil, Errorf(ErrConfig, "ci.no_template", ci.Provider, ci.Layer())
+	}
+
+	files, err := collectTemplates(fsys, []string{ci.Layer()})
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := ciTemplateVars(ci, layers)
+	if err != nil {
+		return nil, err
+	}
+	for i, file := range files {
+		content, err := renderTemplate(path.Join(ci.Layer(), file.Path), string(file.Content), vars, true)
+		if err != nil {
+			return nil, WithCode(ErrConfig, err)
+		}
+		files[i].Content = []byte(content)
+	}
+	return files, nil
+}
+
+// ciTemplateVars CI 模板的变量
+func ciTemplateVars(ci CIOptions, layers []string) (map[string]string, error) {
+	version, err := ci.InstallVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool)
+	for _, layer := range layers {
+		applied[layer] = true
+	}
+
+	vars := map[string]string{"DefaultBranch": ci.DefaultBranch, "Version": version}
+	var languages []string
+	for _, lang := range GetSupportedLanguages() {
+		config, _ := GetLanguageConfig(lang)
+		value := ""
+		if applied[config.ConfigPath] {
+			value = "true"
+			languages = append(languages, lang)
+		}
+		vars[pascalCase(lang)] = value
+	}
+	vars["Languages"] = strings.Join(languages, ",")
+	return vars, nil
+}
+
+// mergeTemplateFiles 合并两组路径不同的模板文件，结果按路径排序
+func mergeTemplateFiles(files, extra []templateFile) []templateFile {
+	files = append(files, extra...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
@@ -0,0 +1,119 @@
+package project
+
+import (
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+
+	devextemplate "devex/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestGitHubWorkflow 渲染的工作流是合法的 YAML，每个 run 脚本都能通过 sh -n 检查
+func TestGitHubWorkflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+		jobs   []string
+	}{
+		{name: "no language", jobs: []string{"review"}},
+		{name: "swift", layers: []string{"swift/config"}, jobs: []string{"review", "swiftlint"}},
+		{name: "swift and kotlin", layers: []string{"swift/config", "kotlin/config"}, jobs: []string{"kotlin-lint", "review", "swiftlint"}},
+	}
+	defer SetVersion(Version)
+	SetVersion("v1.2.3")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := collectCITemplates(devextemplate.FS, CIOptions{Provider: CIGitHub, DefaultBranch: "main"}, tt.layers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 1 || files[0].Path != ".github/workflows/devex.yml" {
+				t.Fatalf("files = %+v", files)
+			}
+
+			var workflow struct {
+				Jobs map[string]struct {
+					Steps []struct {
+						Name string            `yaml:"name"`
+						Env  map[string]string `yaml:"env"`
+						Run  string            `yaml:"run"`
+					} `yaml:"steps"`
+				} `yaml:"jobs"`
+			}
+			if err := yaml.Unmarshal(files[0].Content, &workflow); err != nil {
+				t.Fatalf("invalid YAML: %v\n%s", err, files[0].Content)
+			}
+
+			var jobs []string
+			for name, job := range workflow.Jobs {
+				jobs = append(jobs, name)
+				for _, step := range job.Steps {
+					// CI 安装生成工作流的 devex 版本，而不是 main 分支上的最新安装脚本
+					if step.Name == "Install devex" {
+						if step.Env["DEVEX_VERSION"] != "v1.2.3" || !strings.Contains(step.Run, `--version "$DEVEX_VERSION"`) || strings.Contains(step.Run, "/main/") {
+							t.Errorf("devex is not pinned to the generating version: %v\n%s", step.Env, step.Run)
+						}
+					}
+					if step.Run == "" {
+						continue
+					}
+					cmd := exec.Command("sh", "-n")
+					cmd.Stdin = strings.NewReader(step.Run)
+					if out, err := cmd.CombinedOutput(); err != nil {
+						t.Errorf("job %s: sh -n: %v\n%s\n%s", name, err, out, step.Run)
+					}
+				}
+			}
+			sort.Strings(jobs)
+			if got := strings.Join(jobs, ","); got != strings.Join(tt.jobs, ",") {
+				t.Errorf("jobs = %s, want %s", got, strings.Join(tt.jobs, ","))
+			}
+		})
+	}
+}
+
+// TestCIInstallVersion CI 只能安装发布版本，开发版本需要通过 --ci-version 指定
+func TestCIInstallVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string // 当前 devex 的版本
+		pinned  string // --ci-version
+		want    string
+		wantErr bool
+	}{
+		{name: "release", version: "v1.2.3", want: "v1.2.3"},
+		{name: "release candidate", version: "v1.2.3-rc.1", want: "v1.2.3-rc.1"},
+		{name: "dev build", version: "dev", wantErr: true},
+		{name: "git describe", version: "v0.1.0-5-gabc123-dirty", wantErr: true},
+		{name: "pinned on a dev build", version: "v0.1.0-5-gabc123-dirty", pinned: "v0.1.0", want: "v0.1.0"},
+		{name: "pinned overrides a release", version: "v1.2.3", pinned: "v1.0.0", want: "v1.0.0"},
+		{name: "pinned to a non-release", version: "v1.2.3", pinned: "main", wantErr: true},
+	}
+	defer SetVersion(Version)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetVersion(tt.version)
+			ci := CIOptions{Provider: CIGitHub, DefaultBranch: "main", Version: tt.pinned}
+			err := ci.Validate()
+			files, renderErr := collectCITemplates(devextemplate.FS, ci, nil)
+			if tt.wantErr {
+				if err == nil || renderErr == nil {
+					t.Fatalf("Validate = %v, render = %v, want errors", err, renderErr)
+				}
+				if code := ErrorCodeOf(err); code != ErrUsage {
+					t.Errorf("code = %s, want %s", code, ErrUsage)
+				}
+				return
+			}
+			if err != nil || renderErr != nil {
+				t.Fatalf("Validate = %v, render = %v", err, renderErr)
+			}
+			if !strings.Contains(string(files[0].Content), `DEVEX_VERSION: "`+tt.want+`"`) {
+				t.Errorf("workflow does not install %s:\n%s", tt.want, files[0].Content)
+			}
+		})
+	}
+}
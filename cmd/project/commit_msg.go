@@ -0,0 +1,273 @@
+package project
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"devex/cmd/i18n"
+)
+
+// 提交信息规则，配置项见 config.go 中的 commit_msg.*
+const (
+	RuleEmpty            = "empty"
+	RuleConventional     = "conventional-format"
+	RuleType             = "type-enum"
+	RuleScope            = "scope-enum"
+	RuleScopeRequired    = "scope-required"
+	RuleSubjectMaxLength = "subject-max-length"
+	RuleSubjectMinLength = "subject-min-length"
+	RuleForbiddenChars   = "forbidden-chars"
+	RuleIssueKey         = "issue-key"
+	RuleBodyLeadingBlank = "body-leading-blank"
+)
+
+// scissorsLine git commit --verbose 时，该行之后的内容不属于提交信息
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// conventionalPattern Conventional Commits 标题：<type>(<scope>)!: <description>
+var conventionalPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// ignoredCommitPrefixes 不检查的提交：git commit --fixup/--squash 及 git、GitHub 生成的合并提交
+var ignoredCommitPrefixes = []string{
+	"fixup! ", "squash! ", "amend! ",
+	"Merge branch '", "Merge pull request #", "Merge remote-tracking branch '",
+}
+
+// 提交信息的清理方式，与 git commit --cleanup 同名
+const (
+	CleanupAuto       = "auto"       // 与 git 的默认方式相同：打开了编辑器时为 strip，否则为 whitespace
+	CleanupStrip      = "strip"      // 去掉注释行和 --verbose 的差异部分
+	CleanupWhitespace = "whitespace" // 保留 # 开头的行
+)
+
+// charClass 可禁止的字符类别
+type charClass struct {
+	Name  string
+	Match func(r rune) bool
+}
+
+// charClasses 可在 commit_msg.forbidden_chars 中使用的字符类别
+var charClasses = []charClass{
+	{Name: "cjk", Match: isCJK},
+	{Name: "emoji", Match: isEmoji},
+	{Name: "non_ascii", Match: func(r rune) bool { return r > unicode.MaxASCII }},
+	{Name: "control", Match: func(r rune) bool { return unicode.IsControl(r) && r != '\t' && r != '\n' }},
+}
+
+// describe 字符类别的说明
+func (c charClass) describe() string {
+	return i18n.T("commit_msg.class." + c.Name)
+}
+
+// isCJK 判断是否为中日韩文字、假名、谚文或全角标点
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK 标点
+		(r >= 0xFF00 && r <= 0xFFEF) // 全角字符
+}
+
+// isEmoji 判断是否为常见的 emoji
+func isEmoji(r rune) bool {
+	return (r >= 0x1F300 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x1F1E6 && r <= 0x1F1FF)
+}
+
+// findCharClass 按名称查找字符类别
+func findCharClass(name string) (charClass, bool) {
+	for _, class := range charClasses {
+		if class.Name == name {
+			return class, true
+		}
+	}
+	return charClass{}, false
+}
+
+// validateCharClasses 校验字符类别列表
+func validateCharClasses(value string) error {
+	for _, name := range splitList(value) {
+		if _, ok := findCharClass(name); !ok {
+			var names []string
+			for _, class := range charClasses {
+				names = append(names, class.Name)
+			}
+			return Errorf(ErrConfig, "commit_msg.unsupported_class", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// CommitMsgPolicy 提交信息规范
+type CommitMsgPolicy struct {
+	Conventional     bool           // 标题必须符合 Conventional Commits
+	Types            []string       // 允许的类型
+	Scopes           []string       // 允许的范围，为空时不限制
+	RequireScope     bool           // 必须填写范围
+	SubjectMinLength int            // 标题最短长度，0 表示不限制
+	SubjectMaxLength int            // 标题最长长度，0 表示不限制
+	ForbiddenChars   []string       // 禁止的字符类别
+	IssuePattern     *regexp.Regexp // 提交信息中必须包含的问题编号，为空时不要求
+}
+
+// CommitMsgViolation 违反的规则
+type CommitMsgViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// NewCommitMsgPolicy 根据配置创建提交信息规范
+func NewCommitMsgPolicy(s *Settings) (*CommitMsgPolicy, error) {
+	for _, key := range configKeys {
+		if strings.HasPrefix(key.Name, "commit_msg.") {
+			if _, _, err := s.Lookup(key.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	policy := &CommitMsgPolicy{
+		Conventional:     s.Bool("commit_msg.conventional"),
+		Types:            s.List("commit_msg.types"),
+		Scopes:           s.List("commit_msg.scopes"),
+		RequireScope:     s.Bool("commit_msg.require_scope"),
+		SubjectMinLength: s.Int("commit_msg.subject_min_length"),
+		SubjectMaxLength: s.Int("commit_msg.subject_max_length"),
+		ForbiddenChars:   s.List("commit_msg.forbidden_chars"),
+	}
+	if pattern := s.Get("commit_msg.issue_pattern"); pattern != "" {
+		policy.IssuePattern = regexp.MustCompile(pattern) // 已在读取配置时校验
+	}
+	return policy, nil
+}
+
+// CommitMsgStripsComments 按清理方式判断是否去掉注释行
+// git 未打开编辑器（-m、-F）时为钩子设置 GIT_EDITOR=:，此时 # 开头的行（如 #123 fix crash）是提交信息的一部分
+func CommitMsgStripsComments(cleanup string) (bool, error) {
+	switch cleanup {
+	case CleanupAuto:
+		return os.Getenv("GIT_EDITOR") != ":", nil
+	case CleanupStrip:
+		return true, nil
+	case CleanupWhitespace:
+		return false, nil
+	}
+	return false, Errorf(ErrUsage, "commit_msg.bad_cleanup", cleanup, strings.Join([]string{CleanupAuto, CleanupStrip, CleanupWhitespace}, ", "))
+}
+
+// CleanCommitMessage 按 git 的方式清理提交信息：去掉行尾空白和首尾空行
+// stripComments 为 true 时（git 打开编辑器时的默认方式）还去掉注释行和 --verbose 的差异部分
+func CleanCommitMessage(raw string, stripComments bool) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if stripComments && line == scissorsLine {
+			break
+		}
+		if stripComments && strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// IsIgnoredCommitMsg 是否为不检查的提交（fixup!、squash!、amend! 和合并提交）
+func IsIgnoredCommitMsg(message string) bool {
+	for _, prefix := range ignoredCommitPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 检查清理后的提交信息，返回违反的所有规则
+func (p *CommitMsgPolicy) Check(message string) []CommitMsgViolation {
+	var violations []CommitMsgViolation
+	add := func(rule, key string, args ...interface{}) {
+		violations = append(violations, CommitMsgViolation{Rule: rule, Message: i18n.T(key, args...)})
+	}
+
+	if strings.TrimSpace(message) == "" {
+		add(RuleEmpty, "commit_msg.empty")
+		return violations
+	}
+
+	lines := strings.Split(message, "\n")
+	subject := lines[0]
+
+	if p.Conventional {
+		p.checkConventional(subject, add)
+	}
+
+	length := len([]rune(subject))
+	if p.SubjectMaxLength > 0 && length > p.SubjectMaxLength {
+		add(RuleSubjectMaxLength, "commit_msg.subject_too_long", length, p.SubjectMaxLength)
+	}
+	if p.SubjectMinLength > 0 && length < p.SubjectMinLength {
+		add(RuleSubjectMinLength, "commit_msg.subject_too_short", length, p.SubjectMinLength)
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		add(RuleBodyLeadingBlank, "commit_msg.body_leading_blank")
+	}
+
+	for _, name := range p.ForbiddenChars {
+		class, ok := findCharClass(name)
+		if !ok {
+			continue
+		}
+		if line, r, found := findRune(lines, class.Match); found {
+			add(RuleForbiddenChars, "commit_msg.forbidden_chars", line, class.describe(), r, class.Name)
+		}
+	}
+
+	if p.IssuePattern != nil && !p.IssuePattern.MatchString(message) {
+		add(RuleIssueKey, "commit_msg.issue_key", p.IssuePattern)
+	}
+	return violations
+}
+
+// checkConventional 检查标题是否符合 Conventional Commits
+func (p *CommitMsgPolicy) checkConventional(subject string, add func(rule, format string, args ...interface{})) {
+	m := conventionalPattern.FindStringSubmatch(subject)
+	if m == nil {
+		add(RuleConventional, "commit_msg.conventional")
+		return
+	}
+	commitType, scope, description := m[1], m[2], m[4]
+
+	if len(p.Types) > 0 && !containsString(p.Types, commitType) {
+		add(RuleType, "commit_msg.type", commitType, strings.Join(p.Types, ", "))
+	}
+	switch {
+	case scope == "" && p.RequireScope:
+		add(RuleScopeRequired, "commit_msg.scope_required", commitType)
+	case scope != "" && len(p.Scopes) > 0 && !containsString(p.Scopes, scope):
+		add(RuleScope, "commit_msg.scope", scope, strings.Join(p.Scopes, ", "))
+	}
+	if strings.TrimSpace(description) == "" {
+		add(RuleConventional, "commit_msg.description")
+	}
+}
+
+// findRune 查找第一个满足条件的字符，返回行号（从 1 开始）和字符
+func findRune(lines []string, match func(r rune) bool) (int, rune, bool) {
+	for i, line := range lines {
+		for _, r := range line {
+			if match(r) {
+				return i + 1, r, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
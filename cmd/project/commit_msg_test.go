@@ -0,0 +1,244 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCommitMsgPolicyCheck(t *testing.T) {
+	conventional := CommitMsgPolicy{
+		Conventional:     true,
+		Types:            []string{"feat", "fix", "chore"},
+		SubjectMaxLength: 50,
+	}
+	tests := []struct {
+		name    string
+		policy  CommitMsgPolicy
+		message string
+		want    []string // 违反的规则，按检查顺序
+	}{
+		{name: "empty", policy: conventional, message: "  \n", want: []string{RuleEmpty}},
+		{name: "valid", policy: conventional, message: "feat(login): add SSO\n\nDetails.", want: nil},
+		{name: "breaking change", policy: conventional, message: "feat!: drop v1 API", want: nil},
+		{name: "not conventional", policy: conventional, message: "Add SSO", want: []string{RuleConventional}},
+		{name: "unknown type", policy: conventional, message: "docs: update README", want: []string{RuleType}},
+		{name: "empty description", policy: conventional, message: "fix:  ", want: []string{RuleConventional}},
+		{
+			name:    "subject too long",
+			policy:  conventional,
+			message: "feat: " + "a very long subject that keeps going well past the limit",
+			want:    []string{RuleSubjectMaxLength},
+		},
+		{
+			name:    "subject length counts runes",
+			policy:  CommitMsgPolicy{SubjectMaxLength: 4},
+			message: "修复登录",
+			want:    nil,
+		},
+		{name: "subject too short", policy: CommitMsgPolicy{SubjectMinLength: 10}, message: "wip", want: []string{RuleSubjectMinLength}},
+		{name: "body without blank line", policy: CommitMsgPolicy{}, message: "fix bug\nbody", want: []string{RuleBodyLeadingBlank}},
+		{
+			name:    "scope required",
+			policy:  CommitMsgPolicy{Conventional: true, RequireScope: true},
+			message: "fix: handle nil",
+			want:    []string{RuleScopeRequired},
+		},
+		{
+			name:    "scope not allowed",
+			policy:  CommitMsgPolicy{Conventional: true, Scopes: []string{"ios", "android"}},
+			message: "fix(web): handle nil",
+			want:    []string{RuleScope},
+		},
+		{
+			name:    "forbidden cjk in body",
+			policy:  CommitMsgPolicy{ForbiddenChars: []string{"cjk"}},
+			message: "fix login\n\n修复登录问题",
+			want:    []string{RuleForbiddenChars},
+		},
+		{
+			name:    "forbidden emoji and non ascii",
+			policy:  CommitMsgPolicy{ForbiddenChars: []string{"emoji", "non_ascii"}},
+			message: "fix login 🎉",
+			want:    []string{RuleForbiddenChars, RuleForbiddenChars},
+		},
+		{
+			name:    "missing issue key",
+			policy:  CommitMsgPolicy{IssuePattern: regexp.MustCompile(`[A-Z]+-[0-9]+`)},
+			message: "fix login",
+			want:    []string{RuleIssueKey},
+		},
+		{
+			name:    "issue key in body",
+			policy:  CommitMsgPolicy{IssuePattern: regexp.MustCompile(`[A-Z]+-[0-9]+`)},
+			message: "fix login\n\nRefs APP-42",
+			want:    nil,
+		},
+		{
+			name:    "multiple violations",
+			policy:  CommitMsgPolicy{Conventional: true, Types: []string{"feat"}, ForbiddenChars: []string{"cjk"}},
+			message: "fix: 修复\nbody",
+			want:    []string{RuleType, RuleBodyLeadingBlank, RuleForbiddenChars},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range tt.policy.Check(tt.message) {
+				if v.Message == "" {
+					t.Errorf("rule %s has no message", v.Rule)
+				}
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanCommitMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		stripComments bool
+		want          string
+	}{
+		{name: "comments and blank lines", raw: "\nfix: bug  \n\n# Please enter the commit message\n", stripComments: true, want: "fix: bug"},
+		{name: "crlf", raw: "fix: bug\r\n\r\nbody\r\n", stripComments: true, want: "fix: bug\n\nbody"},
+		{name: "verbose diff", raw: "fix: bug\n" + scissorsLine + "\ndiff --git a/x b/x\n", stripComments: true, want: "fix: bug"},
+		{name: "only comments", raw: "# comment\n", stripComments: true, want: ""},
+		{name: "issue reference from -m", raw: "#123 fix crash\n", want: "#123 fix crash"},
+		{name: "hash lines in body from -m", raw: "fix: crash\n\n# Steps\n1. open\n\n", want: "fix: crash\n\n# Steps\n1. open"},
+		{name: "whitespace keeps scissors", raw: "fix: bug  \n" + scissorsLine + "\n", want: "fix: bug\n" + scissorsLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanCommitMessage(tt.raw, tt.stripComments); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitMsgStripsComments(t *testing.T) {
+	tests := []struct {
+		name      string
+		cleanup   string
+		gitEditor string // git 未打开编辑器时为钩子设置 GIT_EDITOR=:
+		want      bool
+		wantErr   bool
+	}{
+		{name: "auto with editor", cleanup: CleanupAuto, gitEditor: "vim", want: true},
+		{name: "auto without git", cleanup: CleanupAuto, want: true},
+		{name: "auto with -m", cleanup: CleanupAuto, gitEditor: ":", want: false},
+		{name: "strip", cleanup: CleanupStrip, gitEditor: ":", want: true},
+		{name: "whitespace", cleanup: CleanupWhitespace, gitEditor: "vim", want: false},
+		{name: "invalid", cleanup: "verbatim", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GIT_EDITOR", tt.gitEditor)
+			got, err := CommitMsgStripsComments(tt.cleanup)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsIgnoredCommitMsg(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{message: "fixup! feat: add SSO", want: true},
+		{message: "squash! feat: add SSO", want: true},
+		{message: "amend! feat: add SSO", want: true},
+		{message: "Merge branch 'main' into feature", want: true},
+		{message: "Merge remote-tracking branch 'origin/main'", want: true},
+		{message: "Merge pull request #42 from org/feature", want: true},
+		{message: "Merge settings from the user config", want: false},
+		{message: "Merge: combine configs", want: false},
+		{message: "feat: merge settings", want: false},
+		{message: "Merged without prefix", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := IsIgnoredCommitMsg(tt.message); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCharClasses(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "cjk,emoji"},
+		{value: "non_ascii, control"},
+		{value: "cjk,latin", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := validateCharClasses(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && ErrorCodeOf(err) != ErrConfig {
+				t.Errorf("code = %s, want %s", ErrorCodeOf(err), ErrConfig)
+			}
+		})
+	}
+}
+
+// TestDefaultCommitMsgPolicy 默认不限制标题长度，只有配置后才检查
+func TestDefaultCommitMsgPolicy(t *testing.T) {
+	subject := "fix: " + strings.Repeat("long subject ", 10)
+	tests := []struct {
+		name      string
+		maxLength string // 仓库配置的 commit_msg.subject_max_length，为空表示不配置
+		want      []string
+	}{
+		{name: "default"},
+		{name: "configured", maxLength: "72", want: []string{RuleSubjectMaxLength}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := LoadConfigFile(filepath.Join(t.TempDir(), RepoConfigFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.maxLength != "" {
+				if err := repo.Set("commit_msg.subject_max_length", tt.maxLength); err != nil {
+					t.Fatal(err)
+				}
+			}
+			policy, err := NewCommitMsgPolicy(&Settings{Repo: repo})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range policy.Check(subject) {
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
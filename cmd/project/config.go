@@ -0,0 +1,601 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"devex/cmd/i18n"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置的优先级从高到低：命令行参数 > 环境变量 > 仓库配置 .devex.yaml > 用户配置 ~/.config/devex/config.yaml > 内置默认值
+// 命令行参数由 cmd 包处理，这里负责其余各层
+
+// RepoConfigFile 仓库配置文件，位于项目根目录
+const RepoConfigFile = ".devex.yaml"
+
+// 配置项类型
+const (
+	ConfigTypeString = "string"
+	ConfigTypeBool   = "bool"
+	ConfigTypeInt    = "int"
+	ConfigTypeList   = "list" // 配置文件中写作列表，命令行和环境变量中用逗号分隔
+)
+
+// valuesConfigPrefix 模板变量默认值的配置前缀，如 values.BundleIdPrefix
+const valuesConfigPrefix = "values."
+
+// ConfigKey 配置项
+type ConfigKey struct {
+	Name     string // 点分隔的键名，对应配置文件中的嵌套结构，如 template.source
+	Env      string // 对应的环境变量
+	Type     string
+	Default  string // 内置默认值，列表用逗号分隔
+	validate func(value string) error
+}
+
+// configKeys 所有配置项
+var configKeys = []ConfigKey{
+	{Name: "path", Env: "DEVEX_PATH", Type: ConfigTypeString, Default: "."},
+	{Name: "lang", Env: "DEVEX_LANG", Type: ConfigTypeList, validate: validateLanguages},
+	{Name: "template.source", Env: TemplateSourceEnv, Type: ConfigTypeString},
+	{Name: "template.sha256", Env: TemplateChecksumEnv, Type: ConfigTypeString, validate: func(v string) error {
+		_, err := normalizeChecksum(v)
+		return err
+	}},
+	{Name: "template.dir", Env: TemplateDirEnv, Type: ConfigTypeString},
+	{Name: "output", Env: "DEVEX_OUTPUT", Type: ConfigTypeString, Default: OutputText, validate: validateOutputFormat},
+	{Name: "ui.lang", Env: "DEVEX_LANG_UI", Type: ConfigTypeString, validate: validateUILang},
+	{Name: "hooks", Env: "DEVEX_HOOKS", Type: ConfigTypeList, Default: "pre-commit,commit-msg,post-commit", validate: validateHookNames},
+	{Name: "ci.provider", Env: "DEVEX_CI", Type: ConfigTypeString, Default: CINone, validate: validateCIProvider},
+	{Name: "ci.branch", Env: "DEVEX_CI_BRANCH", Type: ConfigTypeString, validate: validateCIBranch},
+	{Name: "ci.version", Env: "DEVEX_CI_VERSION", Type: ConfigTypeString, validate: validateCIVersion},
+	{Name: "secrets.baseline", Env: "DEVEX_SECRETS_BASELINE", Type: ConfigTypeString, Default: SecretsBaselineFile},
+	{Name: "commit_msg.conventional", Env: "DEVEX_COMMIT_MSG_CONVENTIONAL", Type: ConfigTypeBool, Default: "false"},
+	{Name: "commit_msg.types", Type: ConfigTypeList, Default: "feat,fix,docs,style,refactor,perf,test,build,ci,chore,revert"},
+	{Name: "commit_msg.scopes", Type: ConfigTypeList},
+	{Name: "commit_msg.require_scope", Type: ConfigTypeBool, Default: "false"},
+	{Name: "commit_msg.subject_min_length", Type: ConfigTypeInt, Default: "0"},
+	{Name: "commit_msg.subject_max_length", Type: ConfigTypeInt, Default: "0"},
+	{Name: "commit_msg.forbidden_chars", Env: "DEVEX_COMMIT_MSG_FORBIDDEN_CHARS", Type: ConfigTypeList, Default: "cjk", validate: validateCharClasses},
+	{Name: "commit_msg.issue_pattern", Env: "DEVEX_COMMIT_MSG_ISSUE_PATTERN", Type: ConfigTypeString, validate: func(v string) error {
+		_, err := regexp.Compile(v)
+		return err
+	}},
+}
+
+// ConfigKeys 返回所有配置项
+func ConfigKeys() []ConfigKey {
+	return configKeys
+}
+
+// LookupConfigKey 查找配置项，values.<变量名> 为模板变量的默认值
+func LookupConfigKey(name string) (ConfigKey, error) {
+	for _, key := range configKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	if strings.HasPrefix(name, valuesConfigPrefix) {
+		varName := strings.TrimPrefix(name, valuesConfigPrefix)
+		if !variableNamePattern.MatchString(varName) {
+			return ConfigKey{}, Errorf(ErrUsage, "config.invalid_variable", varName)
+		}
+		return ConfigKey{Name: name, Type: ConfigTypeString}, nil
+	}
+	return ConfigKey{}, Errorf(ErrUsage, "config.unknown_key", name)
+}
+
+// Describe 配置项的说明
+func (k ConfigKey) Describe() string {
+	if strings.HasPrefix(k.Name, valuesConfigPrefix) {
+		return i18n.T("config.key.values", strings.TrimPrefix(k.Name, valuesConfigPrefix))
+	}
+	return i18n.T("config.key." + k.Name)
+}
+
+// Normalize 校验并规范化配置值
+func (k ConfigKey) Normalize(value string) (string, error) {
+	switch k.Type {
+	case ConfigTypeBool:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "yes", "1":
+			value = "true"
+		case "false", "no", "0":
+			value = "false"
+		default:
+			return "", Errorf(ErrConfig, "config.not_bool", k.Name, value)
+		}
+	case ConfigTypeInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return "", Errorf(ErrConfig, "config.not_int", k.Name, value)
+		}
+		value = strconv.Itoa(n)
+	case ConfigTypeList:
+		value = strings.Join(splitList(value), ",")
+	}
+	if k.validate != nil && value != "" {
+		if err := k.validate(value); err != nil {
+			return "", Errorf(ErrConfig, "config.invalid_value", k.Name, err)
+		}
+	}
+	return value, nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateLanguages 校验语言列表
+func validateLanguages(value string) error {
+	for _, lang := range splitList(value) {
+		if _, err := GetLanguageConfig(lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOutputFormat 校验输出格式
+func validateOutputFormat(value string) error {
+	if value != OutputText && value != OutputJSON {
+		return i18n.Errorf("reporter.unsupported_format", value, OutputText, OutputJSON)
+	}
+	return nil
+}
+
+// validateUILang 校验界面语言
+func validateUILang(value string) error {
+	if _, ok := i18n.Normalize(value); !ok {
+		return i18n.Errorf("i18n.unsupported", value, strings.Join(i18n.Locales(), ", "))
+	}
+	return nil
+}
+
+// validateHookNames 校验钩子名称
+func validateHookNames(value string) error {
+	for _, name := range splitList(value) {
+		if _, ok := findGitHook(name); !ok {
+			var names []string
+			for _, hook := range gitHooks {
+				names = append(names, hook.Name)
+			}
+			return i18n.Errorf("config.unsupported_hook", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// ConfigFile 配置文件，以 YAML 节点保存以便修改时保留注释和原有写法
+type ConfigFile struct {
+	Path string
+	root *yaml.Node // 顶层映射节点
+}
+
+// LoadConfigFile 读取配置文件，文件不存在时返回空配置
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	config := &ConfigFile{Path: path, root: &yaml.Node{Kind: yaml.MappingNode}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("config.read_failed", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, Errorf(ErrConfig, "config.parse_failed", path, err)
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, Errorf(ErrConfig, "config.not_mapping", path)
+		}
+		config.root = doc.Content[0]
+	}
+	return config, nil
+}
+
+// UserConfigPath 用户配置文件路径：$XDG_CONFIG_HOME/devex/config.yaml，默认 ~/.config/devex/config.yaml
+func UserConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", i18n.Errorf("config.no_home", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "devex", "config.yaml"), nil
+}
+
+// LoadUserConfig 读取用户配置文件
+func LoadUserConfig() (*ConfigFile, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadConfigFile(path)
+}
+
+// RepoConfigPath 查找仓库配置文件：从 dir 向上查找 .devex.yaml，到 Git 仓库根目录为止
+// 未找到时返回仓库根目录（不在仓库中时为 dir）下的 .devex.yaml，found 为 false
+func RepoConfigPath(dir string) (path string, found bool, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", false, err
+	}
+
+	start := dir
+	for {
+		candidate := filepath.Join(dir, RepoConfigFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return candidate, false, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Join(start, RepoConfigFile), false, nil
+		}
+		dir = parent
+	}
+}
+
+// Get 获取配置值，列表以逗号连接
+func (c *ConfigFile) Get(name string) (string, bool) {
+	node := c.lookup(name)
+	if node == nil {
+		return "", false
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", false
+		}
+		return node.Value, true
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), true
+	}
+	return "", false
+}
+
+// Set 设置配置值，值会按配置项类型校验
+func (c *ConfigFile) Set(name, value string) error {
+	key, err := LookupConfigKey(name)
+	if err != nil {
+		return err
+	}
+	if value, err = key.Normalize(value); err != nil {
+		return err
+	}
+
+	var node *yaml.Node
+	switch key.Type {
+	case ConfigTypeBool:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}
+	case ConfigTypeInt:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}
+	case ConfigTypeList:
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range splitList(value) {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+	default:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+
+	parts := strings.Split(name, ".")
+	parent := c.root
+	for _, part := range parts[:len(parts)-1] {
+		child := mappingGet(parent, part)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			mappingSet(parent, part, child)
+		}
+		parent = child
+	}
+	mappingSet(parent, parts[len(parts)-1], node)
+	return nil
+}
+
+// Unset 删除配置值，返回配置项是否存在
+func (c *ConfigFile) Unset(name string) bool {
+	parts := strings.Split(name, ".")
+	parent := c.root
+	for _, part := range parts[:len(parts)-1] {
+		if parent = mappingGet(parent, part); parent == nil || parent.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return mappingDelete(parent, parts[len(parts)-1])
+}
+
+// Values 模板变量的默认值（values 下的所有键）
+func (c *ConfigFile) Values() map[string]string {
+	values := make(map[string]string)
+	node := mappingGet(c.root, strings.TrimSuffix(valuesConfigPrefix, "."))
+	if node == nil || node.Kind != yaml.MappingNode {
+		return values
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode && value.Tag != "!!null" {
+			values[node.Content[i].Value] = value.Value
+		}
+	}
+	return values
+}
+
+// Templates 注册的模板源
+func (c *ConfigFile) Templates() (map[string]TemplateRegistration, error) {
+	templates := make(map[string]TemplateRegistration)
+	if node := mappingGet(c.root, "templates"); node != nil {
+		if err := node.Decode(&templates); err != nil {
+			return nil, Errorf(ErrConfig, "config.bad_templates", c.Path, err)
+		}
+	}
+	return templates, nil
+}
+
+// SetTemplate 注册模板源
+func (c *ConfigFile) SetTemplate(name string, registration TemplateRegistration) error {
+	templates := mappingGet(c.root, "templates")
+	if templates == nil || templates.Kind != yaml.MappingNode {
+		templates = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		mappingSet(c.root, "templates", templates)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(registration); err != nil {
+		return err
+	}
+	mappingSet(templates, name, &node)
+	return nil
+}
+
+// DeleteTemplate 删除模板源，返回模板源是否存在
+func (c *ConfigFile) DeleteTemplate(name string) bool {
+	templates := mappingGet(c.root, "templates")
+	if templates == nil || templates.Kind != yaml.MappingNode {
+		return false
+	}
+	return mappingDelete(templates, name)
+}
+
+// Save 写入配置文件
+func (c *ConfigFile) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{c.root}}); err != nil {
+		return i18n.Errorf("config.serialize_failed", err)
+	}
+	data := buf.Bytes()
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return Errorf(ErrIO, "config.mkdir_failed", err)
+	}
+	if err := os.WriteFile(c.Path, data, 0644); err != nil {
+		return Errorf(ErrIO, "config.write_failed", err)
+	}
+	return nil
+}
+
+// lookup 按点分隔的键名查找节点
+func (c *ConfigFile) lookup(name string) *yaml.Node {
+	node := c.root
+	for _, part := range strings.Split(name, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		if node = mappingGet(node, part); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// mappingGet 获取映射节点中的值
+func mappingGet(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingSet 设置映射节点中的值，已存在时原位替换
+func mappingSet(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// mappingDelete 删除映射节点中的键
+func mappingDelete(mapping *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Settings 合并后的配置（不含命令行参数）
+type Settings struct {
+	User *ConfigFile // 用户配置
+	Repo *ConfigFile // 仓库配置，文件不存在时为空配置
+}
+
+// 配置来源
+const (
+	OriginEnv     = "env"
+	OriginRepo    = "repo"
+	OriginUser    = "user"
+	OriginBuiltin = "builtin"
+)
+
+// settings 当前使用的配置，由 LoadSettings 加载
+var settings = &Settings{}
+
+// LoadSettings 加载用户配置和 dir 所在仓库的配置，作为当前使用的配置
+func LoadSettings(dir string) (*Settings, error) {
+	s, err := ReadSettings(dir)
+	if err != nil {
+		return nil, err
+	}
+	settings = s
+	return settings, nil
+}
+
+// ReadSettings 读取用户配置和 dir 所在仓库的配置，不改变当前使用的配置（批量执行时各仓库的配置）
+func ReadSettings(dir string) (*Settings, error) {
+	user, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	repoPath, _, err := RepoConfigPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := LoadConfigFile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Settings{User: user, Repo: repo}, nil
+}
+
+// CurrentSettings 当前使用的配置
+func CurrentSettings() *Settings {
+	return settings
+}
+
+// Lookup 按 环境变量 > 仓库配置 > 用户配置 > 内置默认值 获取配置值，返回值及其来源
+// 配置文件中的值无效时返回错误，避免悄悄使用默认值
+func (s *Settings) Lookup(name string) (string, string, error) {
+	key, err := LookupConfigKey(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if key.Env != "" {
+		if value, ok := os.LookupEnv(key.Env); ok && value != "" {
+			normalized, err := key.Normalize(value)
+			if err != nil {
+				return "", "", Errorf(ErrConfig, "config.env_invalid", key.Env, err)
+			}
+			return normalized, OriginEnv, nil
+		}
+	}
+
+	layers := []struct {
+		file   *ConfigFile
+		origin string
+	}{{s.Repo, OriginRepo}, {s.User, OriginUser}}
+	for _, layer := range layers {
+		if layer.file == nil {
+			continue
+		}
+		if value, ok := layer.file.Get(name); ok {
+			normalized, err := key.Normalize(value)
+			if err != nil {
+				return "", "", Errorf(ErrConfig, "errors.context", layer.file.Path, err)
+			}
+			return normalized, layer.origin, nil
+		}
+	}
+	return key.Default, OriginBuiltin, nil
+}
+
+// Get 获取配置值，配置无效时使用内置默认值
+func (s *Settings) Get(name string) string {
+	if value, _, err := s.Lookup(name); err == nil {
+		return value
+	}
+	key, _ := LookupConfigKey(name)
+	return key.Default
+}
+
+// Bool 获取布尔配置值
+func (s *Settings) Bool(name string) bool {
+	return s.Get(name) == "true"
+}
+
+// Int 获取整数配置值
+func (s *Settings) Int(name string) int {
+	n, _ := strconv.Atoi(s.Get(name))
+	return n
+}
+
+// List 获取列表配置值
+func (s *Settings) List(name string) []string {
+	return splitList(s.Get(name))
+}
+
+// Values 模板变量的默认值，仓库配置覆盖用户配置
+func (s *Settings) Values() map[string]string {
+	values := make(map[string]string)
+	for _, file := range []*ConfigFile{s.User, s.Repo} {
+		if file == nil {
+			continue
+		}
+		for k, v := range file.Values() {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// Templates 注册的模板源，仓库配置覆盖用户配置中的同名模板源
+func (s *Settings) Templates() (map[string]TemplateRegistration, error) {
+	templates := make(map[string]TemplateRegistration)
+	for _, file := range []*ConfigFile{s.User, s.Repo} {
+		if file == nil {
+			continue
+		}
+		fileTemplates, err := file.Templates()
+		if err != nil {
+			return nil, err
+		}
+		for name, registration := range fileTemplates {
+			templates[name] = registration
+		}
+	}
+	return templates, nil
+}
+
+// ValueKeys 配置文件中出现的模板变量配置项（values.<变量名>），按名称排序
+func (s *Settings) ValueKeys() []string {
+	var keys []string
+	for name := range s.Values() {
+		keys = append(keys, valuesConfigPrefix+name)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfigFile 写入配置文件并读取
+func writeConfigFile(t *testing.T, path, content string) *ConfigFile {
+	t.Helper()
+	writeTestFile(t, path, content)
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestSettingsLookupPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfigFile(t, filepath.Join(dir, "user.yaml"), "ci:\n  branch: user-branch\n  provider: github\nhooks: [commit-msg]\n")
+	repo := writeConfigFile(t, filepath.Join(dir, "repo.yaml"), "ci:\n  branch: repo-branch\nhooks:\n  - pre-commit\n  - commit-msg\n")
+	empty := writeConfigFile(t, filepath.Join(dir, "missing.yaml"), "")
+
+	tests := []struct {
+		name       string
+		settings   *Settings
+		key        string
+		env        string // 对应环境变量的值，为空表示不设置
+		want       string
+		wantOrigin string
+	}{
+		{name: "env over repo", settings: &Settings{User: user, Repo: repo}, key: "ci.branch", env: "env-branch", want: "env-branch", wantOrigin: OriginEnv},
+		{name: "repo over user", settings: &Settings{User: user, Repo: repo}, key: "ci.branch", want: "repo-branch", wantOrigin: OriginRepo},
+		{name: "user when repo unset", settings: &Settings{User: user, Repo: repo}, key: "ci.provider", want: CIGitHub, wantOrigin: OriginUser},
+		{name: "user without repo file", settings: &Settings{User: user, Repo: empty}, key: "ci.branch", want: "user-branch", wantOrigin: OriginUser},
+		{name: "builtin default", settings: &Settings{User: empty, Repo: empty}, key: "ci.provider", want: CINone, wantOrigin: OriginBuiltin},
+		{name: "nil layers", settings: &Settings{}, key: "secrets.baseline", want: SecretsBaselineFile, wantOrigin: OriginBuiltin},
+		{name: "list from repo", settings: &Settings{User: user, Repo: repo}, key: "hooks", want: "pre-commit,commit-msg", wantOrigin: OriginRepo},
+		{name: "list from env", settings: &Settings{User: user, Repo: repo}, key: "hooks", env: " post-commit , pre-commit ", want: "post-commit,pre-commit", wantOrigin: OriginEnv},
+		{name: "empty env is ignored", settings: &Settings{User: user, Repo: repo}, key: "hooks", env: "", want: "pre-commit,commit-msg", wantOrigin: OriginRepo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LookupConfigKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(key.Env, tt.env)
+
+			got, origin, err := tt.settings.Lookup(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || origin != tt.wantOrigin {
+				t.Errorf("Lookup(%s) = %q from %s, want %q from %s", tt.key, got, origin, tt.want, tt.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestSettingsLookupInvalid(t *testing.T) {
+	dir := t.TempDir()
+	repo := writeConfigFile(t, filepath.Join(dir, "repo.yaml"), "hooks: [pre-push]\n")
+	settings := &Settings{Repo: repo}
+
+	t.Setenv("DEVEX_HOOKS", "")
+	if _, _, err := settings.Lookup("hooks"); err == nil {
+		t.Error("expected an error for an invalid repo value")
+	}
+	// Get 在配置无效时退回内置默认值
+	if got := settings.Get("hooks"); got != "pre-commit,commit-msg,post-commit" {
+		t.Errorf("Get = %q", got)
+	}
+
+	t.Setenv("DEVEX_CI", "gitlab")
+	if _, _, err := settings.Lookup("ci.provider"); err == nil {
+		t.Error("expected an error for an invalid env value")
+	}
+}
+
+// TestReadSettings 从用户配置目录和仓库根目录读取配置，仓库配置可以在上级目录中
+func TestReadSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("DEVEX_CI_BRANCH", "")
+	writeTestFile(t, filepath.Join(home, "devex", "config.yaml"), "ci:\n  branch: user-branch\n  provider: github\n")
+
+	repo := t.TempDir()
+	mkdir(t, filepath.Join(repo, ".git"))
+	writeTestFile(t, filepath.Join(repo, RepoConfigFile), "ci:\n  branch: repo-branch\n")
+	sub := filepath.Join(repo, "apps", "ios")
+	mkdir(t, sub)
+
+	settings, err := ReadSettings(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, origin, _ := settings.Lookup("ci.branch"); got != "repo-branch" || origin != OriginRepo {
+		t.Errorf("ci.branch = %q from %s", got, origin)
+	}
+	if got, origin, _ := settings.Lookup("ci.provider"); got != CIGitHub || origin != OriginUser {
+		t.Errorf("ci.provider = %q from %s", got, origin)
+	}
+	if _, err := os.Stat(settings.Repo.Path); err != nil {
+		t.Errorf("repo config path %s: %v", settings.Repo.Path, err)
+	}
+}
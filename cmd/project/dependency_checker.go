@@ -1,9 +1,10 @@
 package project
 
 import (
-	"fmt"
 	"os/exec"
 	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // DependencyChecker 依赖检查器接口
@@ -25,7 +26,7 @@ func NewCommandDependencyChecker() *CommandDependencyChecker {
 func (c *CommandDependencyChecker) CheckDependencies(dependencies []string) error {
 	missing := c.GetMissingDependencies(dependencies)
 	if len(missing) > 0 {
-		return fmt.Errorf("缺少必需的命令行工具: %s", strings.Join(missing, ", "))
+		return i18n.Errorf("dependency_checker.missing", strings.Join(missing, ", "))
 	}
 	return nil
 }
@@ -34,7 +35,7 @@ func (c *CommandDependencyChecker) CheckDependencies(dependencies []string) erro
 func (c *CommandDependencyChecker) CheckSingleDependency(command string) error {
 	_, err := exec.LookPath(command)
 	if err != nil {
-		return fmt.Errorf("未找到命令: %s", command)
+		return i18n.Errorf("dependency_checker.not_found", command)
 	}
 	return nil
 }
@@ -50,6 +51,37 @@ func (c *CommandDependencyChecker) GetMissingDependencies(dependencies []string)
 	return missing
 }
 
+// versionArgs 获取版本号所需的参数，未列出的命令使用 --version
+var versionArgs = map[string][]string{
+	"gitleaks": {"version"},
+	"devex":    {"version"},
+	"java":     {"-version"},
+	"go":       {"version"},
+}
+
+// GetVersion 获取命令的版本信息（输出的第一行非空内容）
+func (c *CommandDependencyChecker) GetVersion(command string) (string, error) {
+	if err := c.CheckSingleDependency(command); err != nil {
+		return "", err
+	}
+
+	args, ok := versionArgs[command]
+	if !ok {
+		args = []string{"--version"}
+	}
+	// 部分工具（如 java）把版本信息输出到 stderr
+	out, err := exec.Command(command, args...).CombinedOutput()
+	if err != nil {
+		return "", i18n.Errorf("dependency_checker.version_failed", command, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+	return "", i18n.Errorf("dependency_checker.version_empty", command)
+}
+
 // SwiftDependencyHelper Swift特定的依赖检查帮助器
 type SwiftDependencyHelper struct {
 	checker DependencyChecker
@@ -71,35 +103,25 @@ func (h *SwiftDependencyHelper) CheckAndInstallXcodegen() error {
 
 	// 未安装，检查是否有 Homebrew
 	if err := h.checker.CheckSingleDependency("brew"); err != nil {
-		return fmt.Errorf("未找到 Homebrew，请先安装 Homebrew：\n/bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"")
+		return i18n.Errorf("dependency_checker.no_brew")
 	}
 
-	fmt.Println("正在安装 xcodegen...")
+	defaultReporter.Info(i18n.T("dependency_checker.installing_xcodegen"))
 
 	// 执行安装命令
 	cmd := exec.Command("brew", "install", "xcodegen")
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("安装 xcodegen 失败：%w", err)
+		return i18n.Errorf("dependency_checker.xcodegen_failed", err)
 	}
 
-	fmt.Println("xcodegen 安装成功！")
+	defaultReporter.Info(i18n.T("dependency_checker.xcodegen_installed"))
 	return nil
 }
 
 // GetInstallationInstructions 获取依赖安装说明
 func GetInstallationInstructions(command string) string {
-	instructions := map[string]string{
-		"xcodegen": "安装说明：brew install xcodegen",
-		"pod":      "安装说明：sudo gem install cocoapods",
-		"brew":     "安装说明：/bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"",
-		"go":       "安装说明：https://golang.org/dl/",
-		"java":     "安装说明：https://adoptopenjdk.net/",
-		"gradle":   "安装说明：https://gradle.org/install/",
-		"mvn":      "安装说明：https://maven.apache.org/install.html",
-	}
-
-	if instruction, exists := instructions[command]; exists {
-		return instruction
+	if key := "dependency_checker.install." + command; i18n.Has(key) {
+		return i18n.T(key)
 	}
-	return fmt.Sprintf("请手动安装 %s", command)
+	return i18n.T("dependency_checker.install_manually", command)
 }
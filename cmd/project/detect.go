@@ -0,0 +1,100 @@
+package project
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// detectMaxDepth 标记文件所在的最大目录深度，项目根目录为 0，如 apps/ios/Podfile 深度为 2
+const detectMaxDepth = 2
+
+// languageMarker 语言标记：匹配 Pattern 的文件或目录表示项目使用了 Language
+type languageMarker struct {
+	Pattern  string
+	Language string
+}
+
+// languageMarkers 用于检测项目语言的标记，Language 不一定有对应的 LanguageConfig
+var languageMarkers = []languageMarker{
+	{"*.xcodeproj", "swift"},
+	{"Package.swift", "swift"},
+	{"Podfile", "swift"},
+	{"build.gradle", "kotlin"},
+	{"build.gradle.kts", "kotlin"},
+	{"go.mod", "go"},
+	{"package.json", "javascript"},
+	{"pyproject.toml", "python"},
+}
+
+// detectSkipDirs 检测时跳过的依赖和构建目录
+var detectSkipDirs = map[string]bool{
+	"node_modules": true,
+	"Pods":         true,
+	"Carthage":     true,
+	"vendor":       true,
+	"build":        true,
+	"DerivedData":  true,
+}
+
+// DetectedLanguage 检测到的项目语言
+type DetectedLanguage struct {
+	Language  string
+	Markers   []string // 命中的标记文件，相对于项目目录
+	Supported bool     // 是否有对应的语言配置
+}
+
+// DetectLanguages 扫描项目目录中的标记文件，返回检测到的语言，按语言名称排序
+func DetectLanguages(projectPath string) ([]DetectedLanguage, error) {
+	return detectLanguages(os.DirFS(projectPath))
+}
+
+// detectLanguages 扫描 fsys 中的标记文件，路径相对于 fsys 的根目录
+func detectLanguages(fsys fs.FS) ([]DetectedLanguage, error) {
+	found := make(map[string][]string)
+
+	err := fs.WalkDir(fsys, ".", func(rel string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 项目目录本身不可读时无法检测；其中不可读的目录和文件跳过，不影响其他目录的检测
+			if rel == "." {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if rel == "." {
+			return nil
+		}
+
+		if d.IsDir() && (strings.HasPrefix(d.Name(), ".") || detectSkipDirs[d.Name()]) {
+			return fs.SkipDir
+		}
+
+		for _, marker := range languageMarkers {
+			if ok, _ := path.Match(marker.Pattern, d.Name()); ok {
+				found[marker.Language] = append(found[marker.Language], rel)
+			}
+		}
+
+		// .xcodeproj 等标记本身是目录，命中后不再深入
+		if d.IsDir() && (strings.HasSuffix(d.Name(), ".xcodeproj") || strings.Count(rel, "/") >= detectMaxDepth) {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var detected []DetectedLanguage
+	for lang, markers := range found {
+		_, err := GetLanguageConfig(lang)
+		detected = append(detected, DetectedLanguage{Language: lang, Markers: markers, Supported: err == nil})
+	}
+	sort.Slice(detected, func(i, j int) bool { return detected[i].Language < detected[j].Language })
+	return detected, nil
+}
@@ -0,0 +1,105 @@
+package project
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// brokenDirFS 读取指定目录时返回错误，模拟没有权限的目录
+type brokenDirFS struct {
+	fstest.MapFS
+	broken string
+}
+
+func (f brokenDirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == f.broken {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+func TestDetectLanguages(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("x")}
+	dir := &fstest.MapFile{Mode: fs.ModeDir | 0755}
+	tests := []struct {
+		name string
+		fsys fs.FS
+		want map[string][]string // 语言到标记文件
+	}{
+		{
+			name: "root markers",
+			fsys: fstest.MapFS{"Podfile": file, "go.mod": file, "README.md": file},
+			want: map[string][]string{"swift": {"Podfile"}, "go": {"go.mod"}},
+		},
+		{
+			name: "nested up to max depth",
+			fsys: fstest.MapFS{
+				"apps/ios/Demo.xcodeproj/project.pbxproj": file,
+				"apps/android/build.gradle.kts":           file,
+				"a/b/c/package.json":                      file,
+			},
+			want: map[string][]string{"swift": {"apps/ios/Demo.xcodeproj"}, "kotlin": {"apps/android/build.gradle.kts"}},
+		},
+		{
+			name: "skips hidden and dependency directories",
+			fsys: fstest.MapFS{
+				".git/go.mod":                   file,
+				"node_modules/pkg/package.json": file,
+				"Pods/Podfile":                  file,
+				"pyproject.toml":                file,
+			},
+			want: map[string][]string{"python": {"pyproject.toml"}},
+		},
+		{
+			name: "unreadable directory is skipped",
+			fsys: brokenDirFS{
+				MapFS: fstest.MapFS{
+					"secret":               dir,
+					"ios/Podfile":          file,
+					"android/build.gradle": file,
+				},
+				broken: "secret",
+			},
+			want: map[string][]string{"swift": {"ios/Podfile"}, "kotlin": {"android/build.gradle"}},
+		},
+		{name: "empty", fsys: fstest.MapFS{}, want: map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected, err := detectLanguages(tt.fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string][]string)
+			for _, lang := range detected {
+				got[lang.Language] = lang.Markers
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguagesSupported(t *testing.T) {
+	detected, err := detectLanguages(fstest.MapFS{"Podfile": {}, "go.mod": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detected) != 2 || detected[0].Language != "go" || detected[1].Language != "swift" {
+		t.Fatalf("unexpected result: %+v", detected)
+	}
+	if detected[0].Supported || !detected[1].Supported {
+		t.Errorf("unexpected support flags: %+v", detected)
+	}
+}
+
+func TestDetectLanguagesUnreadableRoot(t *testing.T) {
+	_, err := DetectLanguages(t.TempDir() + "/missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("err = %v, want not exist", err)
+	}
+}
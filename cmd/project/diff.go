@@ -0,0 +1,228 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines 统一差异格式中每个变更块保留的上下文行数
+const diffContextLines = 3
+
+// diffOp 行级差异操作
+type diffOp struct {
+	kind byte // ' ' 相同，'-' 删除，'+' 新增
+	line string
+}
+
+// splitLines 按行拆分文本，保留最后一行没有换行符的情况
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 基于最长公共子序列计算两组行之间的差异
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// unifiedDiff 生成统一差异格式（unified diff）文本，内容相同时返回空字符串
+func unifiedDiff(oldName, newName, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		// 找到下一处变更
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// 变更块向前包含上下文，向后合并相距不超过两倍上下文的变更
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := start
+		for hunkEnd < len(ops) {
+			if ops[hunkEnd].kind != ' ' {
+				hunkEnd++
+				continue
+			}
+			next := hunkEnd
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-hunkEnd > 2*diffContextLines {
+				break
+			}
+			hunkEnd = next
+		}
+		hunkEnd += diffContextLines
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		// 计算变更块在新旧文件中的起始行号
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
+
+// matchLines 基于最长公共子序列，返回 base 每一行在 other 中对应的行号，未匹配为 -1
+func matchLines(base, other []string) []int {
+	match := make([]int, len(base))
+	i, j := 0, 0
+	for _, op := range diffLines(base, other) {
+		switch op.kind {
+		case ' ':
+			match[i] = j
+			i++
+			j++
+		case '-':
+			match[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return match
+}
+
+// merge3 按行三方合并：以 base 为共同祖先，合并 ours 与 theirs 各自的修改
+// 双方修改了同一区域且结果不同时返回 false
+func merge3(base, ours, theirs string) (string, bool) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matchLines(b, o), matchLines(b, t)
+
+	var sb strings.Builder
+	bi, oi, ti := 0, 0, 0
+	for {
+		// 找到下一处在三方中都未改动的行，作为稳定锚点
+		next := bi
+		for next < len(b) && (mo[next] < 0 || mt[next] < 0) {
+			next++
+		}
+		oEnd, tEnd := len(o), len(t)
+		if next < len(b) {
+			oEnd, tEnd = mo[next], mt[next]
+		}
+
+		// 合并锚点之前的不稳定区域
+		baseChunk, oursChunk, theirsChunk := b[bi:next], o[oi:oEnd], t[ti:tEnd]
+		switch {
+		case equalLines(oursChunk, theirsChunk), equalLines(baseChunk, theirsChunk):
+			sb.WriteString(strings.Join(oursChunk, ""))
+		case equalLines(baseChunk, oursChunk):
+			sb.WriteString(strings.Join(theirsChunk, ""))
+		default:
+			return "", false
+		}
+
+		if next == len(b) {
+			break
+		}
+		sb.WriteString(b[next])
+		bi, oi, ti = next+1, oEnd+1, tEnd+1
+	}
+	return sb.String(), true
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
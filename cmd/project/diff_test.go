@@ -0,0 +1,91 @@
+package project
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{name: "unchanged", old: "a\nb\n", new: "a\nb\n", want: ""},
+		{
+			name: "create from empty",
+			old:  "",
+			new:  "a\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "change in the middle keeps context",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "insert at start",
+			old:  "a\nb\n",
+			new:  "x\na\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n+x\n a\n b\n",
+		},
+		{
+			name: "distant changes make separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "missing newline at end of file",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a/f", "b/f", tt.old, tt.new); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name         string
+		ours, theirs string
+		want         string
+		wantConflict bool
+	}{
+		{name: "no changes", ours: base, theirs: base, want: base},
+		{name: "only ours", ours: "a\nB\nc\nd\ne\n", theirs: base, want: "a\nB\nc\nd\ne\n"},
+		{name: "only theirs", ours: base, theirs: "a\nb\nc\nD\ne\n", want: "a\nb\nc\nD\ne\n"},
+		{name: "separate lines", ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nc\nD\ne\n", want: "a\nB\nc\nD\ne\n"},
+		{name: "same change on both sides", ours: "a\nB\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n", want: "a\nB\nc\nd\ne\n"},
+		{name: "insert at start and end", ours: "start\n" + base, theirs: base + "end\n", want: "start\n" + base + "end\n"},
+		{name: "theirs inserts at start", ours: "a\nB\nc\nd\ne\n", theirs: "start\n" + base, want: "start\na\nB\nc\nd\ne\n"},
+		{name: "ours appends at end", ours: base + "mine\n", theirs: "a\nb\nC\nd\ne\n", want: "a\nb\nC\nd\ne\nmine\n"},
+		{name: "deletion and edit elsewhere", ours: "a\nc\nd\ne\n", theirs: "a\nb\nc\nd\nE\n", want: "a\nc\nd\nE\n"},
+		{name: "conflicting edits", ours: "a\nB\nc\nd\ne\n", theirs: "a\nbee\nc\nd\ne\n", wantConflict: true},
+		{name: "conflicting inserts at start", ours: "mine\n" + base, theirs: "theirs\n" + base, wantConflict: true},
+		{name: "conflicting appends at end", ours: base + "mine\n", theirs: base + "theirs\n", wantConflict: true},
+		{name: "edit against deletion", ours: "a\nB\nc\nd\ne\n", theirs: "a\nc\nd\ne\n", wantConflict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := merge3(base, tt.ours, tt.theirs)
+			if tt.wantConflict {
+				if ok {
+					t.Fatalf("expected a conflict, got\n%s", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("unexpected conflict")
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package project
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"devex/cmd/gitleaks"
+	"devex/cmd/i18n"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CheckStatus 诊断结果状态
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// CheckResult 单项诊断结果
+type CheckResult struct {
+	Category string      `json:"category"`
+	Name     string      `json:"name"`
+	Status   CheckStatus `json:"status"`
+	Detail   string      `json:"detail"`
+}
+
+// coreCommands 钩子运行必需的命令行工具，commit-msg 钩子通过 PATH 中的 devex 检查提交信息
+var coreCommands = []string{"git", "devex"}
+
+// optionalCommands 缺失时只警告的工具：没有 gitleaks 时使用 devex 内置扫描器，
+// 没有 pre-commit 时钩子跳过 .pre-commit-config.yaml 中的检查
+var optionalCommands = []string{"pre-commit", "gitleaks"}
+
+// Doctor 诊断项目的 devex 配置
+type Doctor struct {
+	ProjectPath string
+	Languages   []string // 需要检查工具链的语言，为空时使用 .devex.lock 中记录的语言，没有清单时检查所有支持的语言（缺失仅警告）
+	checker     *CommandDependencyChecker
+	manifest    *Manifest // 项目的 .devex.lock，不存在时为空
+	results     []CheckResult
+}
+
+// NewDoctor 创建诊断器
+func NewDoctor(projectPath string, languages []string) *Doctor {
+	return &Doctor{
+		ProjectPath: projectPath,
+		Languages:   languages,
+		checker:     NewCommandDependencyChecker(),
+	}
+}
+
+// Run 执行所有诊断，返回诊断结果
+func (d *Doctor) Run() ([]CheckResult, error) {
+	d.results = nil
+	d.manifest = nil
+	if manifest, err := LoadManifest(d.ProjectPath); err == nil {
+		d.manifest = manifest
+	}
+
+	d.checkCommands()
+	if err := d.checkLanguageCommands(); err != nil {
+		return nil, err
+	}
+	d.checkHooks()
+	d.checkGitleaksConfig()
+	if err := d.checkTemplateDrift(); err != nil {
+		return nil, err
+	}
+
+	return d.results, nil
+}
+
+// HasFailures 判断诊断结果中是否有失败项
+func HasFailures(results []CheckResult) bool {
+	for _, result := range results {
+		if result.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Doctor) add(category, name string, status CheckStatus, detail string) {
+	d.results = append(d.results, CheckResult{Category: category, Name: name, Status: status, Detail: detail})
+}
+
+// checkCommands 检查钩子必需的工具及版本
+func (d *Doctor) checkCommands() {
+	for _, command := range coreCommands {
+		d.checkCommand(i18n.T("doctor.category.tools"), command, CheckFail)
+	}
+	for _, command := range optionalCommands {
+		d.checkCommand(i18n.T("doctor.category.tools"), command, CheckWarn)
+	}
+}
+
+// checkLanguageCommands 检查语言相关的工具
+// 未指定语言时检查 .devex.lock 中记录的语言配置层对应的语言，没有清单时检查所有支持的语言且缺失只警告
+func (d *Doctor) checkLanguageCommands() error {
+	languages := d.Languages
+	missingStatus := CheckFail
+	if len(languages) == 0 && d.manifest != nil {
+		languages = layerLanguages(d.manifest.Layers)
+	} else if len(languages) == 0 {
+		languages = GetSupportedLanguages()
+		missingStatus = CheckWarn
+	}
+
+	for _, lang := range languages {
+		config, err := GetLanguageConfig(lang)
+		if err != nil {
+			return err
+		}
+		for _, command := range config.RequiredCommands {
+			d.checkCommand(config.DisplayName, command, missingStatus)
+		}
+	}
+	return nil
+}
+
+// layerLanguages 语言配置层对应的语言，按支持的语言顺序
+func layerLanguages(layers []string) []string {
+	applied := make(map[string]bool)
+	for _, layer := range layers {
+		applied[layer] = true
+	}
+	var languages []string
+	for _, lang := range GetSupportedLanguages() {
+		if config, err := GetLanguageConfig(lang); err == nil && applied[config.ConfigPath] {
+			languages = append(languages, lang)
+		}
+	}
+	return languages
+}
+
+func (d *Doctor) checkCommand(category, command string, missingStatus CheckStatus) {
+	if err := d.checker.CheckSingleDependency(command); err != nil {
+		d.add(category, command, missingStatus, i18n.T("doctor.not_installed", GetInstallationInstructions(command)))
+		return
+	}
+
+	version, err := d.checker.GetVersion(command)
+	if err != nil {
+		d.add(category, command, CheckWarn, i18n.T("doctor.no_version"))
+		return
+	}
+	d.add(category, command, CheckPass, version)
+}
+
+// checkHooks 检查 Git 钩子是否存在、可执行且与当前版本生成的内容一致
+func (d *Doctor) checkHooks() {
+	hooksDir, err := resolveHooksDir(d.ProjectPath)
+	if err != nil {
+		d.add(i18n.T("doctor.category.hooks"), i18n.T("doctor.hooks_dir"), CheckFail, err.Error())
+		return
+	}
+
+	repoSettings, err := ReadSettings(d.ProjectPath)
+	if err != nil {
+		d.add(i18n.T("doctor.category.hooks"), RepoConfigFile, CheckFail, err.Error())
+		return
+	}
+	for _, hook := range enabledGitHooks(repoSettings) {
+		if hook.Name != "pre-commit" && hook.Name != "commit-msg" {
+			continue
+		}
+
+		hookPath := filepath.Join(hooksDir, hook.Name)
+		info, err := os.Stat(hookPath)
+		if err != nil {
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckFail, i18n.T("doctor.hook_missing"))
+			continue
+		}
+		if info.Mode().Perm()&0111 == 0 {
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckFail, i18n.T("doctor.hook_not_executable", hookPath))
+			continue
+		}
+
+		content, err := os.ReadFile(hookPath)
+		if err != nil {
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckFail, err.Error())
+			continue
+		}
+		switch {
+		case bytes.Equal(content, renderHook(hook)):
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckPass, hookPath)
+		case isDevexHook(content):
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckWarn, i18n.T("doctor.hook_outdated"))
+		default:
+			d.add(i18n.T("doctor.category.hooks"), hook.Name, CheckWarn, i18n.T("doctor.hook_foreign"))
+		}
+	}
+}
+
+// checkGitleaksConfig 检查 .gitleaks.toml 能否解析
+func (d *Doctor) checkGitleaksConfig() {
+	configPath := filepath.Join(d.ProjectPath, ".gitleaks.toml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckFail, i18n.T("doctor.file_missing"))
+		return
+	}
+
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckFail, i18n.T("doctor.parse_failed", err))
+		return
+	}
+	cfg, err := gitleaks.Parse(data)
+	if err != nil {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckWarn, err.Error())
+		return
+	}
+	if _, err := gitleaks.NewDetector(cfg); err != nil {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckWarn, i18n.T("doctor.builtin_unusable", err))
+		return
+	}
+	if keys := cfg.UnknownKeys(); len(keys) > 0 {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckWarn, i18n.T("doctor.unknown_keys", strings.Join(keys, ", ")))
+	} else {
+		d.add(i18n.T("doctor.category.config"), ".gitleaks.toml", CheckPass, i18n.T("doctor.parsed"))
+	}
+
+	if cfg.Devex != nil {
+		for _, ignore := range ExpiredIgnores(cfg.Devex.Ignores) {
+			d.add(i18n.T("doctor.category.config"), i18n.T("doctor.ignore", DescribeIgnore(ignore)), CheckWarn, i18n.T("doctor.ignore_expired", ignore.Expires, ignore.Reason))
+		}
+	}
+}
+
+// checkTemplateDrift 检查项目中的模板文件
+// 有 .devex.lock 时按其中记录的校验和判断文件是否在 devex 写入后被修改（合并到已有文件的内容以合并结果为准），
+// 并单独报告当前 devex 版本的模板与写入时不同、可以通过 devex upgrade 更新的文件；
+// 没有清单时只能与当前版本内置的模板比较
+func (d *Doctor) checkTemplateDrift() error {
+	templateFS, globalConfigPath, err := getTemplatePath("global_config")
+	if err != nil {
+		return err
+	}
+
+	layers := []string{globalConfigPath}
+	var ci CIOptions
+	if d.manifest != nil {
+		for _, layer := range d.manifest.Layers {
+			if _, err := fs.Stat(templateFS, layer); err == nil {
+				layers = append(layers, layer)
+			}
+		}
+		if d.manifest.CI != nil {
+			ci = *d.manifest.CI
+		}
+	}
+
+	files, err := collectTemplates(templateFS, layers)
+	if err != nil {
+		return err
+	}
+	if ci.Enabled() {
+		if ciFiles, err := collectCITemplates(templateFS, ci, layers[1:]); err == nil {
+			files = mergeTemplateFiles(files, ciFiles)
+		}
+	}
+
+	if d.manifest == nil {
+		for _, file := range files {
+			actual, err := os.ReadFile(filepath.Join(d.ProjectPath, filepath.FromSlash(file.Path)))
+			switch {
+			case err != nil:
+				d.add(i18n.T("doctor.category.template"), file.Path, CheckWarn, i18n.T("doctor.file_missing"))
+			case bytes.Equal(actual, file.Content):
+				d.add(i18n.T("doctor.category.template"), file.Path, CheckPass, i18n.T("doctor.template_current"))
+			default:
+				d.add(i18n.T("doctor.category.template"), file.Path, CheckWarn, i18n.T("doctor.template_drift"))
+			}
+		}
+		return nil
+	}
+
+	current := make(map[string][]byte)
+	for _, file := range files {
+		current[file.Path] = file.Content
+	}
+	paths := make([]string, 0, len(d.manifest.Files))
+	for path := range d.manifest.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var newer []string
+	for _, path := range paths {
+		entry := d.manifest.Files[path]
+		actual, err := os.ReadFile(filepath.Join(d.ProjectPath, filepath.FromSlash(path)))
+		switch {
+		case err != nil:
+			d.add(i18n.T("doctor.category.template"), path, CheckWarn, i18n.T("doctor.file_missing"))
+		case checksum(actual) == entry.Checksum:
+			d.add(i18n.T("doctor.category.template"), path, CheckPass, i18n.T("doctor.template_unchanged"))
+		default:
+			d.add(i18n.T("doctor.category.template"), path, CheckWarn, i18n.T("doctor.template_modified"))
+		}
+		if content, ok := current[path]; ok && string(content) != entry.Template {
+			newer = append(newer, path)
+		}
+	}
+	for _, file := range files {
+		if _, ok := d.manifest.Files[file.Path]; !ok {
+			newer = append(newer, file.Path)
+		}
+	}
+	for _, path := range newer {
+		d.add(i18n.T("doctor.category.template"), path, CheckWarn, i18n.T("doctor.template_newer"))
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"devex/cmd/i18n"
+)
+
+// fakeCommands 在新的 PATH 目录中创建输出版本号的命令，PATH 中只有这些命令
+func fakeCommands(t *testing.T, names ...string) {
+	t.Helper()
+	bin := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(bin, name)
+		writeTestFile(t, path, "#!/bin/sh\necho \""+name+" 1.0\"\n")
+		if err := os.Chmod(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+}
+
+// statuses 按检查项名称汇总诊断结果的状态
+func statuses(results []CheckResult) map[string]CheckStatus {
+	got := make(map[string]CheckStatus)
+	for _, r := range results {
+		got[r.Name] = r.Status
+	}
+	return got
+}
+
+func TestDoctorCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		want      map[string]CheckStatus
+	}{
+		{
+			name:      "all installed",
+			installed: []string{"git", "devex", "pre-commit", "gitleaks"},
+			want:      map[string]CheckStatus{"git": CheckPass, "devex": CheckPass, "pre-commit": CheckPass, "gitleaks": CheckPass},
+		},
+		{
+			// 钩子只在 pre-commit 存在时调用它，没有 gitleaks 时使用内置扫描器
+			name:      "optional tools missing",
+			installed: []string{"git", "devex"},
+			want:      map[string]CheckStatus{"git": CheckPass, "devex": CheckPass, "pre-commit": CheckWarn, "gitleaks": CheckWarn},
+		},
+		{
+			name:      "devex missing",
+			installed: []string{"git", "pre-commit"},
+			want:      map[string]CheckStatus{"git": CheckPass, "devex": CheckFail, "pre-commit": CheckPass, "gitleaks": CheckWarn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommands(t, tt.installed...)
+			d := NewDoctor(t.TempDir(), nil)
+			d.checkCommands()
+			got := statuses(d.results)
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %s, want %s", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDoctorLanguageCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []string
+		layers    []string // .devex.lock 中记录的语言配置层，为空表示没有清单
+		want      map[string]CheckStatus
+	}{
+		{name: "explicit language", languages: []string{"kotlin"}, layers: []string{"swift/config"}, want: map[string]CheckStatus{"gradle": CheckFail, "java": CheckFail}},
+		{name: "languages from the lock", layers: []string{"swift/config"}, want: map[string]CheckStatus{"xcodegen": CheckFail, "pod": CheckFail}},
+		{name: "lock without languages", layers: []string{}, want: map[string]CheckStatus{}},
+		{name: "no lock", want: map[string]CheckStatus{"xcodegen": CheckWarn, "pod": CheckWarn, "gradle": CheckWarn, "java": CheckWarn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommands(t)
+			d := NewDoctor(t.TempDir(), tt.languages)
+			if tt.layers != nil {
+				d.manifest = &Manifest{Layers: tt.layers, Files: map[string]ManifestEntry{}}
+			}
+			if err := d.checkLanguageCommands(); err != nil {
+				t.Fatal(err)
+			}
+			got := statuses(d.results)
+			if len(got) != len(tt.want) {
+				t.Errorf("checked %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %s, want %s", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDoctorHooks(t *testing.T) {
+	preCommit, _ := findGitHook("pre-commit")
+	tests := []struct {
+		name    string
+		content string // 为空表示不安装
+		mode    os.FileMode
+		want    CheckStatus
+		detail  string
+	}{
+		{name: "missing", want: CheckFail, detail: i18n.T("doctor.hook_missing")},
+		{name: "not executable", content: string(renderHook(preCommit)), mode: 0644, want: CheckFail},
+		{name: "current", content: string(renderHook(preCommit)), mode: 0755, want: CheckPass},
+		{name: "outdated", content: string(renderHook(preCommit)) + "# old\n", mode: 0755, want: CheckWarn, detail: i18n.T("doctor.hook_outdated")},
+		{name: "foreign", content: "#!/bin/sh\nexit 0\n", mode: 0755, want: CheckWarn, detail: i18n.T("doctor.hook_foreign")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("DEVEX_HOOKS", "")
+			repo := t.TempDir()
+			initGitRepo(t, repo)
+			writeTestFile(t, filepath.Join(repo, RepoConfigFile), "hooks: [pre-commit]\n")
+			if tt.content != "" {
+				path := filepath.Join(repo, ".git", "hooks", "pre-commit")
+				writeTestFile(t, path, tt.content)
+				if err := os.Chmod(path, tt.mode); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			d := NewDoctor(repo, nil)
+			d.checkHooks()
+			if len(d.results) != 1 {
+				t.Fatalf("results = %+v", d.results)
+			}
+			got := d.results[0]
+			if got.Name != "pre-commit" || got.Status != tt.want || (tt.detail != "" && got.Detail != tt.detail) {
+				t.Errorf("got %+v, want %s %q", got, tt.want, tt.detail)
+			}
+		})
+	}
+}
+
+func TestDoctorGitleaksConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string // 为空表示没有 .gitleaks.toml
+		want   []CheckStatus
+	}{
+		{name: "missing", want: []CheckStatus{CheckFail}},
+		{name: "invalid toml", config: "[rules\n", want: []CheckStatus{CheckFail}},
+		{name: "valid", config: "[[rules]]\nid = \"a\"\nregex = 'a'\n", want: []CheckStatus{CheckPass}},
+		{name: "bad regex", config: "[[rules]]\nid = \"a\"\nregex = '('\n", want: []CheckStatus{CheckWarn}},
+		{name: "unknown keys", config: "version = 2\n", want: []CheckStatus{CheckWarn}},
+		{
+			name:   "expired ignore",
+			config: "[[devex.ignores]]\nkind = \"rule\"\nvalue = \"a\"\nreason = \"legacy\"\nexpires = \"2000-01-01\"\nadded = \"1999-01-01\"\n",
+			want:   []CheckStatus{CheckPass, CheckWarn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.config != "" {
+				writeTestFile(t, filepath.Join(dir, ".gitleaks.toml"), tt.config)
+			}
+			d := NewDoctor(dir, nil)
+			d.checkGitleaksConfig()
+			var got []CheckStatus
+			for _, r := range d.results {
+				got = append(got, r.Status)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("results = %+v, want %v", d.results, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("results = %+v, want %v", d.results, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestDoctorTemplateDrift 有 .devex.lock 时以记录的校验和判断文件是否被修改，模板的新版本单独报告
+func TestDoctorTemplateDrift(t *testing.T) {
+	templateFS, globalConfigPath, err := getTemplatePath("global_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := collectTemplates(templateFS, []string{globalConfigPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template string
+	for _, file := range files {
+		if file.Path == ".gitignore" {
+			template = string(file.Content)
+		}
+	}
+	if template == "" {
+		t.Fatal("no .gitignore in the global config")
+	}
+	// add 把模板合并到项目原有的 .gitignore 中
+	merged := "build/\n" + template
+
+	tests := []struct {
+		name     string
+		noLock   bool
+		content  string // 项目中的 .gitignore，为空表示已删除
+		recorded string // 清单中记录的 .gitignore 内容
+		template string // 清单中记录的模板内容
+		want     []string
+	}{
+		{name: "merged and unchanged", content: merged, recorded: merged, template: template, want: []string{"pass:doctor.template_unchanged"}},
+		{name: "modified after add", content: merged + "*.log\n", recorded: merged, template: template, want: []string{"warn:doctor.template_modified"}},
+		{name: "deleted", recorded: merged, template: template, want: []string{"warn:doctor.file_missing"}},
+		{
+			name: "newer template", content: merged, recorded: merged, template: "# old\n",
+			want: []string{"pass:doctor.template_unchanged", "warn:doctor.template_newer"},
+		},
+		{name: "no lock matches the template", noLock: true, content: template, want: []string{"pass:doctor.template_current"}},
+		{name: "no lock merged", noLock: true, content: merged, want: []string{"warn:doctor.template_drift"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := NewDoctor(dir, nil)
+			if !tt.noLock {
+				d.manifest = &Manifest{Files: map[string]ManifestEntry{}}
+			}
+			for _, file := range files {
+				content := file.Content
+				if file.Path == ".gitignore" {
+					content = []byte(tt.content)
+					if d.manifest != nil {
+						d.manifest.Record(file.Path, []byte(tt.recorded), []byte(tt.template))
+					}
+				} else if d.manifest != nil {
+					d.manifest.Record(file.Path, content, content)
+				}
+				if len(content) > 0 {
+					writeTestFile(t, filepath.Join(dir, filepath.FromSlash(file.Path)), string(content))
+				}
+			}
+
+			if err := d.checkTemplateDrift(); err != nil {
+				t.Fatal(err)
+			}
+			details := map[string]string{}
+			for _, key := range []string{"doctor.template_unchanged", "doctor.template_modified", "doctor.template_newer", "doctor.file_missing", "doctor.template_current", "doctor.template_drift"} {
+				details[i18n.T(key)] = key
+			}
+			var got []string
+			for _, r := range d.results {
+				if r.Name == ".gitignore" {
+					got = append(got, string(r.Status)+":"+details[r.Detail])
+				} else if r.Status != CheckPass {
+					t.Errorf("unexpected result %+v", r)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
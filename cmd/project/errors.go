@@ -0,0 +1,90 @@
+package project
+
+import (
+	"errors"
+
+	"devex/cmd/i18n"
+)
+
+// ErrorCode 稳定的错误码，用于 JSON 输出并决定退出码
+type ErrorCode string
+
+const (
+	ErrFindings    ErrorCode = "findings"    // 检查发现了问题：敏感信息、不合规的提交信息、诊断失败项
+	ErrUsage       ErrorCode = "usage"       // 命令行参数错误
+	ErrConfig      ErrorCode = "config"      // 配置文件、模板变量或项目状态无效
+	ErrTemplate    ErrorCode = "template"    // 模板不存在、无法获取、无法渲染或生成后命令失败
+	ErrDependency  ErrorCode = "dependency"  // 缺少必需的外部工具
+	ErrConflict    ErrorCode = "conflict"    // 目标已存在或被修改，拒绝覆盖
+	ErrGit         ErrorCode = "git"         // git 或其他外部命令执行失败
+	ErrIO          ErrorCode = "io"          // 读写文件失败
+	ErrInternal    ErrorCode = "internal"    // 未分类的错误
+	ErrInterrupted ErrorCode = "interrupted" // 收到中断信号
+)
+
+// exitCodes 各类错误的退出码，脚本可以据此区分失败原因
+var exitCodes = map[ErrorCode]int{
+	ErrFindings:    1,
+	ErrUsage:       2,
+	ErrConfig:      3,
+	ErrTemplate:    4,
+	ErrDependency:  5,
+	ErrConflict:    6,
+	ErrGit:         7,
+	ErrIO:          8,
+	ErrInternal:    9,
+	ErrInterrupted: 130,
+}
+
+// ExitCode 获取错误码对应的退出码
+func ExitCode(code ErrorCode) int {
+	if exit, ok := exitCodes[code]; ok {
+		return exit
+	}
+	return exitCodes[ErrInternal]
+}
+
+// CodedError 带错误码的错误，错误信息与原错误相同
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode 为错误加上错误码，err 为空时返回空
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// Errorf 以消息目录中 key 对应的消息创建带错误码的错误，格式与 fmt.Errorf 相同
+func Errorf(code ErrorCode, key string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: i18n.Errorf(key, args...)}
+}
+
+// ErrorCodeOf 获取错误链中最外层的错误码，没有时为 ErrInternal
+func ErrorCodeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ErrInternal
+}
+
+// WithDefaultCode 为还没有错误码的错误加上错误码，已有错误码的错误保持不变
+func WithDefaultCode(code ErrorCode, err error) error {
+	var coded *CodedError
+	if err == nil || errors.As(err, &coded) {
+		return err
+	}
+	return WithCode(code, err)
+}
@@ -0,0 +1,58 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestExitCode 退出码是脚本依赖的稳定接口，不能随意修改
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		want int
+	}{
+		{code: ErrFindings, want: 1},
+		{code: ErrUsage, want: 2},
+		{code: ErrConfig, want: 3},
+		{code: ErrTemplate, want: 4},
+		{code: ErrDependency, want: 5},
+		{code: ErrConflict, want: 6},
+		{code: ErrGit, want: 7},
+		{code: ErrIO, want: 8},
+		{code: ErrInternal, want: 9},
+		{code: ErrInterrupted, want: 130},
+		{code: "unknown", want: 9},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			if got := ExitCode(tt.code); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if len(exitCodes) != len(tests)-1 {
+		t.Errorf("exitCodes has %d entries, the test covers %d", len(exitCodes), len(tests)-1)
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{name: "uncoded", err: errors.New("boom"), want: ErrInternal},
+		{name: "coded", err: WithCode(ErrConfig, errors.New("bad")), want: ErrConfig},
+		{name: "wrapped", err: fmt.Errorf("add: %w", WithCode(ErrGit, errors.New("failed"))), want: ErrGit},
+		{name: "default does not override", err: WithDefaultCode(ErrIO, WithCode(ErrConflict, errors.New("exists"))), want: ErrConflict},
+		{name: "default", err: WithDefaultCode(ErrIO, errors.New("write failed")), want: ErrIO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCodeOf(tt.err); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
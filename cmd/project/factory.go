@@ -1,31 +1,64 @@
 package project
 
 import (
-	"fmt"
+	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // NewInitializer 根据命令类型创建不同的项目初始化器
-func NewInitializer(commandType, projectName, path string, noGit, noCheck bool, remote string) (Initializer, error) {
+// lang 为空时 init 命令只添加通用配置，否则创建对应语言的初始化器
+func NewInitializer(commandType, lang, projectName, path string, noGit, noCheck bool, remote string) (Initializer, error) {
 	switch commandType {
 	case "init":
 		// init命令：创建新项目
+		if lang != "" {
+			return NewLanguageInitializer(lang, projectName, path, noGit, noCheck, remote)
+		}
 		return NewInitInitializer(projectName, path, noGit, noCheck, remote)
 	case "add":
-		// add命令：为现有项目添加代码审查功能
-		return NewAddInitializer(path)
+		// add命令：为现有项目添加代码审查功能，lang 可以是逗号分隔的多种语言
+		var languages []string
+		if lang != "" {
+			languages = strings.Split(lang, ",")
+		}
+		return NewAddInitializer(path, languages, nil)
 	default:
-		return nil, fmt.Errorf("不支持的命令类型: %s", commandType)
+		return nil, i18n.Errorf("factory.unsupported_command", commandType)
 	}
 }
 
 // NewInitializerForInit 专门为init命令创建初始化器（向后兼容）
-func NewInitializerForInit(projectName, path string, noGit, noCheck bool, remote string) (Initializer, error) {
-	return NewInitInitializer(projectName, path, noGit, noCheck, remote)
+func NewInitializerForInit(lang, projectName, path string, noGit, noCheck bool, remote string) (Initializer, error) {
+	return NewInitializer("init", lang, projectName, path, noGit, noCheck, remote)
+}
+
+// NewLanguageInitializer 根据 LanguageConfig 创建语言特定的初始化器
+func NewLanguageInitializer(lang, projectName, path string, noGit, noCheck bool, remote string) (Initializer, error) {
+	config, err := GetLanguageConfig(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	templateFS, err := templateRoot()
+	if err != nil {
+		return nil, i18n.Errorf("factory.template_not_found", err)
+	}
+
+	switch config.Name {
+	case "swift":
+		return NewSwiftInitializer(templateFS, projectName, path, config.GlobalConfigPath, config.ConfigPath, config.TemplateCodePath, noGit, noCheck, remote), nil
+	case "kotlin":
+		return NewKotlinInitializer(templateFS, projectName, path, config.GlobalConfigPath, config.ConfigPath, config.TemplateCodePath, noGit, noCheck, remote), nil
+	default:
+		return nil, i18n.Errorf("factory.no_initializer", lang)
+	}
 }
 
 // NewInitializerForAdd 专门为add命令创建初始化器（向后兼容）
-func NewInitializerForAdd(path string) (Initializer, error) {
-	return NewAddInitializer(path)
+// languages 为空时自动检测项目语言，reporter 为空时使用默认的 Reporter
+func NewInitializerForAdd(path string, languages []string, reporter Reporter) (Initializer, error) {
+	return NewAddInitializer(path, languages, reporter)
 }
 
 // GetSupportedLanguagesFromConfig 获取支持的语言列表（使用配置系统）
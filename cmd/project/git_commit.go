@@ -0,0 +1,190 @@
+package project
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"devex/cmd/i18n"
+)
+
+// defaultCommitDescription 默认提交信息的描述部分
+const defaultCommitDescription = "add devex code review configuration"
+
+// GitCommitOptions devex 写入文件后在仓库中执行的 Git 操作
+type GitCommitOptions struct {
+	Branch  string // 创建并切换到的分支，为空时使用当前分支
+	Commit  bool   // 是否提交写入的文件
+	Message string // 提交信息，为空时按仓库的提交信息规范生成
+}
+
+// Enabled 是否需要执行 Git 操作
+func (o GitCommitOptions) Enabled() bool {
+	return o.Branch != "" || o.Commit
+}
+
+// DefaultCommitMessage 按提交信息规范生成默认的英文提交信息
+// 格式为 Conventional Commits，类型优先使用 chore，规范要求范围时优先使用 devex
+func DefaultCommitMessage(policy *CommitMsgPolicy) string {
+	commitType := "chore"
+	if policy.Conventional && len(policy.Types) > 0 && !containsString(policy.Types, commitType) {
+		commitType = policy.Types[0]
+	}
+	if policy.RequireScope {
+		scope := "devex"
+		if len(policy.Scopes) > 0 && !containsString(policy.Scopes, scope) {
+			scope = policy.Scopes[0]
+		}
+		commitType += "(" + scope + ")"
+	}
+	return commitType + ": " + defaultCommitDescription
+}
+
+// PrepareCommit 在写入文件前检查能否按选项创建分支和提交，返回补全提交信息后的选项
+// 暂存区中已有修改时拒绝执行，避免与 devex 写入的文件混在一起提交；
+// 提交信息按 repo 所在仓库的提交信息规范生成或检查；启用的钩子依赖 devex 时检查 devex 是否在 PATH 中，
+// 否则钩子会拒绝提交，写入文件后才失败
+func PrepareCommit(repo string, opts GitCommitOptions) (GitCommitOptions, error) {
+	if _, err := runGit(repo, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return opts, Errorf(ErrConfig, "git_hooks.not_repo", repo)
+	}
+
+	out, err := runGit(repo, "diff", "--cached", "--name-only")
+	if err != nil {
+		return opts, err
+	}
+	if staged := strings.TrimSpace(out); staged != "" {
+		return opts, Errorf(ErrConflict, "git_commit.staged_changes", strings.ReplaceAll(staged, "\n", ", "))
+	}
+
+	if opts.Branch != "" {
+		if _, err := runGit(repo, "check-ref-format", "--branch", opts.Branch); err != nil {
+			return opts, Errorf(ErrUsage, "git_commit.bad_branch", opts.Branch)
+		}
+		if _, err := runGit(repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+opts.Branch); err == nil {
+			return opts, Errorf(ErrConflict, "git_commit.branch_exists", opts.Branch)
+		}
+	}
+	if !opts.Commit {
+		return opts, nil
+	}
+
+	s, err := ReadSettings(repo)
+	if err != nil {
+		return opts, WithDefaultCode(ErrConfig, err)
+	}
+	if err := checkCommitHooks(s); err != nil {
+		return opts, err
+	}
+	policy, err := NewCommitMsgPolicy(s)
+	if err != nil {
+		return opts, WithDefaultCode(ErrConfig, err)
+	}
+	if opts.Message == "" {
+		opts.Message = DefaultCommitMessage(policy)
+	}
+	if violations := policy.Check(CleanCommitMessage(opts.Message, false)); len(violations) > 0 {
+		var messages []string
+		for _, v := range violations {
+			messages = append(messages, v.Message)
+		}
+		return opts, Errorf(ErrUsage, "git_commit.message_violates", strings.Join(messages, "; "))
+	}
+	return opts, nil
+}
+
+// checkCommitHooks 检查启用的钩子调用的 devex 子命令能否执行，缺少 devex 时提交会被钩子拒绝
+func checkCommitHooks(s *Settings) error {
+	var hooks []string
+	for _, hook := range enabledGitHooks(s) {
+		if hook.Command != "" {
+			hooks = append(hooks, hook.Name)
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	if missing := NewCommandDependencyChecker().GetMissingDependencies([]string{"devex"}); len(missing) > 0 {
+		return Errorf(ErrDependency, "git_commit.devex_not_found", strings.Join(hooks, ", "))
+	}
+	return nil
+}
+
+// CommitFiles 按选项在仓库中创建分支并提交 files，返回提交的短哈希（未提交时为空）
+// files 为 devex 写入的文件，仓库工作区之外的文件（如 .git/hooks 中的钩子）不提交；
+// 只暂存和提交这些文件，其他修改保持不变。提交失败时撤销暂存并切回原分支、删除新建的分支
+// 调用前应先通过 PrepareCommit 检查并补全选项
+func CommitFiles(repo string, files []string, opts GitCommitOptions, out io.Writer) (string, error) {
+	if opts.Branch != "" {
+		if _, err := runGit(repo, "checkout", "--quiet", "-b", opts.Branch); err != nil {
+			return "", i18n.Errorf("git_commit.branch_failed", opts.Branch, err)
+		}
+	}
+	if !opts.Commit {
+		return "", nil
+	}
+
+	paths := worktreePaths(repo, files)
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	if err := commitPaths(repo, paths, opts.Message, out); err != nil {
+		runGit(repo, append([]string{"reset", "--quiet", "--"}, paths...)...)
+		if opts.Branch != "" {
+			runGit(repo, "checkout", "--quiet", "-")
+			runGit(repo, "branch", "--quiet", "-D", opts.Branch)
+		}
+		return "", err
+	}
+
+	hash, err := runGit(repo, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(hash), nil
+}
+
+// commitPaths 暂存并只提交 paths，成功时钩子的输出写入 out，失败时钩子的输出包含在错误中
+func commitPaths(repo string, paths []string, message string, out io.Writer) error {
+	if _, err := runGit(repo, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", append([]string{"commit", "--quiet", "-m", message, "--"}, paths...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(output.String()); detail != "" {
+			return Errorf(ErrGit, "git_commit.commit_failed_output", err, detail)
+		}
+		return Errorf(ErrGit, "git_commit.commit_failed", err)
+	}
+	_, err := out.Write(output.Bytes())
+	return err
+}
+
+// worktreePaths 把文件转换为相对于仓库根目录的路径，去掉工作区之外和 .git 目录中的文件，重复的文件只保留一次
+func worktreePaths(repo string, files []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, file := range files {
+		rel, err := filepath.Rel(repo, file)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".." || strings.HasPrefix(rel, "../") || rel == ".git" || strings.HasPrefix(rel, ".git/") || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+	}
+	return paths
+}
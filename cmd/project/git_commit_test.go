@@ -0,0 +1,186 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commandsWithGit PATH 中只有 git 和 names 对应的假命令
+func commandsWithGit(t *testing.T, names ...string) {
+	t.Helper()
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not found")
+	}
+	fakeCommands(t, names...)
+	t.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator)+filepath.Dir(git))
+}
+
+// initCommitRepo 创建有一个初始提交的仓库，钩子配置只使用仓库中的 .devex.yaml
+func initCommitRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DEVEX_HOOKS", "")
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeTestFile(t, filepath.Join(repo, "README.md"), "# demo\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "--quiet", "-m", "chore: init")
+	return repo
+}
+
+func TestDefaultCommitMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CommitMsgPolicy
+		want   string
+	}{
+		{name: "no policy", want: "chore: " + defaultCommitDescription},
+		{name: "chore allowed", policy: CommitMsgPolicy{Conventional: true, Types: []string{"feat", "chore"}}, want: "chore: " + defaultCommitDescription},
+		{name: "chore not allowed", policy: CommitMsgPolicy{Conventional: true, Types: []string{"build", "ci"}}, want: "build: " + defaultCommitDescription},
+		{name: "scope required", policy: CommitMsgPolicy{Conventional: true, RequireScope: true}, want: "chore(devex): " + defaultCommitDescription},
+		{name: "devex scope not allowed", policy: CommitMsgPolicy{Conventional: true, RequireScope: true, Scopes: []string{"infra", "app"}}, want: "chore(infra): " + defaultCommitDescription},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultCommitMessage(&tt.policy); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorktreePaths(t *testing.T) {
+	repo := t.TempDir()
+	files := []string{
+		filepath.Join(repo, ".gitignore"),
+		filepath.Join(repo, "config", "detekt", "detekt.yml"),
+		filepath.Join(repo, ".git", "hooks", "pre-commit"),
+		filepath.Join(repo, ".git"),
+		filepath.Join(repo, ".github", "workflows", "devex.yml"),
+		filepath.Join(filepath.Dir(repo), "outside.txt"),
+		filepath.Join(repo, ".gitignore"),
+	}
+	want := []string{".gitignore", "config/detekt/detekt.yml", ".github/workflows/devex.yml"}
+	if got := worktreePaths(repo, files); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPrepareCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		hooks    string   // .devex.yaml 中的 hooks
+		commands []string // PATH 中除 git 以外的命令
+		staged   bool     // 暂存区中已有其他修改
+		branch   string   // 已存在的分支
+		opts     GitCommitOptions
+		want     ErrorCode // 为空表示成功
+		message  string    // 成功时补全的提交信息
+	}{
+		{name: "default message", hooks: "[pre-commit, commit-msg]", commands: []string{"devex"}, opts: GitCommitOptions{Commit: true}, message: "chore: " + defaultCommitDescription},
+		{name: "explicit message", hooks: "[]", opts: GitCommitOptions{Commit: true, Message: "docs: add config"}, message: "docs: add config"},
+		{name: "staged changes", hooks: "[]", staged: true, opts: GitCommitOptions{Commit: true}, want: ErrConflict},
+		{name: "branch exists", hooks: "[]", branch: "devex", opts: GitCommitOptions{Branch: "devex"}, want: ErrConflict},
+		{name: "bad branch", hooks: "[]", opts: GitCommitOptions{Branch: "bad..name"}, want: ErrUsage},
+		{name: "hooks need devex", hooks: "[pre-commit, commit-msg]", opts: GitCommitOptions{Commit: true}, want: ErrDependency},
+		{name: "hooks without devex commands", hooks: "[post-commit]", opts: GitCommitOptions{Commit: true}, message: "chore: " + defaultCommitDescription},
+		{name: "branch only does not run hooks", hooks: "[pre-commit]", opts: GitCommitOptions{Branch: "devex"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := initCommitRepo(t)
+			writeTestFile(t, filepath.Join(repo, RepoConfigFile), "hooks: "+tt.hooks+"\n")
+			if tt.staged {
+				writeTestFile(t, filepath.Join(repo, "other.txt"), "other\n")
+				runTestGit(t, repo, "add", "other.txt")
+			}
+			if tt.branch != "" {
+				runTestGit(t, repo, "branch", tt.branch)
+			}
+			commandsWithGit(t, tt.commands...)
+
+			opts, err := PrepareCommit(repo, tt.opts)
+			if tt.want != "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if code := ErrorCodeOf(err); code != tt.want {
+					t.Errorf("code = %s, want %s: %v", code, tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.Message != tt.message {
+				t.Errorf("message = %q, want %q", opts.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	repo := initCommitRepo(t)
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "build/\n")
+	writeTestFile(t, filepath.Join(repo, ".git", "hooks", "commit-msg.sample"), "#!/bin/sh\n")
+	writeTestFile(t, filepath.Join(repo, "README.md"), "# changed by the user\n")
+
+	files := []string{filepath.Join(repo, ".gitignore"), filepath.Join(repo, ".git", "hooks", "commit-msg.sample")}
+	var out bytes.Buffer
+	commit, err := CommitFiles(repo, files, GitCommitOptions{Branch: "devex", Commit: true, Message: "chore: add devex"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit == "" {
+		t.Fatal("no commit")
+	}
+	if got := strings.TrimSpace(runTestGit(t, repo, "branch", "--show-current")); got != "devex" {
+		t.Errorf("branch = %s", got)
+	}
+	if got := strings.TrimSpace(runTestGit(t, repo, "show", "--name-only", "--format=", "HEAD")); got != ".gitignore" {
+		t.Errorf("committed files = %q, want .gitignore", got)
+	}
+	// 用户的其他修改保持未提交
+	if got := runTestGit(t, repo, "status", "--porcelain"); got != " M README.md\n" {
+		t.Errorf("status = %q", got)
+	}
+}
+
+// TestCommitFilesRollback 钩子拒绝提交时撤销暂存、切回原分支并删除新建的分支，错误中包含钩子的输出
+func TestCommitFilesRollback(t *testing.T) {
+	repo := initCommitRepo(t)
+	hook := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	writeTestFile(t, hook, "#!/bin/sh\necho \"rejected by the hook\" >&2\nexit 1\n")
+	if err := os.Chmod(hook, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "build/\n")
+
+	_, err := CommitFiles(repo, []string{filepath.Join(repo, ".gitignore")}, GitCommitOptions{Branch: "devex", Commit: true, Message: "chore: add devex"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	if code := ErrorCodeOf(err); code != ErrGit {
+		t.Errorf("code = %s, want %s", code, ErrGit)
+	}
+	if !strings.Contains(err.Error(), "rejected by the hook") {
+		t.Errorf("hook output missing from the error: %v", err)
+	}
+	if got := strings.TrimSpace(runTestGit(t, repo, "branch", "--show-current")); got != "main" {
+		t.Errorf("branch = %s, want main", got)
+	}
+	if got := runTestGit(t, repo, "branch", "--list", "devex"); got != "" {
+		t.Errorf("branch devex not deleted: %q", got)
+	}
+	if got := runTestGit(t, repo, "diff", "--cached", "--name-only"); got != "" {
+		t.Errorf("still staged: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".gitignore")); err != nil {
+		t.Errorf("written file removed: %v", err)
+	}
+}
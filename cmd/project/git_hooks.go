@@ -0,0 +1,237 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"devex/cmd/i18n"
+)
+
+const (
+	// hookMarker devex 生成的钩子文件中的标记行，用于识别和幂等更新
+	hookMarker = "# devex-managed-hook"
+	// chainedHookSuffix 被 devex 接管前已存在的钩子重命名后的后缀，devex 钩子会先执行它
+	chainedHookSuffix = ".devex-chained"
+)
+
+// gitHook 描述 devex 安装的一个 Git 钩子
+type gitHook struct {
+	Name           string // Git 钩子名称
+	Script         string // 项目 .git-hooks 目录下对应的脚本，为空表示不调用
+	PreCommitStage string // 需要调用的 pre-commit 框架阶段，为空表示不调用
+	PreCommitSkip  string // pre-commit 框架中跳过的钩子 id，这些检查已由 Command 执行，避免重复
+	Command        string // 需要调用的 devex 子命令，如 hook commit-msg，为空表示不调用
+}
+
+// gitHooks devex 安装的 Git 钩子
+var gitHooks = []gitHook{
+	{Name: "pre-commit", PreCommitStage: "pre-commit", PreCommitSkip: "gitleaks", Command: "scan"},
+	{Name: "commit-msg", Command: "hook commit-msg"},
+	{Name: "post-commit", Script: "post-commit"},
+}
+
+// findGitHook 按名称查找钩子
+func findGitHook(name string) (gitHook, bool) {
+	for _, hook := range gitHooks {
+		if hook.Name == name {
+			return hook, true
+		}
+	}
+	return gitHook{}, false
+}
+
+// enabledGitHooks 配置 s 的 hooks 中启用的钩子，按 gitHooks 中的顺序
+// s 应为目标仓库的配置（ReadSettings），批量执行时各仓库的 .devex.yaml 可以启用不同的钩子
+func enabledGitHooks(s *Settings) []gitHook {
+	enabled := make(map[string]bool)
+	for _, name := range s.List("hooks") {
+		enabled[name] = true
+	}
+
+	var hooks []gitHook
+	for _, hook := range gitHooks {
+		if enabled[hook.Name] {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// resolveHooksDir 获取仓库实际使用的钩子目录
+// 优先使用 git 解析（支持 core.hooksPath、worktree 和 submodule），git 不可用时自行解析
+func resolveHooksDir(repoPath string) (string, error) {
+	if _, err := exec.LookPath("git"); err == nil {
+		cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+		cmd.Dir = repoPath
+		if out, err := cmd.Output(); err == nil {
+			dir := strings.TrimSpace(string(out))
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(repoPath, dir)
+			}
+			return dir, nil
+		}
+	}
+
+	return resolveHooksDirFromFS(repoPath)
+}
+
+// resolveHooksDirFromFS 不依赖 git 命令解析钩子目录
+func resolveHooksDirFromFS(repoPath string) (string, error) {
+	gitDir, err := resolveGitDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	// worktree 的 gitdir 通过 commondir 指向主仓库，钩子位于主仓库中
+	commonDir := gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	if hooksPath := readCoreHooksPath(filepath.Join(commonDir, "config")); hooksPath != "" {
+		if !filepath.IsAbs(hooksPath) {
+			hooksPath = filepath.Join(repoPath, hooksPath)
+		}
+		return hooksPath, nil
+	}
+	return filepath.Join(commonDir, "hooks"), nil
+}
+
+// resolveGitDir 获取仓库的 git 目录，.git 为文件时（worktree、submodule）读取其中的 gitdir
+func resolveGitDir(repoPath string) (string, error) {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", Errorf(ErrConfig, "git_hooks.not_repo", repoPath)
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", i18n.Errorf("git_hooks.read_dotgit", err)
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", i18n.Errorf("git_hooks.bad_dotgit", dotGit)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoPath, gitDir)
+	}
+	return gitDir, nil
+}
+
+// readCoreHooksPath 从 git 配置文件中读取 core.hooksPath
+func readCoreHooksPath(configPath string) string {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inCore := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inCore = strings.EqualFold(strings.Trim(line, "[] \t"), "core")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if inCore && ok && strings.EqualFold(strings.TrimSpace(key), "hooksPath") {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
+
+// isDevexHook 判断钩子文件是否由 devex 生成
+func isDevexHook(content []byte) bool {
+	return bytes.Contains(content, []byte(hookMarker))
+}
+
+// renderHook 生成 devex 钩子脚本：依次执行原有钩子、pre-commit 框架、devex 子命令和项目中的钩子脚本
+// 需要 devex 子命令的钩子在 devex 不在 PATH 中时以非零状态退出
+// 脚本只使用 POSIX sh 语法，不依赖 bash
+func renderHook(hook gitHook) []byte {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString(hookMarker + "\n")
+	sb.WriteString("# Generated by devex. Re-running \"devex add\" rewrites this file.\n")
+	fmt.Fprintf(&sb, "# A hook that existed before devex was moved to %s%s and runs first.\n\n", hook.Name, chainedHookSuffix)
+
+	sb.WriteString("hook_dir=$(dirname \"$0\")\n")
+	fmt.Fprintf(&sb, "if [ -x \"$hook_dir/%s%s\" ]; then\n", hook.Name, chainedHookSuffix)
+	fmt.Fprintf(&sb, "    \"$hook_dir/%s%s\" \"$@\" || exit $?\n", hook.Name, chainedHookSuffix)
+	sb.WriteString("fi\n")
+
+	// 依赖 devex 子命令的钩子在 devex 不可用时拒绝提交，避免在未扫描、未检查的情况下放行
+	if hook.Command != "" {
+		sb.WriteString("\nif ! command -v devex >/dev/null 2>&1; then\n")
+		fmt.Fprintf(&sb, "    echo \"devex not found in PATH, cannot run devex %s.\" >&2\n", hook.Command)
+		sb.WriteString("    echo \"Install devex or add it to PATH; use git commit --no-verify to bypass.\" >&2\n")
+		sb.WriteString("    exit 1\n")
+		sb.WriteString("fi\n")
+	}
+
+	if hook.PreCommitStage != "" {
+		sb.WriteString("\nif command -v pre-commit >/dev/null 2>&1 && [ -f .pre-commit-config.yaml ]; then\n")
+		if hook.PreCommitSkip != "" {
+			fmt.Fprintf(&sb, "    SKIP=\"${SKIP:+$SKIP,}%s\" pre-commit run --hook-stage %s || exit $?\n", hook.PreCommitSkip, hook.PreCommitStage)
+		} else {
+			fmt.Fprintf(&sb, "    pre-commit run --hook-stage %s || exit $?\n", hook.PreCommitStage)
+		}
+		sb.WriteString("fi\n")
+	}
+
+	if hook.Command != "" {
+		fmt.Fprintf(&sb, "\ndevex %s \"$@\" || exit $?\n", hook.Command)
+	}
+
+	if hook.Script != "" {
+		fmt.Fprintf(&sb, "\nif [ -x .git-hooks/%s ]; then\n", hook.Script)
+		fmt.Fprintf(&sb, "    .git-hooks/%s \"$@\" || exit $?\n", hook.Script)
+		sb.WriteString("fi\n")
+	}
+	return []byte(sb.String())
+}
+
+// installHook 安装单个钩子，已有的非 devex 钩子会被重命名并串联执行
+func (b *BaseInitializer) installHook(hooksDir string, hook gitHook) error {
+	hookPath := filepath.Join(hooksDir, hook.Name)
+	chainedPath := hookPath + chainedHookSuffix
+
+	existing, err := os.ReadFile(hookPath)
+	if err == nil && !isDevexHook(existing) {
+		if _, err := os.Stat(chainedPath); err == nil {
+			return Errorf(ErrConflict, "git_hooks.modified", hookPath, chainedPath)
+		}
+		info, err := os.Stat(hookPath)
+		if err != nil {
+			return err
+		}
+		if err := b.writeFile(chainedPath, existing, info.Mode().Perm(), i18n.T("git_hooks.note_chained")); err != nil {
+			return err
+		}
+	}
+
+	return b.writeFile(hookPath, renderHook(hook), 0755, i18n.T("git_hooks.note_hook"))
+}
+
+// checkHookDependencies 检查钩子运行所需的工具，缺失时只给出提示
+func checkHookDependencies(reporter Reporter) {
+	checker := NewCommandDependencyChecker()
+	for _, cmd := range checker.GetMissingDependencies([]string{"pre-commit", "gitleaks", "devex"}) {
+		reporter.Warn(WarnDependencyMissing, i18n.T("git_hooks.dependency_missing", cmd, GetInstallationInstructions(cmd)))
+	}
+}
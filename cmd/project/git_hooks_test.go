@@ -0,0 +1,287 @@
+package project
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHook(t *testing.T) {
+	tests := []struct {
+		hook     string
+		contains []string
+		excludes []string
+	}{
+		{
+			hook: "pre-commit",
+			contains: []string{
+				"#!/bin/sh",
+				`SKIP="${SKIP:+$SKIP,}gitleaks" pre-commit run --hook-stage pre-commit`,
+				`devex scan "$@"`,
+			},
+			excludes: []string{"bash", ".git-hooks/pre-commit"},
+		},
+		{
+			hook:     "commit-msg",
+			contains: []string{"#!/bin/sh", `devex hook commit-msg "$@"`},
+			excludes: []string{"bash", "pre-commit run"},
+		},
+		{
+			hook:     "post-commit",
+			contains: []string{"#!/bin/sh", ".git-hooks/post-commit"},
+			excludes: []string{"bash", "devex scan"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hook, func(t *testing.T) {
+			hook, ok := findGitHook(tt.hook)
+			if !ok {
+				t.Fatalf("hook %s not found", tt.hook)
+			}
+			script := string(renderHook(hook))
+			for _, s := range tt.contains {
+				if !strings.Contains(script, s) {
+					t.Errorf("hook does not contain %q:\n%s", s, script)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(script, s) {
+					t.Errorf("hook contains %q:\n%s", s, script)
+				}
+			}
+
+			// 语法检查：只使用 POSIX sh
+			cmd := exec.Command("sh", "-n")
+			cmd.Stdin = strings.NewReader(script)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("sh -n: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestInstallHookChainsAndIsIdempotent(t *testing.T) {
+	hooksDir := t.TempDir()
+	existing := "#!/bin/sh\necho existing\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &BaseInitializer{FilePath: hooksDir, reporter: NewHumanReporter(io.Discard)}
+	hook, _ := findGitHook("pre-commit")
+	for i := 0; i < 2; i++ {
+		if err := b.installHook(hooksDir, hook); err != nil {
+			t.Fatalf("install %d: %v", i+1, err)
+		}
+	}
+
+	chained, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"+chainedHookSuffix))
+	if err != nil || string(chained) != existing {
+		t.Errorf("existing hook not preserved: %q, %v", chained, err)
+	}
+	installed, _ := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	if string(installed) != string(renderHook(hook)) {
+		t.Errorf("unexpected hook content:\n%s", installed)
+	}
+}
+
+func TestResolveHooksDirFromFS(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, repo string) string // 返回期望的钩子目录
+	}{
+		{
+			name: "git directory",
+			setup: func(t *testing.T, repo string) string {
+				mkdir(t, filepath.Join(repo, ".git"))
+				return filepath.Join(repo, ".git", "hooks")
+			},
+		},
+		{
+			name: "core.hooksPath",
+			setup: func(t *testing.T, repo string) string {
+				mkdir(t, filepath.Join(repo, ".git"))
+				writeTestFile(t, filepath.Join(repo, ".git", "config"), "[core]\n\thooksPath = \"tools/hooks\"\n")
+				return filepath.Join(repo, "tools", "hooks")
+			},
+		},
+		{
+			name: "worktree gitdir file",
+			setup: func(t *testing.T, repo string) string {
+				main := filepath.Join(repo, "main.git")
+				worktree := filepath.Join(main, "worktrees", "wt")
+				mkdir(t, worktree)
+				writeTestFile(t, filepath.Join(worktree, "commondir"), "../..\n")
+				writeTestFile(t, filepath.Join(repo, ".git"), "gitdir: "+worktree+"\n")
+				return filepath.Join(main, "hooks")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			want := tt.setup(t, repo)
+			got, err := resolveHooksDirFromFS(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Clean(got) != filepath.Clean(want) {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestPreCommitScansOnce 提交时 devex scan 只执行一次，pre-commit 框架跳过其中的 gitleaks 钩子
+// TestInstallGitHooksUsesRepoSettings 批量执行时按各仓库的 .devex.yaml 安装钩子，而不是当前目录的配置
+func TestInstallGitHooksUsesRepoSettings(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DEVEX_HOOKS", "")
+	defer func(s *Settings) { settings = s }(settings)
+	settings = &Settings{}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{name: "commit-msg only", config: "hooks: [commit-msg]\n", want: []string{"commit-msg"}},
+		{name: "pre-commit and post-commit", config: "hooks: [pre-commit, post-commit]\n", want: []string{"pre-commit", "post-commit"}},
+		{name: "default", want: []string{"pre-commit", "commit-msg", "post-commit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			initGitRepo(t, repo)
+			if tt.config != "" {
+				writeTestFile(t, filepath.Join(repo, RepoConfigFile), tt.config)
+			}
+
+			b := &BaseInitializer{FilePath: repo, reporter: NewHumanReporter(io.Discard)}
+			if err := b.InstallGitHooks(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, hook := range gitHooks {
+				if _, err := os.Stat(filepath.Join(repo, ".git", "hooks", hook.Name)); err == nil {
+					got = append(got, hook.Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("installed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreCommitScansOnce(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+
+	// 用记录调用参数的脚本代替 devex 和 pre-commit
+	bin := t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	writeTestFile(t, filepath.Join(bin, "devex"), "#!/bin/sh\necho \"devex $*\" >> "+calls+"\n")
+	writeTestFile(t, filepath.Join(bin, "pre-commit"), "#!/bin/sh\necho \"pre-commit SKIP=$SKIP $*\" >> "+calls+"\n")
+	for _, name := range []string{"devex", "pre-commit"} {
+		if err := os.Chmod(filepath.Join(bin, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	b := &BaseInitializer{FilePath: repo, reporter: NewHumanReporter(io.Discard)}
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	for _, name := range []string{"pre-commit", "commit-msg"} {
+		hook, _ := findGitHook(name)
+		if err := b.installHook(hooksDir, hook); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(repo, ".pre-commit-config.yaml"), "repos: []\n")
+	runTestGit(t, repo, "add", ".")
+	runTestGit(t, repo, "commit", "--quiet", "-m", "chore: add config")
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if n := strings.Count(got, "devex scan"); n != 1 {
+		t.Errorf("devex scan ran %d times:\n%s", n, got)
+	}
+	if !strings.Contains(got, "pre-commit SKIP=gitleaks run --hook-stage pre-commit") {
+		t.Errorf("pre-commit did not skip gitleaks:\n%s", got)
+	}
+	if !strings.Contains(got, "devex hook commit-msg") {
+		t.Errorf("commit-msg hook did not run:\n%s", got)
+	}
+}
+
+// TestHookFailsWithoutDevex devex 不在 PATH 中时钩子拒绝提交，也不会让 pre-commit 框架跳过 gitleaks
+func TestHookFailsWithoutDevex(t *testing.T) {
+	dirname, err := exec.LookPath("dirname")
+	if err != nil {
+		t.Skip("dirname not installed")
+	}
+
+	for _, name := range []string{"pre-commit", "commit-msg"} {
+		t.Run(name, func(t *testing.T) {
+			// PATH 中只有 dirname 和记录调用的 pre-commit，没有 devex
+			work := t.TempDir()
+			bin := t.TempDir()
+			calls := filepath.Join(t.TempDir(), "calls")
+			if err := os.Symlink(dirname, filepath.Join(bin, "dirname")); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(bin, "pre-commit"), "#!/bin/sh\necho \"pre-commit SKIP=$SKIP $*\" >> "+calls+"\n")
+			if err := os.Chmod(filepath.Join(bin, "pre-commit"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(work, ".pre-commit-config.yaml"), "repos: []\n")
+
+			hook, _ := findGitHook(name)
+			hookPath := filepath.Join(work, name)
+			writeTestFile(t, hookPath, string(renderHook(hook)))
+
+			cmd := exec.Command("sh", hookPath, ".git/COMMIT_EDITMSG")
+			cmd.Dir = work
+			cmd.Env = append(os.Environ(), "PATH="+bin)
+			out, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("hook succeeded without devex:\n%s", out)
+			}
+			if !strings.Contains(string(out), "devex not found in PATH") {
+				t.Errorf("unexpected output:\n%s", out)
+			}
+			if _, err := os.Stat(calls); err == nil {
+				data, _ := os.ReadFile(calls)
+				t.Errorf("pre-commit ran without devex:\n%s", data)
+			}
+		})
+	}
+}
+
+func mkdir(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	mkdir(t, filepath.Dir(path))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,7 +1,7 @@
 package project
 
 import (
-	"fmt"
+	"devex/cmd/i18n"
 )
 
 // InitInitializer 项目初始化器
@@ -12,13 +12,14 @@ type InitInitializer struct {
 
 // NewInitInitializer 创建项目初始化器
 func NewInitInitializer(projectName, projectPath string, noGit, noCheck bool, remote string) (*InitInitializer, error) {
-	globalConfigPath, err := getTemplatePath("global_config")
+	templateFS, globalConfigPath, err := getTemplatePath("global_config")
 	if err != nil {
-		return nil, fmt.Errorf("无法找到模板路径: %w", err)
+		return nil, i18n.Errorf("factory.template_not_found", err)
 	}
 
 	return &InitInitializer{
 		BaseInitializer: BaseInitializer{
+			TemplateFS:       templateFS,
 			ProjectName:      projectName,
 			FilePath:         projectPath,
 			GlobalConfigPath: globalConfigPath,
@@ -33,29 +34,29 @@ func NewInitInitializer(projectName, projectPath string, noGit, noCheck bool, re
 
 // CreateProject 创建项目结构和文件
 func (i *InitInitializer) CreateProject() error {
-	fmt.Println("📦 创建项目...")
-	fmt.Println("  - 设置项目结构")
-	fmt.Println("  - 配置基础文件")
+	i.report().Progress("📦", i18n.T("init_initializer.creating"))
+	i.report().Detail(i18n.T("init_initializer.structure"))
+	i.report().Detail(i18n.T("init_initializer.base_files"))
 	return nil
 }
 
 // InitDependencies 初始化项目依赖
 func (i *InitInitializer) InitDependencies() error {
-	fmt.Println("📥 初始化项目依赖...")
-	fmt.Println("  - 检查基础依赖")
+	i.report().Progress("📥", i18n.T("init_initializer.deps"))
+	i.report().Detail(i18n.T("init_initializer.checking_deps"))
 	return nil
 }
 
 // ConfigureCodeReview 配置代码审查工具
 func (i *InitInitializer) ConfigureCodeReview() error {
 	if i.NoCheck {
-		fmt.Println("⏭️  跳过代码审查配置 (使用了--no-check参数)")
+		i.report().Progress("⏭️ ", i18n.T("add_initializer.skip_review"))
 		return nil
 	}
 
-	fmt.Println("🔍 配置代码审查工具...")
-	fmt.Println("  - 配置通用代码检查工具")
-	fmt.Println("  - 设置Git钩子")
+	i.report().Progress("🔍", i18n.T("init_initializer.configuring_review"))
+	i.report().Detail(i18n.T("init_initializer.configuring_common"))
+	i.report().Detail(i18n.T("add_initializer.setting_hooks"))
 	return nil
 }
 
@@ -63,8 +64,8 @@ func (i *InitInitializer) ConfigureCodeReview() error {
 func (i *InitInitializer) ShowNextSteps() {
 	i.BaseInitializer.ShowNextSteps()
 
-	fmt.Println("\n项目下一步：")
-	fmt.Println("1. 使用编辑器打开项目")
-	fmt.Println("2. 安装相关依赖")
-	fmt.Println("3. 开始开发")
+	i.report().Info("\n" + i18n.T("init_initializer.next"))
+	i.report().Info(i18n.T("init_initializer.next_1"))
+	i.report().Info(i18n.T("init_initializer.next_2"))
+	i.report().Info(i18n.T("init_initializer.next_3"))
 }
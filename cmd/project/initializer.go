@@ -1,11 +1,13 @@
 package project
 
 import (
-	"fmt"
-	"io"
+	"bytes"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"devex/cmd/i18n"
 )
 
 // Initializer 定义项目初始化器的接口
@@ -30,47 +32,134 @@ type Initializer interface {
 
 	// ShowNextSteps 显示后续步骤
 	ShowNextSteps()
+
+	// SetDryRun 启用预演模式，后续步骤只把变更记录到 plan 中而不写入磁盘
+	SetDryRun(plan *Plan)
+
+	// SetJournal 设置变更日志，后续步骤的所有修改都会先记录到 journal 以便回滚
+	SetJournal(journal *Journal)
+
+	// SetTemplateValues 设置模板变量的取值，prompter 非空时缺少的变量通过交互式输入获取
+	SetTemplateValues(values map[string]string, prompter *Prompter) error
+
+	// SetReporter 设置输出，后续步骤的进度、写入的文件和警告都通过它报告
+	SetReporter(reporter Reporter)
+
+	// SetCI 设置生成的 CI 配置，复制模板文件时按语言配置层和默认分支渲染
+	SetCI(ci CIOptions)
 }
 
 // BaseInitializer 提供基础实现
 type BaseInitializer struct {
+	TemplateFS       fs.FS // 模板根文件系统，下面的模板路径均相对于它
 	ProjectName      string
 	FilePath         string
 	GlobalConfigPath string
+	ConfigLayers     []string  // 叠加在全局配置之上的语言配置目录，按顺序覆盖或合并同名文件
+	CI               CIOptions // 生成的 CI 配置，未启用时不生成
 	ConfigPath       string
 	TemplateCodePath string
 	NoGit            bool
 	NoCheck          bool
 	RemoteURL        string
+
+	templateValues map[string]string // 用户提供的模板变量取值（--set、--values）
+	prompter       *Prompter         // 交互式输入，为空时不提示
+
+	reporter  Reporter       // 进度输出，为空时使用默认的 Reporter
+	plan      *Plan          // 预演计划，非空时不写入任何文件
+	journal   *Journal       // 变更日志，非空时记录所有修改以便回滚
+	manifest  *Manifest      // 本次写入文件的清单，写入 .devex.lock
+	written   []string       // 本次写入的文件，按写入顺序
+	conflicts []FileConflict // 无法安全合并的文件
+}
+
+// FileConflict 无法安全合并到项目中的模板文件
+type FileConflict struct {
+	Path     string // 项目中的原文件
+	SideFile string // 模板内容写入的旁路文件
+	Reason   string // 无法合并的原因
+}
+
+// SetTemplateValues 设置模板变量的取值
+func (b *BaseInitializer) SetTemplateValues(values map[string]string, prompter *Prompter) error {
+	b.templateValues = values
+	b.prompter = prompter
+	return nil
+}
+
+// SetDryRun 启用预演模式
+func (b *BaseInitializer) SetDryRun(plan *Plan) {
+	b.plan = plan
+}
+
+// SetJournal 设置变更日志
+func (b *BaseInitializer) SetJournal(journal *Journal) {
+	b.journal = journal
+}
+
+// SetReporter 设置输出
+func (b *BaseInitializer) SetReporter(reporter Reporter) {
+	b.reporter = reporter
+}
+
+// SetCI 设置生成的 CI 配置
+func (b *BaseInitializer) SetCI(ci CIOptions) {
+	b.CI = ci
+}
+
+// WrittenFiles 获取本次写入的文件（含内容未变的文件），预演模式下为空
+func (b *BaseInitializer) WrittenFiles() []string {
+	return b.written
+}
+
+// Conflicts 获取无法安全合并、写入了旁路文件的模板文件
+func (b *BaseInitializer) Conflicts() []FileConflict {
+	return b.conflicts
+}
+
+// report 获取输出
+func (b *BaseInitializer) report() Reporter {
+	if b.reporter != nil {
+		return b.reporter
+	}
+	return defaultReporter
 }
 
 // CloneRepository 克隆远程仓库的基础实现
 func (b *BaseInitializer) CloneRepository() error {
-	fmt.Printf("📥 克隆远程仓库: %s\n", b.RemoteURL)
+	b.report().Progress("📥", i18n.T("initializer.cloning", b.RemoteURL))
 	if b.RemoteURL == "" {
-		return fmt.Errorf("未指定远程仓库地址")
+		return Errorf(ErrUsage, "initializer.no_remote")
 	}
 
 	// 检查git命令是否存在
 	if _, err := exec.LookPath("git"); err != nil {
-		return fmt.Errorf("未找到git命令，请先安装git")
+		return Errorf(ErrDependency, "initializer.no_git")
 	}
 
 	// 检查目标目录是否已存在
 	if _, err := os.Stat(b.FilePath); !os.IsNotExist(err) {
-		return fmt.Errorf("目标目录已存在: %s", b.FilePath)
+		return Errorf(ErrConflict, "initializer.dir_exists", b.FilePath)
+	}
+
+	// 记录将要新建的项目目录，失败时整体删除
+	if b.journal != nil {
+		if err := b.journal.TrackDir(b.FilePath); err != nil {
+			return err
+		}
 	}
 
 	// 执行git clone
-	fmt.Printf("  - 正在克隆到: %s\n", b.FilePath)
+	b.report().Detail(i18n.T("initializer.cloning_to", b.FilePath))
 	cmd := exec.Command("git", "clone", b.RemoteURL, b.FilePath)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = b.report().Output()
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("克隆远程仓库失败: %w", err)
+		return Errorf(ErrGit, "initializer.clone_failed", err)
 	}
 
-	fmt.Println("  ✅ 仓库克隆成功")
+	b.report().Success(i18n.T("initializer.cloned"))
 	return nil
 }
 
@@ -82,14 +171,36 @@ func (b *BaseInitializer) CreateProject() error {
 
 // CopyTemplateFiles 复制模板文件的基础实现
 func (b *BaseInitializer) CopyTemplateFiles() error {
-	fmt.Println("📂 复制模板文件...")
+	b.report().Progress("📂", i18n.T("initializer.copying"))
 
-	if err := copyDir(b.GlobalConfigPath, b.FilePath); err != nil {
-		return fmt.Errorf("复制全局配置文件失败: %w", err)
+	b.manifest = NewManifest()
+	b.manifest.Layers = b.ConfigLayers
+	if err := b.copyTemplates(); err != nil {
+		return i18n.Errorf("initializer.copy_failed", err)
+	}
+	if b.CI.Enabled() {
+		ci := b.CI
+		b.manifest.CI = &ci
+	}
+	if err := b.writeManifest(); err != nil {
+		return i18n.Errorf("upgrade.write_failed", ManifestFile, err)
+	}
+	if b.plan != nil {
+		return nil
+	}
+	for _, layer := range b.templateLayers() {
+		b.report().Detail(i18n.T("initializer.copied_layer", layer))
+	}
+	if b.CI.Enabled() {
+		b.report().Detail(i18n.T("initializer.copied_ci", b.CI.Layer(), b.CI.DefaultBranch))
+	}
+
+	for _, conflict := range b.conflicts {
+		b.report().Warn(WarnMergeConflict, i18n.T("initializer.merge_conflict",
+			conflict.Path, conflict.Reason, conflict.SideFile))
 	}
-	fmt.Printf("  - 已复制全局配置文件: %s\n", b.GlobalConfigPath)
 
-	fmt.Println("  ✅ 模板文件复制完成")
+	b.report().Success(i18n.T("initializer.copied"))
 	return nil
 }
 
@@ -99,38 +210,40 @@ func (b *BaseInitializer) InitDependencies() error {
 }
 
 // InstallGitHooks 安装 Git 钩子的基础实现
+// 钩子目录按 core.hooksPath、worktree、submodule 解析，已有钩子会被串联而不是覆盖，重复执行结果不变
+// 启用的钩子取自目标仓库的配置
 func (b *BaseInitializer) InstallGitHooks() error {
 	if b.NoCheck {
-		fmt.Println("⏭️  跳过Git钩子安装 (使用了--no-check参数)")
+		b.report().Progress("⏭️ ", i18n.T("initializer.skip_hooks"))
 		return nil
 	}
 
-	fmt.Println("🔗 安装Git钩子...")
+	b.report().Progress("🔗", i18n.T("initializer.installing_hooks"))
 
-	hookScript := filepath.Join(b.FilePath, ".git-hooks", "install-hooks.sh")
-
-	// 检查脚本是否存在
-	if _, err := os.Stat(hookScript); os.IsNotExist(err) {
-		fmt.Println("  ⚠️  Git钩子脚本不存在，跳过安装")
-		return nil
+	repoSettings, err := ReadSettings(b.FilePath)
+	if err != nil {
+		return WithDefaultCode(ErrConfig, err)
 	}
-
-	// 获取绝对路径
-	absHookScript, err := filepath.Abs(hookScript)
+	hooksDir, err := resolveHooksDir(b.FilePath)
 	if err != nil {
-		return fmt.Errorf("获取脚本绝对路径失败: %w", err)
+		return i18n.Errorf("initializer.hooks_dir_failed", err)
+	}
+	if err := b.mkdirAll(hooksDir); err != nil {
+		return i18n.Errorf("initializer.hooks_mkdir_failed", err)
 	}
 
-	cmd := exec.Command("bash", absHookScript)
-	cmd.Dir = b.FilePath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("安装Git钩子失败: %w", err)
+	for _, hook := range enabledGitHooks(repoSettings) {
+		if err := b.installHook(hooksDir, hook); err != nil {
+			return i18n.Errorf("initializer.hook_failed", hook.Name, err)
+		}
+	}
+	if b.plan != nil {
+		return nil
 	}
 
-	fmt.Println("  ✅ Git钩子安装成功")
+	checkHookDependencies(b.report())
+	b.report().Detail(i18n.T("initializer.hooks_dir", hooksDir))
+	b.report().Success(i18n.T("initializer.hooks_installed"))
 	return nil
 }
 
@@ -144,112 +257,150 @@ func (b *BaseInitializer) ConfigureCodeReview() error {
 
 // ShowNextSteps 显示后续步骤的基础实现
 func (b *BaseInitializer) ShowNextSteps() {
-	fmt.Println("\n✨ 项目创建成功！")
-	fmt.Printf("进入项目目录：cd %s\n", b.ProjectName)
+	b.report().Info("\n✨ " + i18n.T("initializer.created"))
+	b.report().Info(i18n.T("initializer.enter_dir", b.ProjectName))
 }
 
-// copyDir 递归复制目录
-func copyDir(src, dst string) error {
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
+// writeFile 写入文件的统一入口，预演模式下只记录变更，note 为预演计划中的说明
+func (b *BaseInitializer) writeFile(dst string, content []byte, mode os.FileMode, note string) error {
+	if b.plan != nil {
+		return b.plan.RecordFile(dst, content, note)
 	}
 
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			if err := os.MkdirAll(dstPath, 0755); err != nil {
-				return err
-			}
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
+	change := ChangeCreate
+	if _, err := os.Stat(dst); err == nil {
+		change = ChangeOverwrite
+	}
+	write := func() error {
+		if err := os.WriteFile(dst, content, mode); err != nil {
+			return err
 		}
+
+		// 保持文件权限（WriteFile 不会修改已存在文件的权限）
+		return os.Chmod(dst, mode)
 	}
 
+	var err error
+	if b.journal != nil {
+		err = b.journal.Write(dst, write)
+	} else {
+		err = write()
+	}
+	if err != nil {
+		return WithCode(ErrIO, err)
+	}
+	b.written = append(b.written, dst)
+	b.report().FileWritten(b.relPath(dst), change, note)
 	return nil
 }
 
-// copyFile 复制单个文件
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
+// relPath 获取相对于项目目录的路径，用于输出
+func (b *BaseInitializer) relPath(path string) string {
+	if rel, err := filepath.Rel(b.FilePath, path); err == nil {
+		return rel
 	}
-	defer in.Close()
+	return path
+}
 
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
+// mkdirAll 创建目录，预演模式下不创建
+func (b *BaseInitializer) mkdirAll(dir string) error {
+	if b.plan != nil {
+		return nil
+	}
+	if b.journal != nil {
+		if err := b.journal.TrackDir(dir); err != nil {
+			return err
+		}
 	}
-	defer out.Close()
+	return WithCode(ErrIO, os.MkdirAll(dir, 0755))
+}
 
-	_, err = io.Copy(out, in)
+// copyTemplates 把叠加后的模板文件复制到项目目录
+func (b *BaseInitializer) copyTemplates() error {
+	files, err := b.templateFiles()
 	if err != nil {
 		return err
 	}
 
-	// 保持文件权限
-	si, err := os.Stat(src)
-	if err != nil {
-		return err
+	for _, file := range files {
+		dst := filepath.Join(b.FilePath, filepath.FromSlash(file.Path))
+		if err := b.mkdirAll(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		if err := b.copyFile(dst, file.Content, file.Mode); err != nil {
+			return err
+		}
 	}
-	err = os.Chmod(dst, si.Mode())
-	if err != nil {
+	return nil
+}
+
+// copyFile 把模板内容写入项目文件，已存在且内容不同的文件会尝试合并
+func (b *BaseInitializer) copyFile(dst string, content []byte, mode os.FileMode) error {
+	written := content
+	existing, err := os.ReadFile(dst)
+	if err == nil && !bytes.Equal(existing, content) {
+		if written, err = b.mergeFile(dst, existing, content, mode); err != nil {
+			return err
+		}
+	} else if err := b.writeFile(dst, content, mode, ""); err != nil {
 		return err
 	}
 
+	// 冲突的文件不写入清单，升级时会重新尝试合并
+	if written != nil {
+		b.recordManifest(dst, written, content)
+	}
 	return nil
 }
 
-// getTemplatePath 获取模板路径，优先使用二进制文件所在目录，然后尝试当前目录
-func getTemplatePath(templateName string) (string, error) {
-	// 获取当前执行文件的路径
-	execPath, err := os.Executable()
-	if err == nil {
-		execDir := filepath.Dir(execPath)
-
-		// 尝试从二进制文件同级目录查找template
-		templatePath := filepath.Join(execDir, "template", templateName)
-		if _, err := os.Stat(templatePath); err == nil {
-			return templatePath, nil
-		}
-
-		// 尝试从二进制文件上级目录查找template（开发环境）
-		templatePath = filepath.Join(execDir, "..", "template", templateName)
-		if _, err := os.Stat(templatePath); err == nil {
-			absPath, _ := filepath.Abs(templatePath)
-			return absPath, nil
+// mergeFile 把模板内容合并到项目中已存在的文件，返回项目中文件的最终内容
+// 无法安全合并时保留原文件，把模板内容写入 .devex-new 旁路文件并记录冲突，返回 nil
+func (b *BaseInitializer) mergeFile(dst string, existing, content []byte, mode os.FileMode) ([]byte, error) {
+	reason := i18n.T("initializer.no_merge_strategy")
+	if merge, ok := mergeStrategies[filepath.Base(dst)]; ok {
+		merged, err := merge(existing, content)
+		if err == nil {
+			return merged, b.writeFile(dst, merged, mode, i18n.T("initializer.note_merged"))
 		}
+		reason = err.Error()
 	}
 
-	// 尝试从当前工作目录查找template（开发环境）
-	currentDir, err := os.Getwd()
-	if err == nil {
-		templatePath := filepath.Join(currentDir, "template", templateName)
-		if _, err := os.Stat(templatePath); err == nil {
-			return templatePath, nil
-		}
+	return nil, b.writeConflict(dst, content, mode, reason)
+}
+
+// writeConflict 保留项目中的原文件，把模板内容写入 .devex-new 旁路文件并记录冲突
+func (b *BaseInitializer) writeConflict(dst string, content []byte, mode os.FileMode, reason string) error {
+	sideFile := dst + conflictSuffix
+	b.conflicts = append(b.conflicts, FileConflict{Path: dst, SideFile: sideFile, Reason: reason})
+	return b.writeFile(sideFile, content, mode, i18n.T("initializer.note_conflict", reason))
+}
+
+// recordManifest 在清单中记录项目文件的最终内容及对应的模板内容
+func (b *BaseInitializer) recordManifest(dst string, written, template []byte) {
+	if b.manifest == nil {
+		return
+	}
+	rel, err := filepath.Rel(b.FilePath, dst)
+	if err != nil {
+		return
 	}
+	b.manifest.Record(filepath.ToSlash(rel), written, template)
+}
 
-	// 尝试相对路径（向上查找）
-	for i := 0; i < 3; i++ {
-		prefix := ""
-		for j := 0; j < i; j++ {
-			prefix = filepath.Join(prefix, "..")
-		}
-		templatePath := filepath.Join(prefix, "template", templateName)
-		if _, err := os.Stat(templatePath); err == nil {
-			absPath, _ := filepath.Abs(templatePath)
-			return absPath, nil
-		}
+// writeManifest 写入 .devex.lock 清单
+func (b *BaseInitializer) writeManifest() error {
+	data, err := b.manifest.Encode()
+	if err != nil {
+		return err
 	}
+	return b.writeFile(filepath.Join(b.FilePath, ManifestFile), data, 0644, i18n.T("initializer.note_manifest"))
+}
 
-	return "", fmt.Errorf("未找到模板目录: %s", templateName)
+// templateFileMode 计算模板文件写入磁盘时的权限
+// 内置模板（embed.FS）不保留可执行位，因此带 shebang 的脚本也视为可执行文件
+func templateFileMode(info fs.FileInfo, content []byte) os.FileMode {
+	if info.Mode().Perm()&0111 != 0 || bytes.HasPrefix(content, []byte("#!")) {
+		return 0755
+	}
+	return 0644
 }
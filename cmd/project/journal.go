@@ -0,0 +1,175 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"devex/cmd/i18n"
+)
+
+// journalEntry 记录某个路径被修改前的状态
+type journalEntry struct {
+	path    string
+	existed bool
+	isDir   bool
+	content []byte
+	mode    os.FileMode
+}
+
+// Journal 变更日志，记录步骤执行过程中创建或修改的文件、目录和钩子，
+// 失败或中断时按相反顺序恢复原始内容和权限
+type Journal struct {
+	mu         sync.Mutex
+	entries    []journalEntry
+	tracked    map[string]bool
+	rolledBack bool
+}
+
+// NewJournal 创建变更日志
+func NewJournal() *Journal {
+	return &Journal{tracked: make(map[string]bool)}
+}
+
+// track 在修改文件前记录其原始状态，同一路径只记录第一次（调用方需持有锁）
+func (j *Journal) track(path string) error {
+	if j.rolledBack {
+		return i18n.Errorf("journal.rolled_back", path)
+	}
+	if j.tracked[path] {
+		return nil
+	}
+
+	entry := journalEntry{path: path}
+	info, err := os.Lstat(path)
+	switch {
+	case err == nil && info.IsDir():
+		entry.existed = true
+		entry.isDir = true
+	case err == nil:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return i18n.Errorf("journal.record_content", err)
+		}
+		entry.existed = true
+		entry.content = content
+		entry.mode = info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return i18n.Errorf("journal.record_state", err)
+	}
+
+	j.tracked[path] = true
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+// TrackDir 在创建目录前记录，回滚时删除本次新建的目录
+func (j *Journal) TrackDir(dir string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// 从最上层不存在的目录开始记录，保证回滚时能完整删除
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if j.rolledBack {
+		return i18n.Errorf("journal.rolled_back", dir)
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if j.tracked[missing[i]] {
+			continue
+		}
+		j.tracked[missing[i]] = true
+		j.entries = append(j.entries, journalEntry{path: missing[i], isDir: true})
+	}
+	return nil
+}
+
+// Write 在日志锁内记录并执行一次修改，保证回滚开始后不会再有新的写入
+func (j *Journal) Write(path string, write func() error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.track(path); err != nil {
+		return err
+	}
+	return write()
+}
+
+// Rollback 按相反顺序恢复所有记录的路径，之后日志拒绝新的修改
+func (j *Journal) Rollback() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.rolledBack {
+		return nil
+	}
+	j.rolledBack = true
+
+	var errs []error
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if err := restoreEntry(j.entries[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Changed 获取内容或权限与记录时不同的文件（包括新建的文件），按记录顺序
+func (j *Journal) Changed() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var changed []string
+	for _, entry := range j.entries {
+		if entry.isDir {
+			continue
+		}
+		info, err := os.Lstat(entry.path)
+		if err != nil {
+			if entry.existed {
+				changed = append(changed, entry.path)
+			}
+			continue
+		}
+		content, err := os.ReadFile(entry.path)
+		if !entry.existed || err != nil || !bytes.Equal(content, entry.content) || info.Mode().Perm() != entry.mode {
+			changed = append(changed, entry.path)
+		}
+	}
+	return changed
+}
+
+func restoreEntry(entry journalEntry) error {
+	switch {
+	case entry.isDir && !entry.existed:
+		return os.RemoveAll(entry.path)
+	case entry.isDir:
+		return nil
+	case !entry.existed:
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return i18n.Errorf("journal.remove_failed", entry.path, err)
+		}
+		return nil
+	default:
+		if err := os.MkdirAll(filepath.Dir(entry.path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(entry.path, entry.content, entry.mode); err != nil {
+			return i18n.Errorf("journal.restore_failed", entry.path, err)
+		}
+		if err := os.Chmod(entry.path, entry.mode); err != nil {
+			return i18n.Errorf("journal.restore_mode_failed", entry.path, err)
+		}
+		return nil
+	}
+}
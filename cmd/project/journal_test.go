@@ -0,0 +1,163 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// journalWrite 通过日志写入文件
+func journalWrite(t *testing.T, j *Journal, path, content string, mode os.FileMode) {
+	t.Helper()
+	err := j.Write(path, func() error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			return err
+		}
+		return os.Chmod(path, mode)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJournalRollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, dir string)             // 执行步骤前已有的内容
+		change func(t *testing.T, j *Journal, dir string) // 步骤中的修改
+		check  func(t *testing.T, dir string)             // 回滚后的状态
+	}{
+		{
+			name: "restores overwritten file and mode",
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "hook"), []byte("original\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			change: func(t *testing.T, j *Journal, dir string) {
+				journalWrite(t, j, filepath.Join(dir, "hook"), "first\n", 0755)
+				journalWrite(t, j, filepath.Join(dir, "hook"), "second\n", 0644)
+			},
+			check: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "hook")
+				if got, _ := os.ReadFile(path); string(got) != "original\n" {
+					t.Errorf("content = %q", got)
+				}
+				if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+					t.Errorf("mode = %v, %v", info.Mode().Perm(), err)
+				}
+			},
+		},
+		{
+			name: "restores deleted file",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "sub", "keep.txt"), "keep\n")
+			},
+			change: func(t *testing.T, j *Journal, dir string) {
+				if err := j.Write(filepath.Join(dir, "sub", "keep.txt"), func() error {
+					return os.RemoveAll(filepath.Join(dir, "sub"))
+				}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, dir string) {
+				if got, _ := os.ReadFile(filepath.Join(dir, "sub", "keep.txt")); string(got) != "keep\n" {
+					t.Errorf("content = %q", got)
+				}
+			},
+		},
+		{
+			name: "removes created files and directories",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "existing", "a.txt"), "a\n")
+			},
+			change: func(t *testing.T, j *Journal, dir string) {
+				nested := filepath.Join(dir, "new", "nested", "deep")
+				if err := j.TrackDir(nested); err != nil {
+					t.Fatal(err)
+				}
+				mkdir(t, nested)
+				journalWrite(t, j, filepath.Join(nested, "file.txt"), "x\n", 0644)
+				journalWrite(t, j, filepath.Join(dir, "existing", "b.txt"), "b\n", 0644)
+				// 已存在的目录只记录不删除
+				if err := j.TrackDir(filepath.Join(dir, "existing")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, dir string) {
+				if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+					t.Errorf("created directory still exists: %v", err)
+				}
+				if _, err := os.Stat(filepath.Join(dir, "existing", "b.txt")); !os.IsNotExist(err) {
+					t.Errorf("created file still exists: %v", err)
+				}
+				if got, _ := os.ReadFile(filepath.Join(dir, "existing", "a.txt")); string(got) != "a\n" {
+					t.Errorf("existing file changed: %q", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+			j := NewJournal()
+			tt.change(t, j, dir)
+			if err := j.Rollback(); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, dir)
+		})
+	}
+}
+
+func TestJournalRollbackIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	j := NewJournal()
+	journalWrite(t, j, path, "devex\n", 0644)
+
+	if err := j.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	// 回滚之后的修改不属于本次执行，再次回滚不能影响它
+	writeTestFile(t, path, "user\n")
+	if err := j.Rollback(); err != nil {
+		t.Fatalf("second rollback: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "user\n" {
+		t.Errorf("second rollback changed the file: %q", got)
+	}
+
+	// 回滚开始后拒绝新的修改
+	wrote := false
+	if err := j.Write(path, func() error { wrote = true; return nil }); err == nil || wrote {
+		t.Errorf("write after rollback: err = %v, wrote = %v", err, wrote)
+	}
+	if err := j.TrackDir(filepath.Join(dir, "new")); err == nil {
+		t.Error("TrackDir after rollback succeeded")
+	}
+}
+
+func TestJournalChanged(t *testing.T) {
+	dir := t.TempDir()
+	same := filepath.Join(dir, "same.txt")
+	edited := filepath.Join(dir, "edited.txt")
+	created := filepath.Join(dir, "created.txt")
+	writeTestFile(t, same, "same\n")
+	writeTestFile(t, edited, "old\n")
+
+	j := NewJournal()
+	journalWrite(t, j, same, "same\n", 0644)
+	journalWrite(t, j, edited, "new\n", 0644)
+	journalWrite(t, j, created, "new\n", 0644)
+
+	want := []string{edited, created}
+	if got := j.Changed(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed() = %v, want %v", got, want)
+	}
+}
@@ -1,8 +1,13 @@
 package project
 
 import (
-	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // KotlinInitializer Kotlin项目初始化器
@@ -12,10 +17,13 @@ type KotlinInitializer struct {
 	templates        TemplateManager
 	dependencyHelper DependencyChecker
 	config           *LanguageConfig
+	templateManifest *TemplateManifest // 模板清单 template.yaml
+	vars             map[string]string // 模板变量取值，如 PackageName、Android
 }
 
 // NewKotlinInitializer 创建Kotlin项目初始化器
 // 参数说明：
+// - templateFS: 模板根文件系统
 // - projectName: 项目名称
 // - projectPath: 项目路径
 // - globalConfigPath: 全局配置路径
@@ -24,8 +32,8 @@ type KotlinInitializer struct {
 // - noGit: 是否跳过Git初始化
 // - noCheck: 是否跳过代码检查工具
 // - remote: 远程仓库地址
-func NewKotlinInitializer(projectName, projectPath, globalConfigPath, configPath, templateCodePath string, noGit, noCheck bool, remote string) *KotlinInitializer {
-	templateManager := NewFileTemplateManager(templateCodePath)
+func NewKotlinInitializer(templateFS fs.FS, projectName, projectPath, globalConfigPath, configPath, templateCodePath string, noGit, noCheck bool, remote string) *KotlinInitializer {
+	templateManager := NewFileTemplateManager(templateFS, templateCodePath)
 	dependencyChecker := NewCommandDependencyChecker()
 
 	// 获取Kotlin配置
@@ -33,9 +41,11 @@ func NewKotlinInitializer(projectName, projectPath, globalConfigPath, configPath
 
 	return &KotlinInitializer{
 		BaseInitializer: BaseInitializer{
+			TemplateFS:       templateFS,
 			ProjectName:      projectName,
 			FilePath:         projectPath,
 			GlobalConfigPath: globalConfigPath,
+			ConfigLayers:     []string{configPath},
 			ConfigPath:       configPath,
 			TemplateCodePath: templateCodePath,
 			NoGit:            noGit,
@@ -48,111 +58,159 @@ func NewKotlinInitializer(projectName, projectPath, globalConfigPath, configPath
 	}
 }
 
+// CopyTemplateFiles 复制Kotlin项目模板文件并生成项目文件
+// 生成过程只写文件、不调用 Gradle，输出目录可以直接与预期的目录树比较
+func (k *KotlinInitializer) CopyTemplateFiles() error {
+	// 先调用父类的方法复制全局配置和 Kotlin 配置文件（ktlint、detekt）
+	if err := k.BaseInitializer.CopyTemplateFiles(); err != nil {
+		return err
+	}
+
+	return k.createProjectFiles()
+}
+
+// createProjectFiles 根据 template.yaml 生成 Gradle 配置和源代码
+func (k *KotlinInitializer) createProjectFiles() error {
+	if err := k.loadTemplate(); err != nil {
+		return err
+	}
+	return k.generateProjectFiles(k.templates, k.templateManifest, k.vars)
+}
+
+// SetTemplateValues 设置模板变量的取值，并立即确定所有变量，以便在执行任何步骤前完成输入和校验
+func (k *KotlinInitializer) SetTemplateValues(values map[string]string, prompter *Prompter) error {
+	if err := k.BaseInitializer.SetTemplateValues(values, prompter); err != nil {
+		return err
+	}
+	k.templateManifest = nil
+	return k.loadTemplate()
+}
+
+// loadTemplate 读取模板清单并确定变量取值
+func (k *KotlinInitializer) loadTemplate() error {
+	if k.templateManifest != nil {
+		return nil
+	}
+
+	// build.gradle.kts 模板根据 CodeReview 变量配置 ktlint 和 detekt，默认由 --no-check 决定，--set 指定时以指定的值为准
+	if _, ok := k.templateValues["CodeReview"]; !ok {
+		values := map[string]string{"CodeReview": strconv.FormatBool(!k.NoCheck)}
+		for name, value := range k.templateValues {
+			values[name] = value
+		}
+		k.templateValues = values
+	}
+
+	manifest, vars, err := k.resolveTemplate()
+	if err != nil {
+		return err
+	}
+	k.templateManifest, k.vars = manifest, vars
+	return nil
+}
+
+// isAndroid 是否生成 Android 应用
+func (k *KotlinInitializer) isAndroid() bool {
+	return k.vars["Android"] == "true"
+}
+
 // CloneRepository 克隆远程仓库
 func (k *KotlinInitializer) CloneRepository() error {
-	// TODO: 实现 Kotlin 项目的仓库克隆逻辑
 	return k.BaseInitializer.CloneRepository()
 }
 
-// CreateProject 创建Kotlin项目特定文件
-// 🔧 实现要求：
-// 1. 检查依赖（调用checkDependencies）
-// 2. 创建Kotlin项目结构
-// 3. 生成Gradle配置文件（build.gradle.kts）
-// 4. 创建src/main/kotlin目录结构
-// 5. 生成MainActivity.kt等核心文件
-// 6. 配置AndroidManifest.xml（如果是Android项目）
+// CreateProject 创建Kotlin项目
+// 项目文件已在复制模板时生成，这里执行模板清单中的生成后命令
+// Gradle 和 Java 只有生成后命令需要，缺少时跳过命令并提示安装后手动执行，不影响已生成的项目文件
 func (k *KotlinInitializer) CreateProject() error {
-	fmt.Println("🔨 创建Kotlin项目...")
+	k.report().Progress("🔨", i18n.T("kotlin.creating"))
 
-	// TODO: 检查依赖
-	if err := k.checkDependencies(); err != nil {
+	if err := k.loadTemplate(); err != nil {
+		return err
+	}
+	commands, err := k.templateManifest.postGenerateCommands(k.vars)
+	if err != nil {
 		return err
 	}
 
-	// TODO: 创建项目目录结构
-	// TODO: 创建核心文件
-
-	fmt.Println("  📁 创建项目目录结构...")
-	// 实现项目目录创建逻辑
-
-	fmt.Println("  📄 生成项目文件...")
-	// 实现项目文件生成逻辑，使用 k.templates.RenderTemplateCode()
+	// 执行 template.yaml 中的生成后命令（gradle wrapper），生成 gradlew 和 wrapper jar
+	if len(commands) > 0 {
+		if missing := k.checkDependencies(); len(missing) > 0 {
+			runs := make([]string, len(commands))
+			for i, command := range commands {
+				runs[i] = command.Run
+			}
+			k.report().Hint(i18n.T("kotlin.skip_post_generate", strings.Join(runs, " && ")))
+		} else if err := k.runPostGenerate(commands); err != nil {
+			return i18n.Errorf("kotlin.wrapper_failed", err)
+		}
+	}
 
-	fmt.Println("  ✅ Kotlin项目创建成功")
-	return fmt.Errorf("❌ CreateProject方法需要实现 - 请参考swift.go中的createProjectFiles方法")
+	k.report().Success(i18n.T("kotlin.created"))
+	return nil
 }
 
-// checkDependencies 检查Kotlin项目依赖
-func (k *KotlinInitializer) checkDependencies() error {
-	fmt.Println("🔍 检查Kotlin依赖...")
+// checkDependencies 检查生成后命令需要的工具，返回缺少的命令
+func (k *KotlinInitializer) checkDependencies() []string {
+	k.report().Progress("🔍", i18n.T("kotlin.checking_deps"))
 
 	missing := k.dependencyHelper.GetMissingDependencies(k.config.RequiredCommands)
 
 	if len(missing) == 0 {
-		fmt.Println("  ✅ 所有依赖都已安装")
+		k.report().Success(i18n.T("initializer.deps_installed"))
 		return nil
 	}
 
-	fmt.Printf("  ⚠️  缺少依赖: %s\n", strings.Join(missing, ", "))
+	k.report().Warn(WarnDependencyMissing, i18n.T("initializer.deps_missing", strings.Join(missing, ", ")))
 
-	// TODO: 为每个缺失的依赖提供安装说明
 	for _, cmd := range missing {
-		fmt.Printf("  💡 %s\n", GetInstallationInstructions(cmd))
-	}
-
-	// TODO: 可选：尝试自动安装某些依赖（参考Swift的xcodegen自动安装）
-
-	return fmt.Errorf("请先安装缺失的依赖: %s", strings.Join(missing, ", "))
-}
-
-// CopyTemplateFiles 复制Kotlin项目模板文件
-func (k *KotlinInitializer) CopyTemplateFiles() error {
-	fmt.Println("📂 复制Kotlin模板文件...")
-
-	// 先调用父类方法复制基础配置
-	if err := k.BaseInitializer.CopyTemplateFiles(); err != nil {
-		return err
+		k.report().Hint(GetInstallationInstructions(cmd))
 	}
 
-	fmt.Println("  ✅ Kotlin模板文件复制完成")
-	return fmt.Errorf("❌ CopyTemplateFiles方法需要实现 - 请参考swift.go中的实现")
+	return missing
 }
 
 // InitDependencies 初始化Kotlin项目依赖
+// 依赖由 Gradle 在首次构建时下载，这里只给出代码审查工具说明
 func (k *KotlinInitializer) InitDependencies() error {
-	fmt.Println("📦 初始化Kotlin依赖...")
+	if !k.NoCheck {
+		k.report().Progress("💡", i18n.T("initializer.review_tools"))
+		k.report().Detail(i18n.T("kotlin.tool_ktlint"))
+		k.report().Detail(i18n.T("kotlin.tool_detekt"))
+		k.report().Detail(i18n.T("initializer.tool_gitleaks"))
+	}
 
-	fmt.Println("  ✅ 依赖初始化完成")
 	return nil
 }
 
 // ConfigureCodeReview 配置Kotlin项目的代码审查工具
-// 🔧 实现要求：
-// 1. 配置ktlint（Kotlin代码格式化）
-// 2. 配置detekt（静态代码分析）
-// 3. 设置Android Lint（如果是Android项目）
-// 4. 集成到Gradle构建脚本
+// ktlint 和 detekt 插件由 build.gradle.kts 模板根据 CodeReview 变量生成，这里检查配置文件是否齐全
 func (k *KotlinInitializer) ConfigureCodeReview() error {
 	if k.NoCheck {
-		fmt.Println("⏭️  跳过代码审查配置 (使用了--no-check参数)")
+		k.report().Progress("⏭️ ", i18n.T("add_initializer.skip_review"))
 		return nil
 	}
 
-	fmt.Println("🔍 配置Kotlin代码审查工具...")
-
-	// TODO: 配置ktlint
-	fmt.Println("  - 配置ktlint (Kotlin代码格式化)")
-	fmt.Println("    提示：在build.gradle.kts中添加ktlint插件")
+	k.report().Progress("🔍", i18n.T("kotlin.configuring"))
 
-	// TODO: 配置detekt
-	fmt.Println("  - 配置detekt (静态代码分析)")
-	fmt.Println("    提示：添加detekt Gradle插件和配置文件")
+	// 检查 ktlint 和 detekt 的配置文件是否存在
+	for _, name := range []string{".editorconfig", filepath.Join("config", "detekt", "detekt.yml")} {
+		if _, err := os.Stat(filepath.Join(k.FilePath, name)); os.IsNotExist(err) {
+			k.report().Warn(WarnFileMissing, i18n.T("kotlin.config_missing", name))
+			k.report().Hint(i18n.T("initializer.config_hint"))
+		} else {
+			k.report().Success(i18n.T("kotlin.config_exists", name))
+		}
+	}
 
-	// TODO: 配置Android Lint（如果适用）
-	fmt.Println("  - 配置Android Lint")
+	if err := k.loadTemplate(); err != nil {
+		return err
+	}
+	if k.vars["CodeReview"] == "true" {
+		k.report().Success(i18n.T("kotlin.gradle_configured"))
+	}
 
-	fmt.Println("  ✅ 代码审查工具配置完成")
+	k.report().Success(i18n.T("initializer.review_done"))
 	return nil
 }
 
@@ -160,9 +218,13 @@ func (k *KotlinInitializer) ConfigureCodeReview() error {
 func (k *KotlinInitializer) ShowNextSteps() {
 	k.BaseInitializer.ShowNextSteps()
 
-	fmt.Println("\n📋 Kotlin项目后续步骤：")
-
-	// TODO: 添加Kotlin特定的后续步骤
-	fmt.Println("1. 构建项目：./gradlew build")
-	fmt.Println("2. 运行测试：./gradlew test")
+	k.report().Info("\n📋 " + i18n.T("kotlin.next"))
+	k.report().Info(i18n.T("kotlin.next_1"))
+	k.report().Info(i18n.T("kotlin.next_2"))
+	if !k.NoCheck {
+		k.report().Info(i18n.T("kotlin.next_3"))
+	}
+	if k.isAndroid() {
+		k.report().Progress("💡", i18n.T("kotlin.android_sdk"))
+	}
 }
@@ -0,0 +1,132 @@
+package project
+
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	devextemplate "devex/template"
+)
+
+var update = flag.Bool("update", false, "用生成结果更新 testdata 中的预期文件")
+
+// TestKotlinProjectTree 生成的 Kotlin 项目文件与 testdata/kotlin 下的预期目录树一致
+// 生成过程不调用 Gradle，模板修改后执行 go test ./cmd/project -run TestKotlinProjectTree -update 更新预期文件
+func TestKotlinProjectTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		noCheck bool
+	}{
+		{name: "android", values: map[string]string{"PackageName": "com.example.demo"}},
+		{name: "jvm", values: map[string]string{"PackageName": "com.example.demo", "Android": "false"}},
+		{name: "android-no-check", values: map[string]string{"PackageName": "com.example.demo"}, noCheck: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := GetLanguageConfig("kotlin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			k := NewKotlinInitializer(devextemplate.FS, "Demo", dir, config.GlobalConfigPath,
+				config.ConfigPath, config.TemplateCodePath, true, tt.noCheck, "")
+			k.reporter = NewHumanReporter(io.Discard)
+
+			if err := k.SetTemplateValues(tt.values, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := k.createProjectFiles(); err != nil {
+				t.Fatal(err)
+			}
+			if err := k.ConfigureCodeReview(); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "kotlin", tt.name)
+			if *update {
+				if err := os.RemoveAll(golden); err != nil {
+					t.Fatal(err)
+				}
+				for path, content := range readTree(t, dir) {
+					writeTestFile(t, filepath.Join(golden, filepath.FromSlash(path)), content)
+				}
+			}
+			compareTrees(t, golden, dir)
+		})
+	}
+}
+
+// compareTrees 比较两个目录树中的文件路径和内容
+func compareTrees(t *testing.T, want, got string) {
+	t.Helper()
+	wantFiles, gotFiles := readTree(t, want), readTree(t, got)
+	for _, path := range sortedKeys(wantFiles) {
+		content, ok := gotFiles[path]
+		switch {
+		case !ok:
+			t.Errorf("missing file %s", path)
+		case content != wantFiles[path]:
+			t.Errorf("%s differs:\n--- want\n%s\n--- got\n%s", path, wantFiles[path], content)
+		}
+	}
+	for _, path := range sortedKeys(gotFiles) {
+		if _, ok := wantFiles[path]; !ok {
+			t.Errorf("unexpected file %s", path)
+		}
+	}
+}
+
+// readTree 读取目录中的所有文件，键为以 / 分隔的相对路径
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := fs.WalkDir(os.DirFS(root), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(os.DirFS(root), path)
+		files[path] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestKotlinCreateProjectWithoutGradle 缺少 Gradle 和 Java 时跳过 gradle wrapper，不影响已生成的项目文件
+func TestKotlinCreateProjectWithoutGradle(t *testing.T) {
+	config, err := GetLanguageConfig("kotlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", t.TempDir())
+
+	dir := t.TempDir()
+	k := NewKotlinInitializer(devextemplate.FS, "Demo", dir, config.GlobalConfigPath,
+		config.ConfigPath, config.TemplateCodePath, true, false, "")
+	k.reporter = NewHumanReporter(io.Discard)
+	if err := k.createProjectFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.CreateProject(); err != nil {
+		t.Fatalf("CreateProject without gradle: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gradlew")); !os.IsNotExist(err) {
+		t.Errorf("gradlew should not be generated, stat error: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package project
+
+import (
+	"testing"
+
+	"devex/cmd/i18n"
+)
+
+func TestJoinLanguageNames(t *testing.T) {
+	tests := []struct {
+		locale    string
+		languages []string
+		want      string
+	}{
+		{locale: "en", languages: nil, want: "none"},
+		{locale: "en", languages: []string{"swift"}, want: "swift"},
+		{locale: "en", languages: []string{"swift", "kotlin"}, want: "swift and kotlin"},
+		{locale: "en", languages: []string{"swift", "kotlin", "flutter"}, want: "swift, kotlin and flutter"},
+		{locale: "zh-CN", languages: nil, want: "无"},
+		{locale: "zh-CN", languages: []string{"swift", "kotlin", "flutter"}, want: "swift、kotlin 和 flutter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.want, func(t *testing.T) {
+			old := i18n.Locale()
+			t.Cleanup(func() { i18n.SetLocale(old) })
+			if err := i18n.SetLocale(tt.locale); err != nil {
+				t.Fatal(err)
+			}
+			if got := joinLanguageNames(tt.languages); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewInitializerUnsupportedLanguage --lang 指定不支持的语言时报用法错误，并按名称顺序列出支持的语言
+func TestNewInitializerUnsupportedLanguage(t *testing.T) {
+	old := i18n.Locale()
+	t.Cleanup(func() { i18n.SetLocale(old) })
+	i18n.SetLocale(i18n.English)
+
+	tests := []struct {
+		command string
+		lang    string
+	}{
+		{command: "init", lang: "rust"},
+		{command: "add", lang: "rust"},
+		{command: "add", lang: "swift,rust"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command+"/"+tt.lang, func(t *testing.T) {
+			_, err := NewInitializer(tt.command, tt.lang, "demo", t.TempDir(), true, true, "")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if code := ErrorCodeOf(err); code != ErrUsage {
+				t.Errorf("code = %s, want %s", code, ErrUsage)
+			}
+			want := "unsupported language: rust. Supported languages: kotlin and swift"
+			if err.Error() != want {
+				t.Errorf("got %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package project
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// templateFile 叠加后的单个模板文件
+type templateFile struct {
+	Path    string // 相对于项目目录的 / 分隔路径
+	Content []byte
+	Mode    fs.FileMode
+}
+
+// collectTemplates 依次叠加多个模板目录，后面的目录覆盖前面的同名文件
+// 同名文件有合并策略（如 .gitignore）时合并而不是覆盖，结果按路径排序
+func collectTemplates(fsys fs.FS, dirs []string) ([]templateFile, error) {
+	files := make(map[string]templateFile)
+
+	for _, dir := range dirs {
+		err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			content, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			rel := strings.TrimPrefix(p, dir+"/")
+			file := templateFile{Path: rel, Content: content, Mode: templateFileMode(info, content)}
+			if prev, ok := files[rel]; ok {
+				if merge, ok := mergeStrategies[path.Base(rel)]; ok {
+					if merged, err := merge(prev.Content, content); err == nil {
+						file.Content = merged
+					}
+				}
+			}
+			files[rel] = file
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]templateFile, 0, len(files))
+	for _, file := range files {
+		result = append(result, file)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// templateLayers 返回全局配置及语言配置层的模板目录
+func (b *BaseInitializer) templateLayers() []string {
+	return append([]string{b.GlobalConfigPath}, b.ConfigLayers...)
+}
+
+// templateFiles 返回叠加后的模板文件，启用 CI 时包含渲染后的 CI 配置
+// 未指定 CI 的分支时检测项目的默认分支，结果保留在 CI 选项中以便写入清单
+func (b *BaseInitializer) templateFiles() ([]templateFile, error) {
+	files, err := collectTemplates(b.TemplateFS, b.templateLayers())
+	if err != nil || !b.CI.Enabled() {
+		return files, err
+	}
+
+	if b.CI.DefaultBranch == "" {
+		b.CI.DefaultBranch = DetectDefaultBranch(b.FilePath)
+	}
+	ciFiles, err := collectCITemplates(b.TemplateFS, b.CI, b.ConfigLayers)
+	if err != nil {
+		return nil, err
+	}
+	return mergeTemplateFiles(files, ciFiles), nil
+}
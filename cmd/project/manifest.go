@@ -0,0 +1,103 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"devex/cmd/i18n"
+)
+
+// ManifestFile 记录 devex 写入文件信息的清单文件名
+const ManifestFile = ".devex.lock"
+
+// Version 当前 devex 版本，写入清单文件
+var Version = "dev"
+
+// SetVersion 设置写入清单文件的 devex 版本
+func SetVersion(version string) {
+	Version = version
+}
+
+// Manifest .devex.lock 清单：记录写入文件的 devex 版本、模板来源和每个文件的校验和
+type Manifest struct {
+	Version string                   `json:"version"`
+	Source  string                   `json:"source"`
+	Layers  []string                 `json:"layers,omitempty"` // 叠加在全局配置之上的语言配置目录
+	CI      *CIOptions               `json:"ci,omitempty"`     // 生成的 CI 配置，未生成时为空
+	Files   map[string]ManifestEntry `json:"files"`
+}
+
+// ManifestEntry 清单中单个文件的记录
+type ManifestEntry struct {
+	Checksum string `json:"checksum"` // devex 处理后项目中文件内容的校验和
+	Template string `json:"template"` // 写入时的模板内容，升级时作为三方合并的基准
+}
+
+// NewManifest 创建当前版本的空清单
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: Version,
+		Source:  templateSource(),
+		Files:   make(map[string]ManifestEntry),
+	}
+}
+
+// LoadManifest 读取项目中的清单文件
+func LoadManifest(projectPath string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, i18n.Errorf("manifest.parse_failed", ManifestFile, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]ManifestEntry)
+	}
+	return &manifest, nil
+}
+
+// Record 记录一个文件，path 为相对于项目目录的 / 分隔路径
+func (m *Manifest) Record(path string, content, template []byte) {
+	m.Files[path] = ManifestEntry{
+		Checksum: checksum(content),
+		Template: string(template),
+	}
+}
+
+// Encode 编码清单内容
+func (m *Manifest) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// checksum 计算内容的 sha256 校验和
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// templateSource 描述当前使用的模板来源
+func templateSource() string {
+	if templateSourceSpec != "" {
+		if _, digest, err := resolveTemplateSource(); err == nil {
+			return resolvedTemplate.source + "@" + digest
+		}
+		return templateSourceSpec
+	}
+	if templateDir == "" {
+		return "builtin"
+	}
+	if abs, err := filepath.Abs(templateDir); err == nil {
+		return abs
+	}
+	return templateDir
+}
@@ -0,0 +1,207 @@
+package project
+
+import (
+	"bytes"
+	"strings"
+
+	"devex/cmd/gitleaks"
+	"devex/cmd/i18n"
+
+	"gopkg.in/yaml.v3"
+)
+
+// conflictSuffix 无法安全合并时，模板内容写入的旁路文件后缀
+const conflictSuffix = ".devex-new"
+
+// mergeFunc 把模板内容合并到项目中已有的文件内容
+type mergeFunc func(existing, incoming []byte) ([]byte, error)
+
+// mergeStrategies 按文件名注册的合并策略，未注册的文件在内容不同时视为冲突
+var mergeStrategies = map[string]mergeFunc{
+	".gitignore":              mergeGitignore,
+	".pre-commit-config.yaml": mergePreCommitConfig,
+	".gitleaks.toml":          mergeGitleaksConfig,
+}
+
+// mergeGitignore 按行取并集：保留项目原有内容，追加模板中缺少的规则
+func mergeGitignore(existing, incoming []byte) ([]byte, error) {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		seen[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(incoming), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || seen[trimmed] {
+			continue
+		}
+		seen[trimmed] = true
+		missing = append(missing, trimmed)
+	}
+
+	if len(missing) == 0 {
+		return existing, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n# Added by devex\n")
+	buf.WriteString(strings.Join(missing, "\n"))
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// mergePreCommitConfig 合并 pre-commit 配置的 repos 列表
+// 保留项目已有的仓库和钩子，只追加模板中缺少的仓库及钩子（按 repo 地址和钩子 id 判断）
+func mergePreCommitConfig(existing, incoming []byte) ([]byte, error) {
+	var userDoc, tplDoc yaml.Node
+	if err := yaml.Unmarshal(existing, &userDoc); err != nil {
+		return nil, i18n.Errorf("merge.parse_existing", err)
+	}
+	if err := yaml.Unmarshal(incoming, &tplDoc); err != nil {
+		return nil, i18n.Errorf("merge.parse_template", err)
+	}
+
+	userRoot, err := documentMapping(&userDoc)
+	if err != nil {
+		return nil, err
+	}
+	tplRoot, err := documentMapping(&tplDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	tplRepos := mappingValue(tplRoot, "repos")
+	if tplRepos == nil {
+		return existing, nil
+	}
+	changed := false
+	userRepos := mappingValue(userRoot, "repos")
+	if userRepos == nil {
+		userRoot.Content = append(userRoot.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "repos"}, tplRepos)
+		return encodeYAML(&userDoc)
+	}
+	if userRepos.Kind != yaml.SequenceNode || tplRepos.Kind != yaml.SequenceNode {
+		return nil, i18n.Errorf("merge.repos_not_list")
+	}
+
+	for _, tplRepo := range tplRepos.Content {
+		repoURL := scalarValue(mappingValue(tplRepo, "repo"))
+		userRepo := findByKey(userRepos, "repo", repoURL)
+		if userRepo == nil {
+			userRepos.Content = append(userRepos.Content, tplRepo)
+			changed = true
+			continue
+		}
+
+		tplHooks := mappingValue(tplRepo, "hooks")
+		if tplHooks == nil {
+			continue
+		}
+		userHooks := mappingValue(userRepo, "hooks")
+		if userHooks == nil {
+			userRepo.Content = append(userRepo.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "hooks"}, tplHooks)
+			changed = true
+			continue
+		}
+		if userHooks.Kind != yaml.SequenceNode {
+			return nil, i18n.Errorf("merge.hooks_not_list", repoURL)
+		}
+		for _, hook := range tplHooks.Content {
+			if findByKey(userHooks, "id", scalarValue(mappingValue(hook, "id"))) == nil {
+				userHooks.Content = append(userHooks.Content, hook)
+				changed = true
+			}
+		}
+	}
+
+	// 没有需要追加的内容时保持原文件不变，避免重新格式化
+	if !changed {
+		return existing, nil
+	}
+	return encodeYAML(&userDoc)
+}
+
+// documentMapping 获取 YAML 文档的根映射节点
+func documentMapping(doc *yaml.Node) (*yaml.Node, error) {
+	if doc.Kind == 0 {
+		// 空文档
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, i18n.Errorf("merge.root_not_mapping")
+	}
+	return doc.Content[0], nil
+}
+
+// mappingValue 获取映射节点中指定键的值
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.Value
+}
+
+// findByKey 在映射列表中查找指定键等于 value 的元素
+func findByKey(seq *yaml.Node, key, value string) *yaml.Node {
+	for _, item := range seq.Content {
+		if scalarValue(mappingValue(item, key)) == value {
+			return item
+		}
+	}
+	return nil
+}
+
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeGitleaksConfig 合并 gitleaks 配置：追加缺少的 [[rules]]，[allowlist] 各列表取并集
+// 合并结果在原文上局部修改，保留用户的注释和字段顺序
+func mergeGitleaksConfig(existing, incoming []byte) ([]byte, error) {
+	userCfg, err := gitleaks.Parse(existing)
+	if err != nil {
+		return nil, err
+	}
+	tplCfg, err := gitleaks.Parse(incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有需要合并的内容时保持原文件不变，避免重新格式化
+	before := userCfg.Encode()
+	userCfg.Merge(tplCfg)
+	after := userCfg.Encode()
+	if bytes.Equal(before, after) {
+		return existing, nil
+	}
+	return userCfg.Patch()
+}
@@ -0,0 +1,140 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeGitignore(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		incoming string
+		want     string
+	}{
+		{
+			name:     "appends missing rules",
+			existing: "node_modules\n",
+			incoming: "# comment\n.DS_Store\nnode_modules\n",
+			want:     "node_modules\n\n# Added by devex\n.DS_Store\n",
+		},
+		{
+			name:     "keeps file without trailing newline",
+			existing: "build",
+			incoming: "Pods\n",
+			want:     "build\n\n# Added by devex\nPods\n",
+		},
+		{
+			name:     "unchanged when all rules exist",
+			existing: "Pods\n  .DS_Store  \n",
+			incoming: ".DS_Store\nPods\n",
+			want:     "Pods\n  .DS_Store  \n",
+		},
+		{
+			name:     "empty existing file",
+			existing: "",
+			incoming: "Pods\nPods\n",
+			want:     "\n# Added by devex\nPods\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeGitignore([]byte(tt.existing), []byte(tt.incoming))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePreCommitConfig(t *testing.T) {
+	template := `repos:
+  - repo: local
+    hooks:
+      - id: gitleaks
+        entry: devex scan
+      - id: check-commit-message
+        entry: devex hook commit-msg
+`
+	tests := []struct {
+		name     string
+		existing string
+		contains []string
+		same     bool // 结果与原文件完全相同
+		wantErr  bool
+	}{
+		{
+			name: "adds missing hook to existing repo",
+			existing: `repos:
+  - repo: local
+    hooks:
+      - id: gitleaks
+        entry: custom-scan
+`,
+			contains: []string{"entry: custom-scan", "id: check-commit-message"},
+		},
+		{
+			name: "adds missing repo and keeps user repos",
+			existing: `# team hooks
+repos:
+  - repo: https://github.com/psf/black
+    rev: 24.1.0
+    hooks:
+      - id: black
+`,
+			contains: []string{"# team hooks", "id: black", "repo: local", "id: gitleaks"},
+		},
+		{
+			name:     "adds repos to config without repos",
+			existing: "default_stages: [pre-commit]\n",
+			contains: []string{"default_stages", "repo: local"},
+		},
+		{
+			name: "unchanged when everything exists",
+			existing: `repos:
+    - repo: local
+      hooks:
+          - id: gitleaks
+            entry: devex scan
+          - id: check-commit-message
+            entry: devex hook commit-msg
+`,
+			same: true,
+		},
+		{
+			name:     "repos is not a list",
+			existing: "repos: local\n",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid yaml",
+			existing: "repos: [\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergePreCommitConfig([]byte(tt.existing), []byte(template))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.same && string(got) != tt.existing {
+				t.Errorf("expected the file to be unchanged, got:\n%s", got)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(got), s) {
+					t.Errorf("result does not contain %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"devex/cmd/i18n"
+)
+
+// ChangeKind 预演模式下文件的变更类型
+type ChangeKind string
+
+const (
+	ChangeCreate    ChangeKind = "create"    // 新建文件
+	ChangeOverwrite ChangeKind = "overwrite" // 覆盖已有文件
+	ChangeModify    ChangeKind = "modify"    // 修改已有文件（如追加内容）
+	ChangeUnchanged ChangeKind = "unchanged" // 内容相同，保持不变
+)
+
+// PlannedChange 预演模式下记录的一次文件变更
+type PlannedChange struct {
+	Step string     `json:"step"`           // 所属步骤
+	Path string     `json:"path"`           // 相对于项目目录的路径
+	Kind ChangeKind `json:"kind"`           // 变更类型
+	Note string     `json:"note,omitempty"` // 补充说明
+	Diff string     `json:"diff,omitempty"` // 覆盖/修改时的统一差异
+}
+
+// Plan 预演计划，记录各步骤将要执行的文件变更而不实际写入
+type Plan struct {
+	root    string
+	step    string
+	Changes []PlannedChange
+}
+
+// NewPlan 创建预演计划，root 为项目目录
+func NewPlan(root string) *Plan {
+	return &Plan{root: root}
+}
+
+// BeginStep 标记后续变更所属的步骤
+func (p *Plan) BeginStep(name string) {
+	p.step = name
+}
+
+// RecordFile 记录一次文件写入，与磁盘上的现有内容比较得出变更类型
+func (p *Plan) RecordFile(path string, content []byte, note string) error {
+	kind := ChangeCreate
+	diff := ""
+
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(old, content):
+		kind = ChangeUnchanged
+	case err == nil:
+		kind = ChangeOverwrite
+		rel := p.relPath(path)
+		diff = unifiedDiff("a/"+rel, "b/"+rel, string(old), string(content))
+	case !os.IsNotExist(err):
+		return i18n.Errorf("plan.read_failed", path, err)
+	}
+
+	p.add(path, kind, note, diff)
+	return nil
+}
+
+// RecordChange 记录一次无法给出完整内容的变更（如由外部工具生成的文件）
+func (p *Plan) RecordChange(path string, kind ChangeKind, note string) {
+	p.add(path, kind, note, "")
+}
+
+func (p *Plan) add(path string, kind ChangeKind, note, diff string) {
+	p.Changes = append(p.Changes, PlannedChange{
+		Step: p.step,
+		Path: p.relPath(path),
+		Kind: kind,
+		Note: note,
+		Diff: diff,
+	})
+}
+
+// relPath 获取相对于项目目录的路径
+func (p *Plan) relPath(path string) string {
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// Print 输出预演计划
+func (p *Plan) Print(w io.Writer) {
+	labels := map[ChangeKind]string{
+		ChangeCreate:    i18n.T("plan.create"),
+		ChangeOverwrite: i18n.T("plan.overwrite"),
+		ChangeModify:    i18n.T("plan.modify"),
+		ChangeUnchanged: i18n.T("plan.unchanged"),
+	}
+
+	fmt.Fprintln(w, "\n📋 "+i18n.T("plan.title"))
+	step := ""
+	counts := map[ChangeKind]int{}
+	for _, change := range p.Changes {
+		if change.Step != step {
+			step = change.Step
+			fmt.Fprintf(w, "\n[%s]\n", step)
+		}
+		counts[change.Kind]++
+
+		line := i18n.T("plan.line", labels[change.Kind], change.Path)
+		if change.Note != "" {
+			line += "  (" + change.Note + ")"
+		}
+		fmt.Fprintln(w, line)
+		if change.Diff != "" {
+			fmt.Fprintln(w)
+			fmt.Fprint(w, change.Diff)
+			fmt.Fprintln(w)
+		}
+	}
+
+	fmt.Fprintln(w, "\n"+i18n.T("plan.total",
+		counts[ChangeCreate], counts[ChangeOverwrite], counts[ChangeModify], counts[ChangeUnchanged]))
+}
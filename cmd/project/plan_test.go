@@ -0,0 +1,49 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanRecordFile(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "same.txt"), "same\n")
+	writeTestFile(t, filepath.Join(root, "old.txt"), "old\n")
+
+	plan := NewPlan(root)
+	plan.BeginStep("files")
+	for path, content := range map[string]string{"same.txt": "same\n", "old.txt": "new\n"} {
+		if err := plan.RecordFile(filepath.Join(root, path), []byte(content), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan.BeginStep("hooks")
+	if err := plan.RecordFile(filepath.Join(root, "dir", "new.txt"), []byte("x\n"), "hook"); err != nil {
+		t.Fatal(err)
+	}
+	plan.RecordChange(filepath.Join(root, "gradlew"), ChangeModify, "generated")
+
+	got := make(map[string]PlannedChange)
+	for _, change := range plan.Changes {
+		got[change.Path] = change
+	}
+	want := map[string]PlannedChange{
+		"same.txt":    {Step: "files", Path: "same.txt", Kind: ChangeUnchanged},
+		"old.txt":     {Step: "files", Path: "old.txt", Kind: ChangeOverwrite, Diff: "--- a/old.txt\n+++ b/old.txt\n@@ -1,1 +1,1 @@\n-old\n+new\n"},
+		"dir/new.txt": {Step: "hooks", Path: "dir/new.txt", Kind: ChangeCreate, Note: "hook"},
+		"gradlew":     {Step: "hooks", Path: "gradlew", Kind: ChangeModify, Note: "generated"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v\nwant %+v", got, want)
+	}
+
+	var sb strings.Builder
+	plan.Print(&sb)
+	for _, s := range []string{"[files]", "[hooks]", "-old\n+new", "dir/new.txt  (hook)", "1 to create, 1 to overwrite, 1 to modify, 1 unchanged"} {
+		if !strings.Contains(sb.String(), s) {
+			t.Errorf("plan output does not contain %q:\n%s", s, sb.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"devex/cmd/i18n"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal 判断文件是否是交互式终端
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Prompter 交互式输入
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter 创建交互式输入
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// NewTerminalPrompter 标准输入是终端时返回交互式输入，否则返回 nil
+func NewTerminalPrompter() *Prompter {
+	if !IsTerminal(os.Stdin) {
+		return nil
+	}
+	return NewPrompter(os.Stdin, os.Stdout)
+}
+
+// Ask 读取一行输入，直接回车时返回默认值
+func (p *Prompter) Ask(label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "  %s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(p.out, "  %s: ", label)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", i18n.Errorf("prompt.read_failed", err)
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// Choose 从选项中选择一项，可以输入序号或选项本身
+func (p *Prompter) Choose(label string, choices []string, def string) (string, error) {
+	fmt.Fprintf(p.out, "  %s\n", label)
+	for i, choice := range choices {
+		fmt.Fprintf(p.out, "    %d) %s\n", i+1, choice)
+	}
+
+	for {
+		answer, err := p.Ask(i18n.T("prompt.choose"), def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return choice, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  ❌ %s\n", i18n.T("prompt.invalid_choice", answer))
+	}
+}
+
+// askVariable 输入模板变量，校验失败时重新输入
+func (p *Prompter) askVariable(v *TemplateVariable, def string) (string, error) {
+	label := v.Prompt.String()
+	if label == "" {
+		label = v.Description.String()
+	}
+	if label == "" {
+		label = v.Name
+	}
+	if v.Type == VarTypeBool {
+		label += " (true/false)"
+	}
+
+	for {
+		var answer string
+		var err error
+		if len(v.Choices) > 0 {
+			answer, err = p.Choose(label, v.Choices, def)
+		} else {
+			answer, err = p.Ask(label, def)
+		}
+		if err != nil {
+			return "", err
+		}
+		if answer == "" && v.Required {
+			fmt.Fprintf(p.out, "  ❌ %s\n", i18n.T("prompt.required", v.Name))
+			continue
+		}
+
+		normalized, err := v.Validate(answer)
+		if err != nil {
+			fmt.Fprintf(p.out, "  ❌ %s\n", err)
+			continue
+		}
+		return normalized, nil
+	}
+}
@@ -0,0 +1,285 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"devex/cmd/i18n"
+)
+
+// 命令输出格式
+const (
+	OutputText = "text" // 面向人阅读的文本
+	OutputJSON = "json" // 每行一个 JSON 事件，供自动化解析
+)
+
+// EventType JSON 输出中的事件类型
+type EventType string
+
+const (
+	EventStepStart  EventType = "step_start"  // 步骤开始
+	EventStepFinish EventType = "step_finish" // 步骤结束，status 为 ok 或 failed
+	EventProgress   EventType = "progress"    // 步骤中的阶段
+	EventInfo       EventType = "info"        // 普通信息
+	EventSuccess    EventType = "success"     // 某项操作成功
+	EventHint       EventType = "hint"        // 提示
+	EventFile       EventType = "file"        // 写入了文件
+	EventWarning    EventType = "warning"     // 警告，带稳定的 code
+	EventError      EventType = "error"       // 错误，带稳定的 code
+	EventResult     EventType = "result"      // 命令的结构化结果
+)
+
+// Event JSON 输出中的一个事件
+type Event struct {
+	Type    EventType   `json:"type"`
+	Time    string      `json:"time"`
+	Repo    string      `json:"repo,omitempty"` // 批量执行时事件所属的仓库
+	Step    string      `json:"step,omitempty"`
+	Status  string      `json:"status,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Path    string      `json:"path,omitempty"`
+	Change  ChangeKind  `json:"change,omitempty"`
+	Kind    string      `json:"kind,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// 稳定的警告码
+const (
+	WarnMergeConflict     = "merge_conflict"     // 模板无法合并，写入了 .devex-new 旁路文件
+	WarnDependencyMissing = "dependency_missing" // 可选的工具未安装
+	WarnFileMissing       = "file_missing"       // 预期存在的文件不存在
+	WarnIgnoreExpired     = "ignore_expired"     // 敏感信息忽略项已过期
+	WarnUnknownRule       = "unknown_rule"       // 规则不在已知的规则中
+	WarnConfigMissing     = "config_missing"     // 配置文件不存在，使用默认值
+	WarnIgnoredInput      = "ignored_input"      // 参数被忽略
+	WarnTemplateSource    = "template_source"    // 注册的模板源不可用
+	WarnRollbackFailed    = "rollback_failed"    // 回滚未完全成功，需要手动检查
+	WarnUntrustedTemplate = "untrusted_template" // 外部模板的生成后命令未被信任，已跳过
+	WarnUnknownConfigKey  = "unknown_config_key" // 配置中有不支持的字段，已忽略
+)
+
+// Reporter 命令执行过程中的事件输出，初始化器等通过它报告进度而不是直接打印
+type Reporter interface {
+	// StepStart 步骤开始
+	StepStart(step string)
+	// StepFinish 步骤结束，err 非空表示失败
+	StepFinish(step string, err error)
+	// Progress 步骤中的阶段标题，icon 只用于文本输出
+	Progress(icon, message string)
+	// Info 普通信息
+	Info(message string)
+	// Detail 阶段中的明细
+	Detail(message string)
+	// Success 某项操作成功
+	Success(message string)
+	// Hint 提示
+	Hint(message string)
+	// Warn 警告，code 为稳定的警告码
+	Warn(code, message string)
+	// FileWritten 写入了文件，path 为相对于项目目录的路径
+	FileWritten(path string, change ChangeKind, note string)
+	// Error 错误，code 为稳定的错误码
+	Error(code ErrorCode, err error)
+	// Result 命令的结构化结果，文本输出中由命令自行打印
+	Result(kind string, data interface{})
+	// Output 外部命令（git、xcodegen 等）输出的去向
+	Output() io.Writer
+}
+
+// NewReporter 根据输出格式创建 Reporter
+func NewReporter(format string) (Reporter, error) {
+	switch format {
+	case "", OutputText:
+		return NewHumanReporter(os.Stdout), nil
+	case OutputJSON:
+		return NewJSONReporter(os.Stdout, os.Stderr), nil
+	default:
+		return nil, Errorf(ErrUsage, "reporter.unsupported_format", format, OutputText, OutputJSON)
+	}
+}
+
+// defaultReporter 未单独设置 Reporter 时使用的输出
+var defaultReporter Reporter = NewHumanReporter(os.Stdout)
+
+// SetReporter 设置默认的 Reporter，不属于某个初始化器的输出（语言检测、模板源、扫描等）使用它
+func SetReporter(r Reporter) {
+	defaultReporter = r
+}
+
+// CurrentReporter 获取默认的 Reporter
+func CurrentReporter() Reporter {
+	return defaultReporter
+}
+
+// ForRepo 批量执行时单个仓库的输出
+// JSON 输出中的事件带上 repo 字段；文本输出写入 buf，由调用方在仓库完成后整体输出，避免多个仓库的输出交错
+func ForRepo(parent Reporter, repo string, buf io.Writer) Reporter {
+	if j, ok := parent.(*JSONReporter); ok {
+		return &JSONReporter{mu: j.mu, enc: j.enc, stderr: j.stderr, repo: repo}
+	}
+	return NewHumanReporter(buf)
+}
+
+// HumanReporter 文本输出，保持原有的带图标的中文格式
+type HumanReporter struct {
+	w io.Writer
+}
+
+// NewHumanReporter 创建文本输出
+func NewHumanReporter(w io.Writer) *HumanReporter {
+	return &HumanReporter{w: w}
+}
+
+// StepStart 文本输出中步骤的开始由各阶段标题体现
+func (h *HumanReporter) StepStart(step string) {}
+
+// StepFinish 文本输出中步骤的失败由命令统一输出
+func (h *HumanReporter) StepFinish(step string, err error) {}
+
+// Progress 输出阶段标题
+func (h *HumanReporter) Progress(icon, message string) {
+	fmt.Fprintf(h.w, "%s %s\n", icon, message)
+}
+
+// Info 输出普通信息
+func (h *HumanReporter) Info(message string) {
+	fmt.Fprintln(h.w, message)
+}
+
+// Detail 输出明细
+func (h *HumanReporter) Detail(message string) {
+	fmt.Fprintf(h.w, "  - %s\n", message)
+}
+
+// Success 输出成功信息
+func (h *HumanReporter) Success(message string) {
+	fmt.Fprintf(h.w, "  ✅ %s\n", message)
+}
+
+// Hint 输出提示
+func (h *HumanReporter) Hint(message string) {
+	fmt.Fprintf(h.w, "  💡 %s\n", message)
+}
+
+// Warn 输出警告
+func (h *HumanReporter) Warn(code, message string) {
+	fmt.Fprintf(h.w, "  ⚠️  %s\n", message)
+}
+
+// FileWritten 文本输出中不逐个列出写入的文件
+func (h *HumanReporter) FileWritten(path string, change ChangeKind, note string) {}
+
+// Error 输出错误
+func (h *HumanReporter) Error(code ErrorCode, err error) {
+	fmt.Fprintln(h.w, i18n.T("reporter.error", err))
+}
+
+// Result 文本输出中结果由命令自行打印
+func (h *HumanReporter) Result(kind string, data interface{}) {}
+
+// Output 外部命令的输出直接显示
+func (h *HumanReporter) Output() io.Writer {
+	return h.w
+}
+
+// JSONReporter JSON Lines 输出，每个事件一行，外部命令的输出转到 stderr 以免破坏格式
+type JSONReporter struct {
+	mu     *sync.Mutex // 同一输出的各仓库 Reporter 共用
+	enc    *json.Encoder
+	stderr io.Writer
+	repo   string // 批量执行时事件所属的仓库
+}
+
+// NewJSONReporter 创建 JSON Lines 输出
+func NewJSONReporter(w, stderr io.Writer) *JSONReporter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONReporter{mu: &sync.Mutex{}, enc: enc, stderr: stderr}
+}
+
+// emit 输出一个事件，多个 goroutine 可以同时调用
+func (j *JSONReporter) emit(e Event) {
+	e.Time = time.Now().UTC().Format(time.RFC3339)
+	e.Message = strings.TrimSpace(e.Message)
+	e.Repo = j.repo
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.enc.Encode(e)
+}
+
+// StepStart 输出步骤开始事件
+func (j *JSONReporter) StepStart(step string) {
+	j.emit(Event{Type: EventStepStart, Step: step})
+}
+
+// StepFinish 输出步骤结束事件
+func (j *JSONReporter) StepFinish(step string, err error) {
+	e := Event{Type: EventStepFinish, Step: step, Status: "ok"}
+	if err != nil {
+		e.Status = "failed"
+		e.Code = string(ErrorCodeOf(err))
+		e.Message = err.Error()
+	}
+	j.emit(e)
+}
+
+// Progress 输出阶段事件
+func (j *JSONReporter) Progress(icon, message string) {
+	j.emit(Event{Type: EventProgress, Message: message})
+}
+
+// Info 输出信息事件
+func (j *JSONReporter) Info(message string) {
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	j.emit(Event{Type: EventInfo, Message: message})
+}
+
+// Detail 输出信息事件
+func (j *JSONReporter) Detail(message string) {
+	j.emit(Event{Type: EventInfo, Message: message})
+}
+
+// Success 输出成功事件
+func (j *JSONReporter) Success(message string) {
+	j.emit(Event{Type: EventSuccess, Message: message})
+}
+
+// Hint 输出提示事件
+func (j *JSONReporter) Hint(message string) {
+	j.emit(Event{Type: EventHint, Message: message})
+}
+
+// Warn 输出警告事件
+func (j *JSONReporter) Warn(code, message string) {
+	j.emit(Event{Type: EventWarning, Code: code, Message: message})
+}
+
+// FileWritten 输出文件事件
+func (j *JSONReporter) FileWritten(path string, change ChangeKind, note string) {
+	j.emit(Event{Type: EventFile, Path: filepath.ToSlash(path), Change: change, Message: note})
+}
+
+// Error 输出错误事件
+func (j *JSONReporter) Error(code ErrorCode, err error) {
+	j.emit(Event{Type: EventError, Code: string(code), Message: err.Error()})
+}
+
+// Result 输出结果事件
+func (j *JSONReporter) Result(kind string, data interface{}) {
+	j.emit(Event{Type: EventResult, Kind: kind, Data: data})
+}
+
+// Output 外部命令的输出转到 stderr
+func (j *JSONReporter) Output() io.Writer {
+	return j.stderr
+}
@@ -0,0 +1,85 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestJSONReporterEvents 每个事件输出一行 JSON，字段名和取值是自动化依赖的稳定格式
+func TestJSONReporterEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		report func(r Reporter)
+		want   map[string]interface{} // 不含 time
+	}{
+		{name: "step start", report: func(r Reporter) { r.StepStart("hooks") },
+			want: map[string]interface{}{"type": "step_start", "step": "hooks"}},
+		{name: "step ok", report: func(r Reporter) { r.StepFinish("hooks", nil) },
+			want: map[string]interface{}{"type": "step_finish", "step": "hooks", "status": "ok"}},
+		{name: "step failed", report: func(r Reporter) { r.StepFinish("hooks", WithCode(ErrGit, errors.New("git failed"))) },
+			want: map[string]interface{}{"type": "step_finish", "step": "hooks", "status": "failed", "code": "git", "message": "git failed"}},
+		{name: "progress", report: func(r Reporter) { r.Progress("🔨", "creating\n") },
+			want: map[string]interface{}{"type": "progress", "message": "creating"}},
+		{name: "detail", report: func(r Reporter) { r.Detail("running") },
+			want: map[string]interface{}{"type": "info", "message": "running"}},
+		{name: "success", report: func(r Reporter) { r.Success("done") },
+			want: map[string]interface{}{"type": "success", "message": "done"}},
+		{name: "hint", report: func(r Reporter) { r.Hint("try --force") },
+			want: map[string]interface{}{"type": "hint", "message": "try --force"}},
+		{name: "warning", report: func(r Reporter) { r.Warn(WarnMergeConflict, "conflict") },
+			want: map[string]interface{}{"type": "warning", "code": "merge_conflict", "message": "conflict"}},
+		{name: "file", report: func(r Reporter) { r.FileWritten(filepath.Join("config", "a.yml"), ChangeCreate, "") },
+			want: map[string]interface{}{"type": "file", "path": "config/a.yml", "change": "create"}},
+		{name: "error", report: func(r Reporter) { r.Error(ErrConfig, errors.New("bad <config>")) },
+			want: map[string]interface{}{"type": "error", "code": "config", "message": "bad <config>"}},
+		{name: "result", report: func(r Reporter) { r.Result("doctor", map[string]int{"missing": 1}) },
+			want: map[string]interface{}{"type": "result", "kind": "doctor", "data": map[string]interface{}{"missing": float64(1)}}},
+		{name: "repo", report: func(r Reporter) { ForRepo(r, "apps/ios", nil).Success("done") },
+			want: map[string]interface{}{"type": "success", "repo": "apps/ios", "message": "done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, stderr bytes.Buffer
+			tt.report(NewJSONReporter(&out, &stderr))
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("got %d lines, want 1: %q", len(lines), out.String())
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+				t.Fatal(err)
+			}
+			stamp, _ := got["time"].(string)
+			if _, err := time.Parse(time.RFC3339, stamp); err != nil {
+				t.Errorf("time = %q: %v", stamp, err)
+			}
+			delete(got, "time")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONReporterOutput 外部命令的输出写入 stderr，空信息不输出事件
+func TestJSONReporterOutput(t *testing.T) {
+	var out, stderr bytes.Buffer
+	r := NewJSONReporter(&out, &stderr)
+	fmt.Fprintln(r.Output(), "gradle output")
+	r.Info("  ")
+
+	if out.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", out.String())
+	}
+	if stderr.String() != "gradle output\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
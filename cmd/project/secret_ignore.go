@@ -0,0 +1,379 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"devex/cmd/gitleaks"
+	"devex/cmd/i18n"
+)
+
+// 忽略项类型
+const (
+	IgnoreKindPath        = "path"        // 路径正则，指定规则时只对该规则生效
+	IgnoreKindRule        = "rule"        // 整条规则
+	IgnoreKindFingerprint = "fingerprint" // 单个发现的指纹
+)
+
+// GitleaksIgnoreFile gitleaks 读取的指纹忽略文件，位于仓库根目录
+const GitleaksIgnoreFile = ".gitleaksignore"
+
+// ignoreDateLayout 忽略项日期格式
+const ignoreDateLayout = "2006-01-02"
+
+// allPathsPattern 忽略本地定义的整条规则时写入规则 allowlist 的路径
+const allPathsPattern = ".*"
+
+// SecretIgnores 管理 .gitleaks.toml 中的忽略项
+// 忽略项按 gitleaks 的方式生效，同时在 [[devex.ignores]] 中记录原因和过期日期
+type SecretIgnores struct {
+	Root       string
+	ConfigPath string
+	config     *gitleaks.Config
+	// ignoreEdits 待写入 .gitleaksignore 的修改，在 .gitleaks.toml 保存成功后执行
+	ignoreEdits []func(lines []string) []string
+}
+
+// NewSecretIgnores 读取仓库根目录下的 .gitleaks.toml
+func NewSecretIgnores(projectPath string) (*SecretIgnores, error) {
+	root, err := gitRoot(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(root, ".gitleaks.toml")
+	cfg, err := gitleaks.Load(configPath)
+	if os.IsNotExist(err) {
+		return nil, Errorf(ErrConfig, "secret_ignore.no_config", configPath)
+	}
+	if err != nil {
+		return nil, Errorf(ErrConfig, "errors.context", configPath, err)
+	}
+	if cfg.Devex == nil {
+		cfg.Devex = &gitleaks.Devex{}
+	}
+	return &SecretIgnores{Root: root, ConfigPath: configPath, config: cfg}, nil
+}
+
+// List 返回所有忽略项
+func (s *SecretIgnores) List() []gitleaks.Ignore {
+	return s.config.Devex.Ignores
+}
+
+// Add 添加忽略项，相同的忽略项已存在时更新原因和过期日期
+func (s *SecretIgnores) Add(ignore gitleaks.Ignore) error {
+	if strings.TrimSpace(ignore.Reason) == "" {
+		return Errorf(ErrUsage, "secret_ignore.no_reason")
+	}
+	if ignore.Expires != "" {
+		expires, err := time.Parse(ignoreDateLayout, ignore.Expires)
+		if err != nil {
+			return Errorf(ErrUsage, "secret_ignore.bad_date", ignore.Expires)
+		}
+		if expires.Before(today()) {
+			return Errorf(ErrUsage, "secret_ignore.date_passed", ignore.Expires)
+		}
+	}
+
+	preexisting, err := s.apply(ignore)
+	if err != nil {
+		return err
+	}
+
+	if existing := s.config.Devex.Ignore(ignore.Kind, ignore.Value, ignore.Rule); existing != nil {
+		existing.Reason = ignore.Reason
+		existing.Expires = ignore.Expires
+		return nil
+	}
+	ignore.Added = today().Format(ignoreDateLayout)
+	ignore.Preexisting = preexisting
+	s.config.Devex.Ignores = append(s.config.Devex.Ignores, ignore)
+	return nil
+}
+
+// apply 把忽略项写入 gitleaks 实际读取的位置，返回 .gitleaks.toml 中是否已有相同的条目
+func (s *SecretIgnores) apply(ignore gitleaks.Ignore) (bool, error) {
+	switch ignore.Kind {
+	case IgnoreKindPath:
+		if _, err := regexp.Compile(ignore.Value); err != nil {
+			return false, Errorf(ErrUsage, "secret_ignore.bad_path", err)
+		}
+		if ignore.Rule == "" {
+			if s.config.Allowlist == nil {
+				s.config.Allowlist = &gitleaks.Allowlist{}
+			}
+			preexisting := containsString(s.config.Allowlist.Paths, ignore.Value)
+			s.config.Allowlist.Paths = appendUnique(s.config.Allowlist.Paths, ignore.Value)
+			return preexisting, nil
+		}
+		rule := s.config.Rule(ignore.Rule)
+		if rule == nil {
+			return false, Errorf(ErrUsage, "secret_ignore.rule_not_defined", ignore.Rule)
+		}
+		if rule.Allowlist == nil {
+			rule.Allowlist = &gitleaks.Allowlist{}
+		}
+		preexisting := containsString(rule.Allowlist.Paths, ignore.Value)
+		rule.Allowlist.Paths = appendUnique(rule.Allowlist.Paths, ignore.Value)
+		return preexisting, nil
+
+	case IgnoreKindRule:
+		if rule := s.config.Rule(ignore.Value); rule != nil {
+			if rule.Allowlist == nil {
+				rule.Allowlist = &gitleaks.Allowlist{}
+			}
+			preexisting := containsString(rule.Allowlist.Paths, allPathsPattern)
+			rule.Allowlist.Paths = appendUnique(rule.Allowlist.Paths, allPathsPattern)
+			return preexisting, nil
+		}
+		if s.config.Extend == nil || !s.config.Extend.UseDefault {
+			return false, Errorf(ErrUsage, "secret_ignore.rule_not_found", ignore.Value)
+		}
+		if defaults, err := gitleaks.DefaultConfig(); err == nil && defaults.Rule(ignore.Value) == nil {
+			defaultReporter.Warn(WarnUnknownRule, i18n.T("secret_ignore.unknown_rule", ignore.Value))
+		}
+		preexisting := containsString(s.config.Extend.DisabledRules, ignore.Value)
+		s.config.Extend.DisabledRules = appendUnique(s.config.Extend.DisabledRules, ignore.Value)
+		return preexisting, nil
+
+	case IgnoreKindFingerprint:
+		if strings.Count(ignore.Value, ":") < 2 {
+			return false, Errorf(ErrUsage, "secret_ignore.bad_fingerprint", ignore.Value)
+		}
+		comment := "# " + ignore.Reason
+		if ignore.Expires != "" {
+			comment += " (expires " + ignore.Expires + ")"
+		}
+		s.ignoreEdits = append(s.ignoreEdits, func(lines []string) []string {
+			lines = removeFingerprint(lines, ignore.Value)
+			return append(lines, comment, ignore.Value)
+		})
+		return false, nil
+	}
+	return false, Errorf(ErrUsage, "secret_ignore.unsupported_kind", ignore.Kind)
+}
+
+// Remove 删除忽略项并撤销其效果，添加前已存在的 allowlist 路径和禁用规则保留
+func (s *SecretIgnores) Remove(ignore gitleaks.Ignore) error {
+	existing := s.config.Devex.Ignore(ignore.Kind, ignore.Value, ignore.Rule)
+	if existing == nil {
+		return Errorf(ErrUsage, "secret_ignore.not_found", ignore.Kind, ignore.Value)
+	}
+
+	switch {
+	case existing.Preexisting:
+	case ignore.Kind == IgnoreKindPath:
+		if ignore.Rule == "" {
+			if s.config.Allowlist != nil {
+				s.config.Allowlist.Paths = removeString(s.config.Allowlist.Paths, ignore.Value)
+			}
+		} else if rule := s.config.Rule(ignore.Rule); rule != nil {
+			removeRulePath(rule, ignore.Value)
+		}
+	case ignore.Kind == IgnoreKindRule:
+		if rule := s.config.Rule(ignore.Value); rule != nil {
+			removeRulePath(rule, allPathsPattern)
+		} else if s.config.Extend != nil {
+			s.config.Extend.DisabledRules = removeString(s.config.Extend.DisabledRules, ignore.Value)
+		}
+	case ignore.Kind == IgnoreKindFingerprint:
+		s.ignoreEdits = append(s.ignoreEdits, func(lines []string) []string {
+			return removeFingerprint(lines, ignore.Value)
+		})
+	}
+
+	var kept []gitleaks.Ignore
+	for _, item := range s.config.Devex.Ignores {
+		if item != *existing {
+			kept = append(kept, item)
+		}
+	}
+	s.config.Devex.Ignores = kept
+	return nil
+}
+
+// RemoveExpired 删除所有已过期的忽略项，返回被删除的忽略项
+func (s *SecretIgnores) RemoveExpired() ([]gitleaks.Ignore, error) {
+	expired := ExpiredIgnores(s.List())
+	for _, ignore := range expired {
+		if err := s.Remove(ignore); err != nil {
+			return nil, err
+		}
+	}
+	return expired, nil
+}
+
+// Save 写回 .gitleaks.toml，成功后再修改 .gitleaksignore，避免指纹已生效而忽略记录没有保存
+func (s *SecretIgnores) Save() error {
+	if err := s.config.Save(s.ConfigPath); err != nil {
+		return Errorf(ErrIO, "upgrade.write_failed", s.ConfigPath, err)
+	}
+	if len(s.ignoreEdits) == 0 {
+		return nil
+	}
+	if err := s.editIgnoreFile(func(lines []string) []string {
+		for _, edit := range s.ignoreEdits {
+			lines = edit(lines)
+		}
+		return lines
+	}); err != nil {
+		return WithDefaultCode(ErrIO, err)
+	}
+	s.ignoreEdits = nil
+	return nil
+}
+
+// editIgnoreFile 按行修改 .gitleaksignore
+func (s *SecretIgnores) editIgnoreFile(edit func(lines []string) []string) error {
+	path := filepath.Join(s.Root, GitleaksIgnoreFile)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return i18n.Errorf("secret_ignore.read_failed", GitleaksIgnoreFile, err)
+	}
+
+	var lines []string
+	if trimmed := strings.TrimRight(string(content), "\n"); trimmed != "" {
+		lines = strings.Split(trimmed, "\n")
+	}
+	lines = edit(lines)
+
+	if len(lines) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// removeFingerprint 删除指纹行及其上方的注释
+func removeFingerprint(lines []string, fingerprint string) []string {
+	var kept []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == fingerprint {
+			if n := len(kept); n > 0 && strings.HasPrefix(kept[n-1], "#") {
+				kept = kept[:n-1]
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// removeRulePath 从规则的 allowlist 中删除路径，allowlist 为空时一并删除
+func removeRulePath(rule *gitleaks.Rule, path string) {
+	if rule.Allowlist == nil {
+		return
+	}
+	rule.Allowlist.Paths = removeString(rule.Allowlist.Paths, path)
+	a := rule.Allowlist
+	if a.Description == "" && len(a.Regexes) == 0 && len(a.Paths) == 0 && len(a.Commits) == 0 && len(a.StopWords) == 0 {
+		rule.Allowlist = nil
+	}
+}
+
+// ParseIgnoreExpiry 解析过期时间，支持 YYYY-MM-DD 和相对天数（如 90d），返回 YYYY-MM-DD
+func ParseIgnoreExpiry(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return "", Errorf(ErrUsage, "secret_ignore.bad_expires", value)
+		}
+		return today().AddDate(0, 0, n).Format(ignoreDateLayout), nil
+	}
+	if _, err := time.Parse(ignoreDateLayout, value); err != nil {
+		return "", Errorf(ErrUsage, "secret_ignore.bad_expires", value)
+	}
+	return value, nil
+}
+
+// IgnoreExpired 判断忽略项是否已过期，过期日期当天仍然有效
+func IgnoreExpired(ignore gitleaks.Ignore) bool {
+	if ignore.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(ignoreDateLayout, ignore.Expires)
+	return err == nil && today().After(expires)
+}
+
+// ExpiredIgnores 返回已过期的忽略项
+func ExpiredIgnores(ignores []gitleaks.Ignore) []gitleaks.Ignore {
+	var expired []gitleaks.Ignore
+	for _, ignore := range ignores {
+		if IgnoreExpired(ignore) {
+			expired = append(expired, ignore)
+		}
+	}
+	return expired
+}
+
+// DescribeIgnore 忽略项的简短描述，如 path .*\.strings$ (chinese-characters)
+func DescribeIgnore(ignore gitleaks.Ignore) string {
+	desc := ignore.Kind + " " + ignore.Value
+	if ignore.Rule != "" {
+		desc += " (" + ignore.Rule + ")"
+	}
+	return desc
+}
+
+// warnExpiredIgnores 提示 gitleaks 配置中已过期的忽略项
+func warnExpiredIgnores(cfg *gitleaks.Config) {
+	if cfg == nil || cfg.Devex == nil {
+		return
+	}
+	for _, ignore := range ExpiredIgnores(cfg.Devex.Ignores) {
+		defaultReporter.Warn(WarnIgnoreExpired, i18n.T("secret_ignore.expired", ignore.Expires, DescribeIgnore(ignore), ignore.Reason))
+	}
+}
+
+// loadGitleaksIgnore 读取 .gitleaksignore 中的指纹
+func loadGitleaksIgnore(root string) (map[string]bool, error) {
+	fingerprints := make(map[string]bool)
+	content, err := os.ReadFile(filepath.Join(root, GitleaksIgnoreFile))
+	if os.IsNotExist(err) {
+		return fingerprints, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("secret_ignore.read_failed", GitleaksIgnoreFile, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			fingerprints[line] = true
+		}
+	}
+	return fingerprints, nil
+}
+
+// today 当天零点（UTC），用于比较日期
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// appendUnique 追加不存在的字符串
+func appendUnique(items []string, s string) []string {
+	if containsString(items, s) {
+		return items
+	}
+	return append(items, s)
+}
+
+// removeString 删除字符串
+func removeString(items []string, s string) []string {
+	var kept []string
+	for _, item := range items {
+		if item != s {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
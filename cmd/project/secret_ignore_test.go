@@ -0,0 +1,223 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"devex/cmd/gitleaks"
+)
+
+func TestNewSecretIgnoresMissingConfig(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+
+	_, err := NewSecretIgnores(dir)
+	if err == nil {
+		t.Fatal("expected an error without .gitleaks.toml")
+	}
+	if code := ErrorCodeOf(err); code != ErrConfig {
+		t.Errorf("code = %s, want %s", code, ErrConfig)
+	}
+	if !strings.Contains(err.Error(), ".gitleaks.toml") || strings.Contains(err.Error(), ManifestFile) {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+// TestSecretIgnoresKeepsConfigLayout 添加和删除忽略项只修改相关的行，保留注释、顺序和不支持的字段
+func TestSecretIgnoresKeepsConfigLayout(t *testing.T) {
+	const original = `# team secrets config
+[extend]
+useDefault = true
+
+# keep this comment
+[[rules]]
+id = "token"
+regex = '''tok_[a-z0-9]{8}'''
+condition = "AND"
+
+[allowlist]
+paths = [
+    '''^vendor/''', # third party
+]
+`
+	tests := []struct {
+		name   string
+		ignore gitleaks.Ignore
+		want   string
+	}{
+		{
+			name:   "global path",
+			ignore: gitleaks.Ignore{Kind: IgnoreKindPath, Value: "^docs/", Reason: "examples"},
+			want: strings.Replace(original, "    '''^vendor/''', # third party\n", "    '''^vendor/''', # third party\n    '''^docs/''',\n", 1) +
+				"\n[[devex.ignores]]\nkind = \"path\"\nvalue = '''^docs/'''\nreason = \"examples\"\nadded = \"" + today().Format(ignoreDateLayout) + "\"\n",
+		},
+		{
+			name:   "rule",
+			ignore: gitleaks.Ignore{Kind: IgnoreKindRule, Value: "token", Reason: "noisy"},
+			want: strings.Replace(original, "condition = \"AND\"\n", "condition = \"AND\"\n\n[rules.allowlist]\npaths = [\n    '''.*'''\n]\n", 1) +
+				"\n[[devex.ignores]]\nkind = \"rule\"\nvalue = '''token'''\nreason = \"noisy\"\nadded = \"" + today().Format(ignoreDateLayout) + "\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			initGitRepo(t, dir)
+			configPath := filepath.Join(dir, ".gitleaks.toml")
+			writeTestFile(t, configPath, original)
+
+			ignores, err := NewSecretIgnores(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Add(tt.ignore); err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(configPath); string(got) != tt.want {
+				t.Fatalf("after add:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			ignores, err = NewSecretIgnores(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Remove(tt.ignore); err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Save(); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(configPath)
+			if strings.Contains(string(got), "devex.ignores") || !strings.Contains(string(got), "# keep this comment") {
+				t.Errorf("after remove:\n%s", got)
+			}
+		})
+	}
+}
+
+// TestSecretIgnoresKeepsPreexistingEntries 删除忽略项时只撤销 devex 添加的条目，添加前已有的 allowlist 路径和禁用规则保留
+func TestSecretIgnoresKeepsPreexistingEntries(t *testing.T) {
+	const original = `[extend]
+useDefault = true
+disabledRules = ["generic-api-key"]
+
+[[rules]]
+id = "token"
+regex = '''tok_[a-z0-9]{8}'''
+
+[rules.allowlist]
+paths = [
+    '''^fixtures/''',
+]
+
+[allowlist]
+paths = [
+    '''^vendor/''',
+]
+`
+	tests := []struct {
+		name   string
+		ignore gitleaks.Ignore
+		kept   string // 删除后仍应存在的内容
+	}{
+		{name: "global path", ignore: gitleaks.Ignore{Kind: IgnoreKindPath, Value: "^vendor/", Reason: "third party"}, kept: "'''^vendor/'''"},
+		{name: "rule path", ignore: gitleaks.Ignore{Kind: IgnoreKindPath, Value: "^fixtures/", Rule: "token", Reason: "fixtures"}, kept: "'''^fixtures/'''"},
+		{name: "disabled rule", ignore: gitleaks.Ignore{Kind: IgnoreKindRule, Value: "generic-api-key", Reason: "noisy"}, kept: `"generic-api-key"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			initGitRepo(t, dir)
+			configPath := filepath.Join(dir, ".gitleaks.toml")
+			writeTestFile(t, configPath, original)
+
+			ignores, err := NewSecretIgnores(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Add(tt.ignore); err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(configPath); !strings.Contains(string(got), "preexisting = true") {
+				t.Fatalf("the ignore is not marked as preexisting:\n%s", got)
+			}
+
+			ignores, err = NewSecretIgnores(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Remove(tt.ignore); err != nil {
+				t.Fatal(err)
+			}
+			if err := ignores.Save(); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(configPath)
+			if strings.Contains(string(got), "devex.ignores") || !strings.Contains(string(got), tt.kept) {
+				t.Errorf("after remove:\n%s", got)
+			}
+		})
+	}
+}
+
+// TestSecretIgnoresFingerprintWrittenOnSave .gitleaksignore 在 .gitleaks.toml 保存成功后才修改
+func TestSecretIgnoresFingerprintWrittenOnSave(t *testing.T) {
+	dir := t.TempDir()
+	initGitRepo(t, dir)
+	configPath := filepath.Join(dir, ".gitleaks.toml")
+	writeTestFile(t, configPath, "[extend]\nuseDefault = true\n")
+	ignorePath := filepath.Join(dir, GitleaksIgnoreFile)
+	const fingerprint = "abc123:config.yml:generic-api-key:3"
+
+	ignores, err := NewSecretIgnores(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ignores.Add(gitleaks.Ignore{Kind: IgnoreKindFingerprint, Value: fingerprint, Reason: "test key"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ignorePath); !os.IsNotExist(err) {
+		t.Fatalf("%s written before Save: %v", GitleaksIgnoreFile, err)
+	}
+
+	// .gitleaks.toml 无法写入时不修改 .gitleaksignore
+	ignores.ConfigPath = t.TempDir()
+	if err := ignores.Save(); err == nil {
+		t.Fatal("expected Save to fail")
+	}
+	if _, err := os.Stat(ignorePath); !os.IsNotExist(err) {
+		t.Fatalf("%s written although the config was not saved: %v", GitleaksIgnoreFile, err)
+	}
+
+	ignores.ConfigPath = configPath
+	if err := ignores.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(ignorePath); string(got) != "# test key\n"+fingerprint+"\n" {
+		t.Errorf("%s = %q", GitleaksIgnoreFile, got)
+	}
+
+	ignores, err = NewSecretIgnores(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ignores.Remove(gitleaks.Ignore{Kind: IgnoreKindFingerprint, Value: fingerprint}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ignorePath); err != nil {
+		t.Fatalf("%s changed before Save: %v", GitleaksIgnoreFile, err)
+	}
+	if err := ignores.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ignorePath); !os.IsNotExist(err) {
+		t.Errorf("%s not removed: %v", GitleaksIgnoreFile, err)
+	}
+}
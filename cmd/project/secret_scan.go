@@ -0,0 +1,493 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"devex/cmd/gitleaks"
+	"devex/cmd/i18n"
+)
+
+// 敏感信息扫描引擎
+const (
+	ScanEngineAuto     = "auto"     // 安装了 gitleaks 时使用 gitleaks，否则使用内置扫描器
+	ScanEngineGitleaks = "gitleaks" // 只使用 gitleaks
+	ScanEngineBuiltin  = "builtin"  // 只使用内置扫描器
+)
+
+// SecretsBaselineFile 默认的敏感信息基线文件，位于仓库根目录
+const SecretsBaselineFile = ".gitleaks-baseline.json"
+
+// binarySniffLen 判断二进制文件时检查的字节数，与 git 一致
+const binarySniffLen = 8000
+
+// hunkHeaderPattern 统一差异格式的块头，取新文件的起始行和行数
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// SecretScanner 扫描暂存区或提交范围中新增内容里的敏感信息
+type SecretScanner struct {
+	ProjectPath  string
+	ConfigPath   string // gitleaks 配置文件，为空时使用仓库根目录下的 .gitleaks.toml
+	Engine       string
+	ReportPath   string // gitleaks JSON 报告的输出路径，为空时不输出
+	Since        string // 不为空时扫描 <Since>..HEAD 之间的提交，否则扫描暂存区
+	All          bool   // 扫描暂存区中所有文件的全部内容，用于生成基线
+	BaselinePath string // 基线文件（gitleaks JSON 报告），相对路径相对于仓库根目录，不存在时忽略
+}
+
+// ScanResult 扫描结果
+type ScanResult struct {
+	Engine    string             `json:"engine"`
+	Leaks     bool               `json:"leaks"`
+	Findings  []gitleaks.Finding `json:"findings"`  // 只有内置扫描器会返回发现，gitleaks 的输出直接打印到终端
+	Baselined int                `json:"baselined"` // 基线中已接受而不再报告的发现数（内置扫描器）
+}
+
+// scanTarget 需要扫描的一个文件版本及其新增的行
+type scanTarget struct {
+	commit *commitInfo // 扫描暂存区时为 nil
+	file   string
+	added  []lineRange
+	whole  bool // 扫描全部内容，而不只是新增的行
+}
+
+// lineRange 新增的行范围，行号从 1 开始，包含两端
+type lineRange struct {
+	start, end int
+}
+
+// commitInfo 提交信息，填入发现的 Commit、Author 等字段
+type commitInfo struct {
+	sha, author, email, date, message string
+}
+
+// NewSecretScanner 创建敏感信息扫描器
+func NewSecretScanner(projectPath, configPath, engine, reportPath string) *SecretScanner {
+	return &SecretScanner{
+		ProjectPath: projectPath,
+		ConfigPath:  configPath,
+		Engine:      engine,
+		ReportPath:  reportPath,
+	}
+}
+
+// Scan 扫描暂存区或提交范围，按 Engine 选择 gitleaks 或内置扫描器
+func (s *SecretScanner) Scan() (*ScanResult, error) {
+	root, err := gitRoot(s.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// gitleaks 在仓库根目录执行，相对路径需要先转换
+	configPath := filepath.Join(root, ".gitleaks.toml")
+	if s.ConfigPath != "" {
+		if configPath, err = filepath.Abs(s.ConfigPath); err != nil {
+			return nil, err
+		}
+	}
+	if s.ReportPath != "" {
+		if s.ReportPath, err = filepath.Abs(s.ReportPath); err != nil {
+			return nil, err
+		}
+	}
+	if s.BaselinePath != "" && !filepath.IsAbs(s.BaselinePath) {
+		s.BaselinePath = filepath.Join(root, s.BaselinePath)
+	}
+	if s.Since != "" && s.Since != EmptyTreeID {
+		if _, err := runGit(root, "rev-parse", "--verify", "--quiet", s.Since+"^{commit}"); err != nil {
+			return nil, Errorf(ErrUsage, "secret_scan.bad_commit", s.Since)
+		}
+	}
+	if cfg, err := gitleaks.Load(configPath); err == nil {
+		warnExpiredIgnores(cfg)
+	}
+
+	switch s.Engine {
+	case ScanEngineAuto, "":
+		if _, err := exec.LookPath("gitleaks"); err == nil {
+			return s.runGitleaks(root, configPath)
+		}
+		return s.runBuiltin(root, configPath)
+	case ScanEngineGitleaks:
+		if _, err := exec.LookPath("gitleaks"); err != nil {
+			return nil, Errorf(ErrDependency, "secret_scan.no_gitleaks", GetInstallationInstructions("gitleaks"))
+		}
+		return s.runGitleaks(root, configPath)
+	case ScanEngineBuiltin:
+		return s.runBuiltin(root, configPath)
+	default:
+		return nil, Errorf(ErrUsage, "secret_scan.unsupported_engine", s.Engine, ScanEngineAuto, ScanEngineGitleaks, ScanEngineBuiltin)
+	}
+}
+
+// gitRoot 获取仓库根目录
+func gitRoot(path string) (string, error) {
+	root, err := runGit(path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", Errorf(ErrConfig, "git_hooks.not_repo", path)
+	}
+	return strings.TrimSpace(root), nil
+}
+
+// WriteBaseline 使用内置扫描器扫描暂存区中所有文件，把当前的发现写入基线文件
+// 基线文件会提交到仓库，写入前隐藏密钥，匹配时只使用指纹
+func (s *SecretScanner) WriteBaseline() (*ScanResult, error) {
+	root, err := gitRoot(s.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	if s.BaselinePath == "" {
+		s.BaselinePath = SecretsBaselineFile
+	}
+	if !filepath.IsAbs(s.BaselinePath) {
+		s.BaselinePath = filepath.Join(root, s.BaselinePath)
+	}
+
+	scanner := *s
+	scanner.Engine = ScanEngineBuiltin
+	scanner.All = true
+	scanner.Since = ""
+	scanner.ReportPath = ""
+	result, err := scanner.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]gitleaks.Finding, len(result.Findings))
+	for i, finding := range result.Findings {
+		redacted[i] = finding.Redacted()
+	}
+	if err := gitleaks.WriteJSONFile(s.BaselinePath, redacted); err != nil {
+		return nil, Errorf(ErrIO, "secret_scan.write_report", err)
+	}
+	return result, nil
+}
+
+// runGitleaks 暂存区调用 gitleaks protect --staged，提交范围调用 gitleaks detect --log-opts
+// 发现敏感信息时 gitleaks 以退出码 1 退出
+func (s *SecretScanner) runGitleaks(root, configPath string) (*ScanResult, error) {
+	args := []string{"protect", "--staged", "--verbose"}
+	if s.Since != "" {
+		args = []string{"detect", "--source", ".", "--log-opts", logRange(s.Since), "--verbose"}
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		args = append(args, "--config", configPath)
+	}
+	// 基线中的密钥已隐藏，gitleaks 需要同样隐藏发现中的密钥后再与基线比较
+	if s.BaselinePath != "" {
+		if _, err := os.Stat(s.BaselinePath); err == nil {
+			args = append(args, "--baseline-path", s.BaselinePath, "--redact")
+		}
+	}
+	if s.ReportPath != "" {
+		args = append(args, "--report-format", "json", "--report-path", s.ReportPath)
+	}
+
+	cmd := exec.Command("gitleaks", args...)
+	cmd.Dir = root
+	cmd.Stdout = defaultReporter.Output()
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return &ScanResult{Engine: ScanEngineGitleaks}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return &ScanResult{Engine: ScanEngineGitleaks, Leaks: true}, nil
+	default:
+		return nil, Errorf(ErrGit, "secret_scan.gitleaks_failed", err)
+	}
+}
+
+// runBuiltin 使用内置扫描器扫描新增的行，已在基线中的发现不再报告
+func (s *SecretScanner) runBuiltin(root, configPath string) (*ScanResult, error) {
+	cfg, err := loadScanConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	detector, err := gitleaks.NewDetector(cfg)
+	if err != nil {
+		return nil, Errorf(ErrConfig, "errors.context", configPath, err)
+	}
+	// 重新生成基线时不使用旧的基线
+	baseline := make(map[string]bool)
+	if !s.All {
+		if baseline, err = loadBaseline(s.BaselinePath); err != nil {
+			return nil, err
+		}
+	}
+	ignored, err := loadGitleaksIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []scanTarget
+	switch {
+	case s.All:
+		targets, err = trackedTargets(root)
+	case s.Since != "":
+		targets, err = commitTargets(root, s.Since)
+	default:
+		targets, err = stagedTargets(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 基线文件是扫描报告，旧版本写入的基线中还有未隐藏的密钥，不扫描
+	baselineFile := ""
+	if s.BaselinePath != "" {
+		if rel, err := filepath.Rel(root, s.BaselinePath); err == nil {
+			baselineFile = filepath.ToSlash(rel)
+		}
+	}
+
+	result := &ScanResult{Engine: ScanEngineBuiltin, Findings: []gitleaks.Finding{}}
+	for _, target := range targets {
+		if target.file == baselineFile {
+			continue
+		}
+		findings, err := scanTargetFile(root, detector, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, finding := range findings {
+			// 与 gitleaks 一致，.gitleaksignore 中的指纹可以带或不带提交
+			if ignored[finding.Fingerprint] || ignored[fmt.Sprintf("%s:%s:%d", finding.File, finding.RuleID, finding.StartLine)] {
+				continue
+			}
+			if baseline[baselineKey(finding)] {
+				result.Baselined++
+				continue
+			}
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+	result.Leaks = len(result.Findings) > 0
+
+	if s.ReportPath != "" {
+		if err := gitleaks.WriteJSONFile(s.ReportPath, result.Findings); err != nil {
+			return nil, Errorf(ErrIO, "secret_scan.write_report", err)
+		}
+	}
+	return result, nil
+}
+
+// scanTargetFile 扫描文件的完整内容，只保留落在新增行中的发现，行号因此与文件一致
+func scanTargetFile(root string, detector *gitleaks.Detector, target scanTarget) ([]gitleaks.Finding, error) {
+	object := ":" + target.file
+	fragment := gitleaks.Fragment{FilePath: target.file}
+	if target.commit != nil {
+		object = target.commit.sha + ":" + target.file
+		fragment.CommitSHA = target.commit.sha
+	}
+
+	content, err := runGit(root, "cat-file", "blob", object)
+	if err != nil {
+		return nil, i18n.Errorf("secret_ignore.read_failed", object, err)
+	}
+	if isBinaryContent(content) {
+		return nil, nil
+	}
+	fragment.Raw = content
+
+	var findings []gitleaks.Finding
+	for _, finding := range detector.Detect(fragment) {
+		if !target.touches(finding.StartLine, finding.EndLine) {
+			continue
+		}
+		if c := target.commit; c != nil {
+			finding.Author, finding.Email, finding.Date, finding.Message = c.author, c.email, c.date, c.message
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// touches 判断发现的行范围是否包含新增的行，只匹配路径的发现（行号为 0）视为包含
+func (t scanTarget) touches(start, end int) bool {
+	if t.whole || start == 0 {
+		return true
+	}
+	for _, r := range t.added {
+		if start <= r.end && end >= r.start {
+			return true
+		}
+	}
+	return false
+}
+
+// stagedTargets 获取暂存区中新增、复制、修改和重命名的文件及其新增的行
+func stagedTargets(root string) ([]scanTarget, error) {
+	out, err := runGit(root, "diff", "--cached", "-U0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, i18n.Errorf("secret_scan.staged_diff", err)
+	}
+	return parseAddedLines(out, nil), nil
+}
+
+// trackedTargets 获取暂存区中的所有文件
+func trackedTargets(root string) ([]scanTarget, error) {
+	out, err := runGit(root, "ls-files", "-z")
+	if err != nil {
+		return nil, i18n.Errorf("secret_scan.list_files", err)
+	}
+	var targets []scanTarget
+	for _, file := range strings.Split(out, "\x00") {
+		if file != "" {
+			targets = append(targets, scanTarget{file: file, whole: true})
+		}
+	}
+	return targets, nil
+}
+
+// commitTargets 获取 <since>..HEAD 之间每个非合并提交新增的行
+func commitTargets(root, since string) ([]scanTarget, error) {
+	out, err := runGit(root, "log", "--no-merges", "--format=%H%x00%an%x00%ae%x00%aI%x00%B%x1e", logRange(since))
+	if err != nil {
+		return nil, i18n.Errorf("secret_scan.list_commits", err)
+	}
+
+	var targets []scanTarget
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		commit := &commitInfo{sha: fields[0], author: fields[1], email: fields[2], date: fields[3], message: strings.TrimSpace(fields[4])}
+
+		diff, err := runGit(root, "diff-tree", "-r", "-p", "-U0", "--root", "--no-commit-id", "--no-color", "--no-ext-diff",
+			"--src-prefix=a/", "--dst-prefix=b/", "--diff-filter=ACMR", commit.sha)
+		if err != nil {
+			return nil, i18n.Errorf("secret_scan.commit_diff", commit.sha, err)
+		}
+		targets = append(targets, parseAddedLines(diff, commit)...)
+	}
+	return targets, nil
+}
+
+// parseAddedLines 从 -U0 的统一差异中解析每个文件新增的行
+func parseAddedLines(diff string, commit *commitInfo) []scanTarget {
+	var targets []scanTarget
+	current := -1
+	inHeader := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, inHeader = -1, true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			// 包含空格的路径后面会带一个制表符
+			path := unquoteGitPath(strings.TrimRight(strings.TrimPrefix(line, "+++ "), "\t"))
+			if strings.HasPrefix(path, "b/") {
+				targets = append(targets, scanTarget{commit: commit, file: strings.TrimPrefix(path, "b/")})
+				current = len(targets) - 1
+			}
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if current < 0 || m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count > 0 {
+				targets[current].added = append(targets[current].added, lineRange{start: start, end: start + count - 1})
+			}
+		}
+	}
+
+	// 只删除内容的文件没有新增的行
+	var withAdded []scanTarget
+	for _, target := range targets {
+		if len(target.added) > 0 {
+			withAdded = append(withAdded, target)
+		}
+	}
+	return withAdded
+}
+
+// unquoteGitPath 还原 git 对包含特殊字符的路径加的 C 风格引号
+func unquoteGitPath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
+
+// EmptyTreeID Git 空树对象的 ID，作为 --since 时扫描 HEAD 的全部历史，用于 CI 中分支的第一次推送
+const EmptyTreeID = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// logRange --since 对应的提交范围，从空树开始时为 HEAD 的全部提交（包括根提交）
+func logRange(since string) string {
+	if since == EmptyTreeID {
+		return "HEAD"
+	}
+	return since + "..HEAD"
+}
+
+// loadScanConfig 读取 gitleaks 配置，文件不存在时只使用内置规则
+func loadScanConfig(configPath string) (*gitleaks.Config, error) {
+	cfg, err := gitleaks.Load(configPath)
+	if os.IsNotExist(err) {
+		defaultReporter.Warn(WarnConfigMissing, i18n.T("secret_scan.no_config", configPath))
+		return &gitleaks.Config{Extend: &gitleaks.Extend{UseDefault: true}}, nil
+	}
+	if err != nil {
+		return nil, Errorf(ErrConfig, "errors.context", configPath, err)
+	}
+	if keys := cfg.UnknownKeys(); len(keys) > 0 {
+		defaultReporter.Warn(WarnUnknownConfigKey, i18n.T("secret_scan.unknown_keys", configPath, strings.Join(keys, ", ")))
+	}
+	return cfg, nil
+}
+
+// loadBaseline 读取基线文件中的发现，文件不存在时返回空基线
+func loadBaseline(path string) (map[string]bool, error) {
+	baseline := make(map[string]bool)
+	if path == "" {
+		return baseline, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("secret_scan.read_baseline", err)
+	}
+
+	var findings []gitleaks.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, Errorf(ErrConfig, "secret_scan.parse_baseline", path, err)
+	}
+	for _, finding := range findings {
+		baseline[baselineKey(finding)] = true
+	}
+	return baseline, nil
+}
+
+// baselineKey 按不含提交的指纹（文件、规则和行号）匹配基线，基线中的密钥已隐藏
+func baselineKey(f gitleaks.Finding) string {
+	return fmt.Sprintf("%s:%s:%d", f.File, f.RuleID, f.StartLine)
+}
+
+// isBinaryContent 与 git 相同的判断方式：前 8000 字节中包含 NUL 即视为二进制文件
+func isBinaryContent(content string) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return strings.IndexByte(content, 0) >= 0
+}
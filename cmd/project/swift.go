@@ -1,13 +1,13 @@
 package project
 
 import (
-	"bytes"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // SwiftInitializer Swift项目初始化器
@@ -17,11 +17,13 @@ type SwiftInitializer struct {
 	templates        TemplateManager
 	dependencyHelper *SwiftDependencyHelper
 	config           *LanguageConfig
+	templateManifest *TemplateManifest // 模板清单 template.yaml
+	vars             map[string]string // 模板变量取值
 }
 
 // NewSwiftInitializer 创建Swift项目初始化器
-func NewSwiftInitializer(projectName, projectPath, globalConfigPath, configPath, templateCodePath string, noGit, noCheck bool, remote string) *SwiftInitializer {
-	templateManager := NewFileTemplateManager(templateCodePath)
+func NewSwiftInitializer(templateFS fs.FS, projectName, projectPath, globalConfigPath, configPath, templateCodePath string, noGit, noCheck bool, remote string) *SwiftInitializer {
+	templateManager := NewFileTemplateManager(templateFS, templateCodePath)
 	dependencyHelper := NewSwiftDependencyHelper()
 
 	// 获取Swift配置
@@ -29,9 +31,11 @@ func NewSwiftInitializer(projectName, projectPath, globalConfigPath, configPath,
 
 	return &SwiftInitializer{
 		BaseInitializer: BaseInitializer{
+			TemplateFS:       templateFS,
 			ProjectName:      projectName,
 			FilePath:         projectPath,
 			GlobalConfigPath: globalConfigPath,
+			ConfigLayers:     []string{configPath},
 			ConfigPath:       configPath,
 			TemplateCodePath: templateCodePath,
 			NoGit:            noGit,
@@ -46,31 +50,11 @@ func NewSwiftInitializer(projectName, projectPath, globalConfigPath, configPath,
 
 // CopyTemplateFiles Swift项目特定的模板文件复制
 func (s *SwiftInitializer) CopyTemplateFiles() error {
-	// 先调用父类的方法复制所有文件
+	// 先调用父类的方法复制全局配置和 Swift 配置文件
 	if err := s.BaseInitializer.CopyTemplateFiles(); err != nil {
 		return err
 	}
 
-	// 读取Podfile文件
-	podfilePath := filepath.Join(s.FilePath, "Podfile")
-	content, err := os.ReadFile(podfilePath)
-	if err != nil {
-		return fmt.Errorf("读取Podfile失败：%w", err)
-	}
-
-	// 替换target名称
-	oldContent := string(content)
-	newContent := strings.ReplaceAll(
-		oldContent,
-		"target 'XXXX' do",
-		fmt.Sprintf("target '%s' do", s.ProjectName),
-	)
-
-	// 写回文件
-	if err := os.WriteFile(podfilePath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("更新Podfile失败：%w", err)
-	}
-
 	// 创建项目文件
 	if err := s.createProjectFiles(); err != nil {
 		return err
@@ -86,117 +70,86 @@ func (s *SwiftInitializer) CreateProject() error {
 		return err
 	}
 
-	fmt.Println("正在生成 Xcode 项目...")
-
-	// 使用 xcodegen 生成项目文件
-	cmd := exec.Command("xcodegen", "generate", "--spec", "project.yml")
-	cmd.Dir = s.FilePath
-
-	// 捕获命令的输出
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = io.MultiWriter(os.Stdout, &stdout)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
-
-	if err := cmd.Run(); err != nil {
-		// 如果命令执行失败，返回详细的错误信息
-		return fmt.Errorf("生成 Xcode 项目失败：\n错误：%v\n输出：%s\n错误输出：%s",
-			err, stdout.String(), stderr.String())
+	// 执行 template.yaml 中的生成后命令（xcodegen generate）
+	if err := s.loadTemplate(); err != nil {
+		return err
+	}
+	commands, err := s.templateManifest.postGenerateCommands(s.vars)
+	if err != nil {
+		return i18n.Errorf("swift.generate_failed", err)
+	}
+	if err := s.runPostGenerate(commands); err != nil {
+		return i18n.Errorf("swift.generate_failed", err)
 	}
 
-	fmt.Println("✅ Xcode 项目生成成功！")
+	s.report().Progress("✅", i18n.T("swift.generated"))
 	return nil
 }
 
 // checkDependencies 检查所有依赖
 func (s *SwiftInitializer) checkDependencies() error {
-	fmt.Println("🔍 检查依赖...")
+	s.report().Progress("🔍", i18n.T("swift.checking_deps"))
 
 	checker := NewCommandDependencyChecker()
 	missing := checker.GetMissingDependencies(s.config.RequiredCommands)
 
 	if len(missing) == 0 {
-		fmt.Println("  ✅ 所有依赖都已安装")
+		s.report().Success(i18n.T("initializer.deps_installed"))
 		return nil
 	}
 
-	fmt.Printf("  ⚠️  缺少依赖: %s\n", strings.Join(missing, ", "))
+	s.report().Warn(WarnDependencyMissing, i18n.T("initializer.deps_missing", strings.Join(missing, ", ")))
 
 	// 尝试自动安装 xcodegen
 	for _, cmd := range missing {
 		if cmd == "xcodegen" {
-			fmt.Println("  🔧 尝试自动安装 xcodegen...")
+			s.report().Info("  🔧 " + i18n.T("swift.installing_xcodegen"))
 			if err := s.dependencyHelper.CheckAndInstallXcodegen(); err != nil {
 				return err
 			}
 		} else {
 			// 对于其他依赖，提供安装说明
-			fmt.Printf("  💡 %s\n", GetInstallationInstructions(cmd))
+			s.report().Hint(GetInstallationInstructions(cmd))
 		}
 	}
 
 	// 再次检查是否还有缺失的依赖
 	stillMissing := checker.GetMissingDependencies(s.config.RequiredCommands)
 	if len(stillMissing) > 0 {
-		return fmt.Errorf("仍然缺少依赖: %s", strings.Join(stillMissing, ", "))
+		return i18n.Errorf("swift.still_missing", strings.Join(stillMissing, ", "))
 	}
 
 	return nil
 }
 
-// createProjectFiles 创建项目所需的所有文件
+// createProjectFiles 根据 template.yaml 创建项目所需的所有文件
 func (s *SwiftInitializer) createProjectFiles() error {
-	// 创建项目源代码目录
-	srcDir := filepath.Join(s.FilePath, s.ProjectName)
-	if err := os.MkdirAll(srcDir, 0755); err != nil {
-		return fmt.Errorf("创建源代码目录失败：%w", err)
-	}
-
-	// 定义所有需要创建的文件
-	files := map[string]string{
-		"Info.plist":              "Info.plist",
-		"AppDelegate.swift":       "AppDelegate.swift",
-		"ViewController.swift":    "ViewController.swift",
-		"Main.storyboard":         "Main.storyboard",
-		"LaunchScreen.storyboard": "LaunchScreen.storyboard",
-	}
-
-	// 检查模板文件是否存在
-	for _, tplName := range files {
-		if !s.templates.TemplateExists(tplName) {
-			return fmt.Errorf("模板文件不存在: %s。请检查模板目录: %s", tplName, s.TemplateCodePath)
-		}
+	if err := s.loadTemplate(); err != nil {
+		return err
 	}
+	return s.generateProjectFiles(s.templates, s.templateManifest, s.vars)
+}
 
-	fmt.Println("📄 创建项目文件...")
-
-	// 写入所有文件
-	for filename, tplName := range files {
-		fmt.Printf("  - 创建 %s\n", filename)
-		content, err := s.templates.LoadTemplateCode(tplName)
-		if err != nil {
-			return fmt.Errorf("加载模板 %s 失败：%w", tplName, err)
-		}
-		filePath := filepath.Join(srcDir, filename)
-		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("创建文件 %s 失败：%w", filename, err)
-		}
+// SetTemplateValues 设置模板变量的取值，并立即确定所有变量，以便在执行任何步骤前完成输入和校验
+func (s *SwiftInitializer) SetTemplateValues(values map[string]string, prompter *Prompter) error {
+	if err := s.BaseInitializer.SetTemplateValues(values, prompter); err != nil {
+		return err
 	}
+	s.templateManifest = nil
+	return s.loadTemplate()
+}
 
-	// 检查 project.yml 模板
-	if !s.templates.TemplateExists("project.yml") {
-		return fmt.Errorf("模板文件不存在: project.yml。请检查模板目录: %s", s.TemplateCodePath)
+// loadTemplate 读取模板清单并确定变量取值
+func (s *SwiftInitializer) loadTemplate() error {
+	if s.templateManifest != nil {
+		return nil
 	}
 
-	// 写入 project.yml 文件，替换变量
-	fmt.Println("  - 创建 project.yml")
-	content, err := s.templates.RenderTemplateCode("project.yml", map[string]string{"PROJECT_NAME": s.ProjectName})
+	manifest, vars, err := s.resolveTemplate()
 	if err != nil {
-		return fmt.Errorf("渲染 project.yml 失败：%w", err)
-	}
-	filePath := filepath.Join(s.FilePath, "project.yml")
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("创建 project.yml 失败：%w", err)
+		return err
 	}
+	s.templateManifest, s.vars = manifest, vars
 	return nil
 }
 
@@ -204,10 +157,10 @@ func (s *SwiftInitializer) createProjectFiles() error {
 func (s *SwiftInitializer) InitDependencies() error {
 	// 显示代码审查工具说明（仅在启用代码审查时）
 	if !s.NoCheck {
-		fmt.Println("💡 代码审查工具说明：")
-		fmt.Println("  - SwiftLint: 代码风格检查，会在构建时自动运行")
-		fmt.Println("  - gitleaks: 敏感信息检查，通过Git钩子运行")
-		fmt.Println("  - 手动运行SwiftLint: swiftlint lint")
+		s.report().Progress("💡", i18n.T("initializer.review_tools"))
+		s.report().Detail(i18n.T("swift.tool_swiftlint"))
+		s.report().Detail(i18n.T("initializer.tool_gitleaks"))
+		s.report().Detail(i18n.T("swift.tool_manual"))
 	}
 
 	return nil
@@ -216,33 +169,33 @@ func (s *SwiftInitializer) InitDependencies() error {
 // ConfigureCodeReview Swift项目特定的代码审查配置
 func (s *SwiftInitializer) ConfigureCodeReview() error {
 	if s.NoCheck {
-		fmt.Println("⏭️  跳过代码审查配置 (使用了--no-check参数)")
+		s.report().Progress("⏭️ ", i18n.T("add_initializer.skip_review"))
 		return nil
 	}
 
-	fmt.Println("🔍 配置Swift代码审查工具...")
+	s.report().Progress("🔍", i18n.T("swift.configuring"))
 
 	// 1. 检查SwiftLint配置文件是否存在
 	swiftlintPath := filepath.Join(s.FilePath, ".swiftlint.yml")
 	if _, err := os.Stat(swiftlintPath); os.IsNotExist(err) {
-		fmt.Printf("  ⚠️  SwiftLint配置文件不存在: %s\n", swiftlintPath)
-		fmt.Println("  💡 提示：配置文件应该在复制模板文件时创建")
+		s.report().Warn(WarnFileMissing, i18n.T("swift.config_missing", swiftlintPath))
+		s.report().Hint(i18n.T("initializer.config_hint"))
 	} else {
-		fmt.Println("  ✅ SwiftLint配置文件已存在")
+		s.report().Success(i18n.T("swift.config_exists"))
 	}
 
 	// 2. 向Podfile添加SwiftLint依赖
 	if err := s.addSwiftLintToPodfile(); err != nil {
-		return fmt.Errorf("添加SwiftLint依赖失败: %w", err)
+		return i18n.Errorf("swift.add_dependency_failed", err)
 	}
 
 	// 3. 向project.yml添加SwiftLint构建脚本
 	if err := s.addSwiftLintToProjectYml(); err != nil {
-		return fmt.Errorf("添加SwiftLint脚本失败: %w", err)
+		return i18n.Errorf("swift.add_script_failed", err)
 	}
 
-	fmt.Println("  ✅ 代码审查配置完成")
-	fmt.Println("  💡 提示：依赖将在下一步安装")
+	s.report().Success(i18n.T("initializer.review_done"))
+	s.report().Hint(i18n.T("swift.deps_next"))
 
 	return nil
 }
@@ -254,14 +207,14 @@ func (s *SwiftInitializer) addSwiftLintToPodfile() error {
 	// 读取现有Podfile内容
 	content, err := os.ReadFile(podfilePath)
 	if err != nil {
-		return fmt.Errorf("读取Podfile失败: %w", err)
+		return i18n.Errorf("swift.read_podfile", err)
 	}
 
 	podfileContent := string(content)
 
 	// 检查是否已经包含SwiftLint依赖
 	if strings.Contains(podfileContent, "SwiftLint") {
-		fmt.Println("  ✅ Podfile已包含SwiftLint依赖")
+		s.report().Success(i18n.T("swift.podfile_configured"))
 		return nil
 	}
 
@@ -269,7 +222,7 @@ func (s *SwiftInitializer) addSwiftLintToPodfile() error {
 	// 查找"use_frameworks!"后面的位置
 	useFrameworksPos := strings.Index(podfileContent, "use_frameworks!")
 	if useFrameworksPos == -1 {
-		return fmt.Errorf("Podfile格式不正确，未找到use_frameworks!")
+		return i18n.Errorf("swift.podfile_malformed")
 	}
 
 	// 查找use_frameworks!所在行的结尾
@@ -285,11 +238,11 @@ func (s *SwiftInitializer) addSwiftLintToPodfile() error {
 	newContent := podfileContent[:lineEndPos] + swiftlintDependency + podfileContent[lineEndPos:]
 
 	// 写回文件
-	if err := os.WriteFile(podfilePath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("写入Podfile失败: %w", err)
+	if err := s.writeFile(podfilePath, []byte(newContent), 0644, ""); err != nil {
+		return i18n.Errorf("swift.write_podfile", err)
 	}
 
-	fmt.Println("  ✅ 已向Podfile添加SwiftLint依赖")
+	s.report().Success(i18n.T("swift.podfile_added"))
 	return nil
 }
 
@@ -300,14 +253,14 @@ func (s *SwiftInitializer) addSwiftLintToProjectYml() error {
 	// 读取现有project.yml内容
 	content, err := os.ReadFile(projectYmlPath)
 	if err != nil {
-		return fmt.Errorf("读取project.yml失败: %w", err)
+		return i18n.Errorf("swift.read_project", err)
 	}
 
 	projectYmlContent := string(content)
 
 	// 检查是否已经包含SwiftLint脚本
 	if strings.Contains(projectYmlContent, "SwiftLint") {
-		fmt.Println("  ✅ project.yml已包含SwiftLint脚本")
+		s.report().Success(i18n.T("swift.project_configured"))
 		return nil
 	}
 
@@ -315,7 +268,7 @@ func (s *SwiftInitializer) addSwiftLintToProjectYml() error {
 	dependenciesEndPattern := "- sdk: AVFoundation.framework"
 	dependenciesPos := strings.Index(projectYmlContent, dependenciesEndPattern)
 	if dependenciesPos == -1 {
-		return fmt.Errorf("project.yml格式不正确，未找到dependencies部分")
+		return i18n.Errorf("swift.project_malformed")
 	}
 
 	// 查找该行的结尾
@@ -340,11 +293,11 @@ func (s *SwiftInitializer) addSwiftLintToProjectYml() error {
 	newContent := projectYmlContent[:lineEndPos] + swiftlintScript + projectYmlContent[lineEndPos:]
 
 	// 写回文件
-	if err := os.WriteFile(projectYmlPath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("写入project.yml失败: %w", err)
+	if err := s.writeFile(projectYmlPath, []byte(newContent), 0644, ""); err != nil {
+		return i18n.Errorf("swift.write_project", err)
 	}
 
-	fmt.Println("  ✅ 已向project.yml添加SwiftLint构建脚本（使用CocoaPods路径）")
+	s.report().Success(i18n.T("swift.project_added"))
 	return nil
 }
 
@@ -353,11 +306,11 @@ func (s *SwiftInitializer) verifySwiftLintInProjectYml() error {
 	projectYmlPath := filepath.Join(s.FilePath, "project.yml")
 	content, err := os.ReadFile(projectYmlPath)
 	if err != nil {
-		return fmt.Errorf("读取project.yml失败: %w", err)
+		return i18n.Errorf("swift.read_project", err)
 	}
 
 	if !strings.Contains(string(content), "SwiftLint") {
-		return fmt.Errorf("project.yml中未找到SwiftLint构建脚本")
+		return i18n.Errorf("swift.script_missing")
 	}
 
 	return nil
@@ -367,15 +320,19 @@ func (s *SwiftInitializer) verifySwiftLintInProjectYml() error {
 func (s *SwiftInitializer) ShowNextSteps() {
 	s.BaseInitializer.ShowNextSteps()
 
-	fmt.Println("\n下一步：")
+	steps := []string{i18n.T("swift.next_install"), i18n.T("swift.next_open")}
 	if !s.NoCheck {
-		fmt.Println("1. 安装依赖（包含SwiftLint）：pod install")
-		fmt.Println("2. 使用 Xcode 打开 .xcworkspace 文件")
-		fmt.Println("3. 设置开发者团队ID在project.yml中")
-		fmt.Println("4. 运行项目，SwiftLint会自动检查代码风格")
-	} else {
-		fmt.Println("1. 安装依赖：pod install")
-		fmt.Println("2. 使用 Xcode 打开 .xcworkspace 文件")
-		fmt.Println("3. 设置开发者团队ID在project.yml中")
+		steps[0] = i18n.T("swift.next_install_lint")
+	}
+	if s.vars["DevelopmentTeam"] == "" {
+		steps = append(steps, i18n.T("swift.next_team"))
+	}
+	if !s.NoCheck {
+		steps = append(steps, i18n.T("swift.next_run"))
+	}
+
+	s.report().Info("\n" + i18n.T("swift.next"))
+	for i, step := range steps {
+		s.report().Info(fmt.Sprintf("%d. %s", i+1, step))
 	}
 }
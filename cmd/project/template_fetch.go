@@ -0,0 +1,427 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"devex/cmd/i18n"
+)
+
+// 模板源的写法：
+//   - 本地目录：/path/to/templates、./templates
+//   - 本地或远程压缩包：templates.tar.gz、https://example.com/templates.tar.gz（也支持 .tgz）
+//   - Git 仓库：https://github.com/org/templates.git#v1.2.0、git@github.com:org/templates.git、
+//     file:///srv/templates.git#main，# 后为分支、标签或完整的提交 ID，省略时使用默认分支；
+//     地址不以 .git 结尾时可以加 git+ 前缀，如 git+https://example.com/templates#main
+//
+// 远程模板下载后按内容摘要存放在用户缓存目录（如 ~/.cache/devex/templates/<摘要>），
+// 固定了摘要且缓存中已存在时不再下载。摘要只与模板内容有关，与来源类型无关
+
+// TemplateSourceEnv 指定模板源的环境变量
+const TemplateSourceEnv = "DEVEX_TEMPLATE"
+
+// TemplateChecksumEnv 指定模板源固定摘要的环境变量
+const TemplateChecksumEnv = "DEVEX_TEMPLATE_SHA256"
+
+// templateFetchTimeout 下载压缩包的超时时间
+const templateFetchTimeout = 2 * time.Minute
+
+// templateHTTPClient 下载压缩包使用的 HTTP 客户端
+var templateHTTPClient = &http.Client{Timeout: templateFetchTimeout}
+
+// 模板源类型
+const (
+	SourceTypeLocal   = "local"
+	SourceTypeGit     = "git"
+	SourceTypeTarball = "tarball"
+)
+
+// TemplateSource 模板来源
+type TemplateSource struct {
+	Spec     string // 原始写法
+	Type     string // local、git 或 tarball
+	Location string // 本地路径或远程地址
+	Ref      string // Git 分支、标签或提交
+}
+
+// ParseTemplateSource 解析模板源写法
+func ParseTemplateSource(spec string) (*TemplateSource, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, i18n.Errorf("template_fetch.empty")
+	}
+
+	source := &TemplateSource{Spec: spec}
+	location := spec
+	forceGit := false
+	if strings.HasPrefix(location, "git+") {
+		location = strings.TrimPrefix(location, "git+")
+		forceGit = true
+	}
+
+	remote := strings.Contains(location, "://") || isSCPLikeURL(location)
+	if !remote {
+		if forceGit {
+			return nil, i18n.Errorf("template_fetch.invalid_git", spec)
+		}
+		source.Location = location
+		source.Type = SourceTypeLocal
+		if isTarball(location) {
+			source.Type = SourceTypeTarball
+		}
+		return source, nil
+	}
+
+	if i := strings.LastIndex(location, "#"); i >= 0 {
+		location, source.Ref = location[:i], location[i+1:]
+	}
+	source.Location = location
+
+	switch {
+	case forceGit:
+		source.Type = SourceTypeGit
+	case isTarball(location):
+		if source.Ref != "" {
+			return nil, i18n.Errorf("template_fetch.archive_ref", spec)
+		}
+		if !strings.HasPrefix(location, "https://") && !strings.HasPrefix(location, "http://") {
+			return nil, i18n.Errorf("template_fetch.archive_location", spec)
+		}
+		source.Type = SourceTypeTarball
+	default:
+		source.Type = SourceTypeGit
+	}
+	return source, nil
+}
+
+// isSCPLikeURL 判断是否为 git@host:path 形式的地址
+func isSCPLikeURL(s string) bool {
+	at := strings.Index(s, "@")
+	colon := strings.Index(s, ":")
+	return at > 0 && colon > at && !strings.Contains(s[:colon], "/")
+}
+
+// isTarball 判断路径是否为 tar.gz 压缩包
+func isTarball(s string) bool {
+	if i := strings.IndexAny(s, "?#"); i >= 0 && strings.Contains(s, "://") {
+		s = s[:i]
+	}
+	return strings.HasSuffix(s, ".tar.gz") || strings.HasSuffix(s, ".tgz")
+}
+
+// Fetch 获取模板源，返回模板根目录及内容摘要
+// 本地目录直接使用，其他来源下载并解压到缓存目录；checksum 不为空时校验内容摘要
+// 通过明文 http:// 下载的压缩包可能被篡改，必须固定摘要
+func (s *TemplateSource) Fetch(checksum string) (string, string, error) {
+	want, err := normalizeChecksum(checksum)
+	if err != nil {
+		return "", "", err
+	}
+	if s.Type == SourceTypeTarball && strings.HasPrefix(s.Location, "http://") && want == "" {
+		return "", "", i18n.Errorf("template_fetch.insecure_archive", s.Spec, TemplateChecksumEnv)
+	}
+
+	if s.Type == SourceTypeLocal {
+		info, err := os.Stat(s.Location)
+		if err != nil {
+			return "", "", i18n.Errorf("template_fetch.unavailable", err)
+		}
+		if !info.IsDir() {
+			return "", "", i18n.Errorf("template_fetch.not_dir_or_archive", s.Location)
+		}
+		digest, err := hashTemplateDir(s.Location)
+		if err != nil {
+			return "", "", err
+		}
+		if want != "" && digest != want {
+			return "", "", checksumMismatch(s.Spec, want, digest)
+		}
+		return s.Location, digest, nil
+	}
+
+	cacheRoot, err := templateCacheDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	// 固定了摘要且缓存命中时直接使用，不访问网络
+	if want != "" {
+		cached := filepath.Join(cacheRoot, strings.TrimPrefix(want, "sha256:"))
+		if info, err := os.Stat(cached); err == nil && info.IsDir() {
+			return cached, want, nil
+		}
+	}
+
+	if err := os.MkdirAll(cacheRoot, 0755); err != nil {
+		return "", "", i18n.Errorf("template_fetch.create_cache", err)
+	}
+	tmp, err := os.MkdirTemp(cacheRoot, ".fetch-")
+	if err != nil {
+		return "", "", i18n.Errorf("template_fetch.create_cache", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	switch s.Type {
+	case SourceTypeGit:
+		err = fetchGitTemplate(s.Location, s.Ref, tmp)
+	case SourceTypeTarball:
+		err = fetchTarballTemplate(s.Location, tmp)
+	default:
+		err = i18n.Errorf("template_fetch.unknown_type", s.Type)
+	}
+	if err != nil {
+		return "", "", i18n.Errorf("template_fetch.fetch_failed", s.Spec, err)
+	}
+
+	root, err := templateContentRoot(tmp)
+	if err != nil {
+		return "", "", err
+	}
+	digest, err := hashTemplateDir(root)
+	if err != nil {
+		return "", "", err
+	}
+	if want != "" && digest != want {
+		return "", "", checksumMismatch(s.Spec, want, digest)
+	}
+
+	cached := filepath.Join(cacheRoot, strings.TrimPrefix(digest, "sha256:"))
+	if _, err := os.Stat(cached); err == nil {
+		return cached, digest, nil
+	}
+	if err := os.Rename(root, cached); err != nil {
+		// 并发获取同一模板时可能已被其他进程写入
+		if _, statErr := os.Stat(cached); statErr == nil {
+			return cached, digest, nil
+		}
+		return "", "", i18n.Errorf("template_fetch.write_cache", err)
+	}
+	return cached, digest, nil
+}
+
+// templateCacheDir 模板缓存目录
+func templateCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", i18n.Errorf("template_fetch.cache_dir", err)
+	}
+	return filepath.Join(dir, "devex", "templates"), nil
+}
+
+// commitIDPattern 完整的提交 ID（SHA-1 或 SHA-256）
+var commitIDPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// fetchGitTemplate 检出 Git 仓库到 dst，ref 为空时使用默认分支
+// 分支和标签浅克隆；提交需要写完整的提交 ID，只浅获取该提交，不克隆完整历史
+func fetchGitTemplate(url, ref, dst string) error {
+	if commitIDPattern.MatchString(strings.ToLower(ref)) {
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		if _, err := runGit(dst, "init", "--quiet"); err != nil {
+			return err
+		}
+		if _, err := runGit(dst, "remote", "add", "origin", "--", url); err != nil {
+			return err
+		}
+		if _, err := runGit(dst, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+			return i18n.Errorf("template_fetch.ref_not_found", ref, err)
+		}
+		if _, err := runGit(dst, "checkout", "--quiet", "--detach", "FETCH_HEAD"); err != nil {
+			return err
+		}
+		return os.RemoveAll(filepath.Join(dst, ".git"))
+	}
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	// -- 之后的参数不会被当作选项，避免以 - 开头的地址注入 git 参数
+	if _, err := runGit("", append(args, "--", url, dst)...); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dst, ".git"))
+}
+
+// runGit 执行 git 命令，失败时返回包含输出的错误
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", Errorf(ErrGit, "template_fetch.git_output", args[0], msg)
+		}
+		return "", Errorf(ErrGit, "template_fetch.git_failed", args[0], err)
+	}
+	return string(out), nil
+}
+
+// fetchTarballTemplate 下载或读取 tar.gz 压缩包并解压到 dst
+func fetchTarballTemplate(location, dst string) error {
+	if !strings.Contains(location, "://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return extractTarGz(f, dst)
+	}
+
+	resp, err := templateHTTPClient.Get(location)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return i18n.Errorf("template_fetch.download_failed", resp.Status)
+	}
+	return extractTarGz(resp.Body, dst)
+}
+
+// extractTarGz 解压 tar.gz 到 dst，只解压普通文件和目录，拒绝指向 dst 之外的路径
+func extractTarGz(r io.Reader, dst string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return i18n.Errorf("template_fetch.extract_failed", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return i18n.Errorf("template_fetch.extract_failed", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return i18n.Errorf("template_fetch.illegal_path", hdr.Name)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			mode := os.FileMode(0644)
+			if hdr.Mode&0111 != 0 {
+				mode = 0755
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+		// 符号链接等其他类型直接忽略
+	}
+}
+
+// templateContentRoot 压缩包只包含一个顶层目录时（如 GitHub 生成的 repo-v1.0/），以该目录作为模板根目录
+func templateContentRoot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+// hashTemplateDir 计算模板目录的内容摘要，包含每个文件的相对路径、可执行位和内容，忽略 .git 目录
+func hashTemplateDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		mode := "644"
+		if info.Mode()&0111 != 0 {
+			mode = "755"
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(h, "%s\x00%s\x00%x\n", filepath.ToSlash(rel), mode, sum)
+		return nil
+	})
+	if err != nil {
+		return "", i18n.Errorf("template_fetch.digest_failed", err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizeChecksum 统一摘要写法为 sha256:<hex>
+func normalizeChecksum(checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum == "" {
+		return "", nil
+	}
+	hexPart := strings.TrimPrefix(checksum, "sha256:")
+	if _, err := hex.DecodeString(hexPart); err != nil || len(hexPart) != sha256.Size*2 {
+		return "", i18n.Errorf("template_fetch.bad_checksum", checksum)
+	}
+	return "sha256:" + hexPart, nil
+}
+
+// checksumMismatch 摘要不一致的错误
+func checksumMismatch(spec, want, got string) error {
+	return i18n.Errorf("template_fetch.checksum_mismatch", spec, want, got)
+}
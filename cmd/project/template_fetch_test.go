@@ -0,0 +1,277 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseTemplateSource(t *testing.T) {
+	tests := []struct {
+		spec     string
+		typ      string
+		location string
+		ref      string
+		wantErr  bool
+	}{
+		{spec: "./templates", typ: SourceTypeLocal, location: "./templates"},
+		{spec: "templates.tar.gz", typ: SourceTypeTarball, location: "templates.tar.gz"},
+		{spec: "https://example.com/t.tgz", typ: SourceTypeTarball, location: "https://example.com/t.tgz"},
+		{spec: "https://github.com/org/t.git#v1.2.0", typ: SourceTypeGit, location: "https://github.com/org/t.git", ref: "v1.2.0"},
+		{spec: "git@github.com:org/t.git", typ: SourceTypeGit, location: "git@github.com:org/t.git"},
+		{spec: "git+https://example.com/t#main", typ: SourceTypeGit, location: "https://example.com/t", ref: "main"},
+		{spec: "https://example.com/t.tar.gz#v1", wantErr: true},
+		{spec: "git+./templates", wantErr: true},
+		{spec: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			source, err := ParseTemplateSource(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source.Type != tt.typ || source.Location != tt.location || source.Ref != tt.ref {
+				t.Errorf("got %+v", source)
+			}
+		})
+	}
+}
+
+func TestFetchGitTemplate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// 两个提交的模板仓库：v1 标签指向第一个提交
+	work := t.TempDir()
+	initGitRepo(t, work)
+	writeTestFile(t, filepath.Join(work, "global_config", "version"), "1\n")
+	writeTestFile(t, filepath.Join(work, "README.md"), "templates\n")
+	runTestGit(t, work, "add", ".")
+	runTestGit(t, work, "commit", "--quiet", "-m", "v1")
+	runTestGit(t, work, "tag", "v1")
+	first := strings.TrimSpace(runTestGit(t, work, "rev-parse", "HEAD"))
+	writeTestFile(t, filepath.Join(work, "global_config", "version"), "2\n")
+	runTestGit(t, work, "commit", "--quiet", "-am", "v2")
+
+	bare := filepath.Join(t.TempDir(), "templates.git")
+	runTestGit(t, work, "clone", "--quiet", "--bare", work, bare)
+	url := "file://" + filepath.ToSlash(bare)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "default branch", want: "2\n"},
+		{name: "branch", ref: "main", want: "2\n"},
+		{name: "tag", ref: "v1", want: "1\n"},
+		{name: "commit", ref: first, want: "1\n"},
+		{name: "abbreviated commit", ref: first[:12], wantErr: true},
+		{name: "missing branch", ref: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := url
+			if tt.ref != "" {
+				spec += "#" + tt.ref
+			}
+			source, err := ParseTemplateSource(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir, digest, err := source.Fetch("")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "global_config", "version"))
+			if err != nil || string(got) != tt.want {
+				t.Errorf("version = %q, %v, want %q", got, err, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+				t.Errorf(".git should be removed from the cache")
+			}
+			if !strings.HasSuffix(dir, strings.TrimPrefix(digest, "sha256:")) {
+				t.Errorf("cache dir %s is not named after digest %s", dir, digest)
+			}
+		})
+	}
+}
+
+func TestFetchTarballTemplate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	archive := tarGz(t, map[string]string{
+		"templates-1.0/global_config/.gitignore":    ".DS_Store\n",
+		"templates-1.0/swift/config/.swiftlint.yml": "only_rules: []\n",
+	})
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(archive)
+	}))
+	defer server.Close()
+	defer func(client *http.Client) { templateHTTPClient = client }(templateHTTPClient)
+	templateHTTPClient = server.Client()
+
+	source, err := ParseTemplateSource(server.URL + "/templates.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 首次获取：下载并解压，以单一顶层目录作为模板根目录
+	dir, digest, err := source.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "global_config", ".gitignore")); err != nil {
+		t.Fatalf("template root not unwrapped: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		checksum     string
+		wantErr      bool
+		wantRequests int32 // 获取后累计的请求次数
+	}{
+		{name: "pinned checksum uses cache", checksum: digest, wantRequests: 1},
+		{name: "pinned checksum without prefix", checksum: strings.TrimPrefix(digest, "sha256:"), wantRequests: 1},
+		{name: "checksum mismatch", checksum: "sha256:" + strings.Repeat("0", 64), wantErr: true, wantRequests: 2},
+		{name: "invalid checksum", checksum: "sha256:1234", wantErr: true, wantRequests: 2},
+		{name: "unpinned downloads again", wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDir, gotDigest, err := source.Fetch(tt.checksum)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", gotDir)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if gotDir != dir || gotDigest != digest {
+				t.Errorf("got %s %s, want %s %s", gotDir, gotDigest, dir, digest)
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestFetchTarballOverHTTPRequiresChecksum 明文 http 下载的压缩包必须固定摘要
+func TestFetchTarballOverHTTPRequiresChecksum(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	archive := tarGz(t, map[string]string{"global_config/.gitignore": ".DS_Store\n"})
+	local := filepath.Join(t.TempDir(), "templates.tar.gz")
+	if err := os.WriteFile(local, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := ParseTemplateSource(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, digest, err := source.Fetch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(archive)
+	}))
+	defer server.Close()
+	source, err = ParseTemplateSource(server.URL + "/templates.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dir, _, err := source.Fetch(""); err == nil {
+		t.Fatalf("expected an error, got %s", dir)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("requests = %d, want 0", n)
+	}
+
+	// 固定摘要时允许下载并校验，使用新的缓存目录确保访问网络
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if _, got, err := source.Fetch(digest); err != nil || got != digest {
+		t.Fatalf("pinned fetch: %s, %v", got, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+// TestFetchGitTemplateOptionLikeURL 以 - 开头的地址不会被 git 当作选项
+func TestFetchGitTemplateOptionLikeURL(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	url := "--upload-pack=touch " + marker + ";false"
+	for _, ref := range []string{"", strings.Repeat("a", 40)} {
+		// 目标目录是仓库时，被当作选项的地址会让 git 从目标目录克隆并执行注入的命令
+		dst := filepath.Join(t.TempDir(), "dst")
+		runTestGit(t, "", "init", "--quiet", "--bare", dst)
+		if err := fetchGitTemplate(url, ref, dst); err == nil {
+			t.Errorf("ref %q: expected an error", ref)
+		}
+		if _, err := os.Stat(marker); !os.IsNotExist(err) {
+			t.Fatalf("ref %q: git ran the injected command", ref)
+		}
+	}
+}
+
+func TestExtractTarGzRejectsEscapingPaths(t *testing.T) {
+	for _, name := range []string{"../evil", "a/../../evil", "/etc/evil"} {
+		t.Run(name, func(t *testing.T) {
+			archive := tarGz(t, map[string]string{name: "x"})
+			if err := extractTarGz(bytes.NewReader(archive), t.TempDir()); err == nil {
+				t.Errorf("expected an error for %s", name)
+			}
+		})
+	}
+}
+
+// tarGz 生成包含指定文件的 tar.gz
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedKeys(files) {
+		content := files[name]
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -1,10 +1,11 @@
 package project
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"strings"
+
+	"devex/cmd/i18n"
 )
 
 // TemplateManager 模板管理器接口
@@ -12,27 +13,33 @@ import (
 type TemplateManager interface {
 	LoadTemplateCode(name string) (string, error)
 	RenderTemplateCode(name string, vars map[string]string) (string, error)
+	// RenderPath 渲染模板树中的文件名和目录名，如 {{.ProjectName}}/AppDelegate.swift
+	RenderPath(name string, vars map[string]string) (string, error)
 	// 实用方法
 	TemplateExists(name string) bool
 	ListTemplates() ([]string, error)
 }
 
 // FileTemplateManager 文件模板管理器通用实现
+// 模板从 fs.FS 中读取，可以是内置模板或外部模板目录，使用 text/template 渲染（语法见 template_render.go）
 type FileTemplateManager struct {
+	FS              fs.FS
 	TemplateCodeDir string
+	Strict          bool // 严格模式：引用未定义的变量时报错
 }
 
-func NewFileTemplateManager(templateCodeDir string) *FileTemplateManager {
+func NewFileTemplateManager(fsys fs.FS, templateCodeDir string) *FileTemplateManager {
 	return &FileTemplateManager{
+		FS:              fsys,
 		TemplateCodeDir: templateCodeDir,
+		Strict:          true,
 	}
 }
 
 func (m *FileTemplateManager) LoadTemplateCode(name string) (string, error) {
-	path := filepath.Join(m.TemplateCodeDir, name)
-	content, err := os.ReadFile(path)
+	content, err := fs.ReadFile(m.FS, path.Join(m.TemplateCodeDir, name))
 	if err != nil {
-		return "", fmt.Errorf("读取模板文件失败: %w", err)
+		return "", i18n.Errorf("template_manager.read_failed", err)
 	}
 	return string(content), nil
 }
@@ -42,37 +49,44 @@ func (m *FileTemplateManager) RenderTemplateCode(name string, vars map[string]st
 	if err != nil {
 		return "", err
 	}
+	return renderTemplate(name, content, vars, m.Strict)
+}
+
+// RenderPath 渲染模板路径，渲染结果可以包含 /（如包名目录），但不能跳出项目目录
+func (m *FileTemplateManager) RenderPath(name string, vars map[string]string) (string, error) {
+	rendered := name
+	if strings.Contains(name, "{{") || strings.Contains(name, "${") {
+		var err error
+		if rendered, err = renderTemplate(name, name, vars, m.Strict); err != nil {
+			return "", err
+		}
+	}
 
-	// 支持多种模板变量格式，方便不同语言使用
-	for k, v := range vars {
-		content = strings.ReplaceAll(content, "${"+k+"}", v)
-		content = strings.ReplaceAll(content, "{{"+k+"}}", v)
+	rendered = path.Clean(rendered)
+	if rendered == "." || path.IsAbs(rendered) || rendered == ".." || strings.HasPrefix(rendered, "../") {
+		return "", i18n.Errorf("template_manager.invalid_path", name, rendered)
 	}
-	return content, nil
+	return rendered, nil
 }
 
 // TemplateExists 检查模板是否存在
 func (m *FileTemplateManager) TemplateExists(name string) bool {
-	path := filepath.Join(m.TemplateCodeDir, name)
-	_, err := os.Stat(path)
-	return !os.IsNotExist(err)
+	_, err := fs.Stat(m.FS, path.Join(m.TemplateCodeDir, name))
+	return err == nil
 }
 
 // ListTemplates 列出所有可用的模板
 func (m *FileTemplateManager) ListTemplates() ([]string, error) {
 	var templates []string
 
-	err := filepath.Walk(m.TemplateCodeDir, func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(m.FS, m.TemplateCodeDir, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !info.IsDir() {
+		if !d.IsDir() {
 			// 获取相对于模板目录的路径
-			relPath, err := filepath.Rel(m.TemplateCodeDir, path)
-			if err != nil {
-				return err
-			}
+			relPath := strings.TrimPrefix(p, m.TemplateCodeDir+"/")
 			templates = append(templates, relPath)
 		}
 		return nil
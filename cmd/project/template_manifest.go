@@ -0,0 +1,499 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"devex/cmd/i18n"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateManifestFile 语言模板目录中描述模板的清单文件，与 code 目录同级
+const TemplateManifestFile = "template.yaml"
+
+// 模板变量类型
+const (
+	VarTypeString = "string"
+	VarTypeBool   = "bool"
+	VarTypeInt    = "int"
+)
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// TemplateManifest 语言模板清单：声明变量、文件映射和生成后执行的命令
+type TemplateManifest struct {
+	Name         string                `yaml:"name"`
+	Description  LocalizedText         `yaml:"description"`
+	Variables    []TemplateVariable    `yaml:"variables"`
+	Files        []TemplateFileMapping `yaml:"files"`         // 为空时渲染 code 目录下的所有文件
+	PostGenerate []TemplateCommand     `yaml:"post_generate"` // 依赖检查通过后在项目目录中执行，外部模板需要被信任
+}
+
+// TemplateVariable 模板变量
+type TemplateVariable struct {
+	Name        string        `yaml:"name" json:"name"`
+	Type        string        `yaml:"type" json:"type,omitempty"` // string（默认）、bool、int
+	Description LocalizedText `yaml:"description" json:"description,omitempty"`
+	Prompt      LocalizedText `yaml:"prompt" json:"prompt,omitempty"`   // 交互式输入时的提示，为空时使用 description
+	Default     string        `yaml:"default" json:"default,omitempty"` // 默认值，可以引用前面声明的变量，如 com.agora.{{.ProjectName | snakeCase}}
+	Required    bool          `yaml:"required" json:"required"`         // 没有默认值时必须提供
+	Pattern     string        `yaml:"pattern" json:"pattern,omitempty"` // 校验取值的正则表达式
+	Choices     []string      `yaml:"choices" json:"choices,omitempty"` // 可选值列表
+
+	pattern *regexp.Regexp
+}
+
+// TemplateFileMapping 模板文件到项目文件的映射
+type TemplateFileMapping struct {
+	Src    string `yaml:"src"`    // 相对于 code 目录的文件或目录，目录会递归包含其中所有文件
+	Dst    string `yaml:"dst"`    // 相对于项目目录的目标路径，可使用模板语法，默认与 src 相同，不能指向项目目录之外
+	Render *bool  `yaml:"render"` // 是否渲染内容，默认 true；false 时原样复制
+	When   string `yaml:"when"`   // 条件：bool 变量名，! 前缀表示取反
+}
+
+// TemplateCommand 项目文件生成后执行的命令
+type TemplateCommand struct {
+	Run         string        `yaml:"run"` // 通过 sh -c 执行，可使用模板语法
+	Description LocalizedText `yaml:"description"`
+	When        string        `yaml:"when"`
+}
+
+// LocalizedText 按界面语言显示的文字，可以是字符串，也可以是界面语言到文字的映射，如
+//
+//	description:
+//	  en: Project name
+//	  zh-CN: 项目名称
+//
+// 映射中没有当前界面语言时依次使用英文和任意一种语言
+type LocalizedText map[string]string
+
+// UnmarshalYAML 解析字符串或界面语言到文字的映射，语言名称按 i18n.Normalize 规范化
+func (t *LocalizedText) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = LocalizedText{"": node.Value}
+		return nil
+	}
+	var texts map[string]string
+	if err := node.Decode(&texts); err != nil {
+		return err
+	}
+	*t = make(LocalizedText, len(texts))
+	for lang, text := range texts {
+		if normalized, ok := i18n.Normalize(lang); ok {
+			lang = normalized
+		}
+		(*t)[lang] = text
+	}
+	return nil
+}
+
+// MarshalJSON 输出当前界面语言的文字
+func (t LocalizedText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// String 当前界面语言的文字
+func (t LocalizedText) String() string {
+	for _, lang := range []string{i18n.Locale(), i18n.DefaultLocale, ""} {
+		if text, ok := t[lang]; ok {
+			return text
+		}
+	}
+	langs := make([]string, 0, len(t))
+	for lang := range t {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	if len(langs) > 0 {
+		return t[langs[0]]
+	}
+	return ""
+}
+
+// generatedFile 根据清单展开后的单个生成文件
+type generatedFile struct {
+	Src    string
+	Dst    string
+	Render bool
+}
+
+// templateManifestPath 根据语言的代码模板路径获取清单路径
+func templateManifestPath(templateCodePath string) string {
+	return path.Join(path.Dir(templateCodePath), TemplateManifestFile)
+}
+
+// LoadTemplateManifest 读取并校验模板清单
+func LoadTemplateManifest(fsys fs.FS, manifestPath string) (*TemplateManifest, error) {
+	data, err := fs.ReadFile(fsys, manifestPath)
+	if err != nil {
+		return nil, i18n.Errorf("template_manifest.read_failed", err)
+	}
+
+	var manifest TemplateManifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && err != io.EOF {
+		return nil, i18n.Errorf("template_manifest.parse_failed", manifestPath, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, i18n.Errorf("template_manifest.invalid", manifestPath, err)
+	}
+	return &manifest, nil
+}
+
+// validate 校验清单中的变量声明和条件
+func (m *TemplateManifest) validate() error {
+	declared := make(map[string]string)
+	for i := range m.Variables {
+		v := &m.Variables[i]
+		if !variableNamePattern.MatchString(v.Name) {
+			return i18n.Errorf("template_manifest.bad_variable_name", v.Name)
+		}
+		if _, ok := declared[v.Name]; ok {
+			return i18n.Errorf("template_manifest.duplicate_variable", v.Name)
+		}
+		if v.Type == "" {
+			v.Type = VarTypeString
+		}
+		if v.Type != VarTypeString && v.Type != VarTypeBool && v.Type != VarTypeInt {
+			return i18n.Errorf("template_manifest.bad_type", v.Name, v.Type)
+		}
+		if v.Pattern != "" {
+			pattern, err := regexp.Compile(v.Pattern)
+			if err != nil {
+				return i18n.Errorf("template_manifest.bad_pattern", v.Name, err)
+			}
+			v.pattern = pattern
+		}
+		declared[v.Name] = v.Type
+	}
+
+	conditions := make([]string, 0, len(m.Files)+len(m.PostGenerate))
+	for _, file := range m.Files {
+		if file.Src == "" {
+			return i18n.Errorf("template_manifest.missing_src")
+		}
+		conditions = append(conditions, file.When)
+	}
+	for _, command := range m.PostGenerate {
+		if command.Run == "" {
+			return i18n.Errorf("template_manifest.missing_run")
+		}
+		conditions = append(conditions, command.When)
+	}
+	for _, when := range conditions {
+		if when == "" {
+			continue
+		}
+		name := strings.TrimPrefix(when, "!")
+		if declared[name] != VarTypeBool {
+			return i18n.Errorf("template_manifest.bad_when", when)
+		}
+	}
+	return nil
+}
+
+// Variable 按名称查找变量声明
+func (m *TemplateManifest) Variable(name string) (*TemplateVariable, bool) {
+	for i := range m.Variables {
+		if m.Variables[i].Name == name {
+			return &m.Variables[i], true
+		}
+	}
+	return nil, false
+}
+
+// Resolve 按声明顺序确定变量取值：优先使用 values 中的值，否则使用默认值，并按类型和规则校验
+// prompter 非空时，values 中没有的变量通过交互式输入获取（以默认值为建议值）
+func (m *TemplateManifest) Resolve(values map[string]string, prompter *Prompter) (map[string]string, error) {
+	for name := range values {
+		if _, ok := m.Variable(name); !ok {
+			return nil, i18n.Errorf("template_manifest.undeclared", m.Name, name)
+		}
+	}
+
+	if prompter != nil {
+		fmt.Fprintln(prompter.out, "📝 "+i18n.T("template_manifest.prompt"))
+	}
+
+	vars := make(map[string]string, len(m.Variables))
+	for i := range m.Variables {
+		v := &m.Variables[i]
+
+		value, ok := values[v.Name]
+		if !ok {
+			def, err := renderTemplate(v.Name, v.Default, vars, true)
+			if err != nil {
+				return nil, i18n.Errorf("template_manifest.default_failed", v.Name, err)
+			}
+
+			switch {
+			case prompter != nil:
+				if vars[v.Name], err = prompter.askVariable(v, def); err != nil {
+					return nil, err
+				}
+				continue
+			case def == "" && v.Required:
+				return nil, i18n.Errorf("template_manifest.missing_required",
+					v.Name, v.Description.String(), v.Name)
+			}
+			value = def
+		}
+
+		normalized, err := v.Validate(value)
+		if err != nil {
+			return nil, err
+		}
+		vars[v.Name] = normalized
+	}
+	return vars, nil
+}
+
+// Validate 按类型、可选值和正则校验变量取值，返回规范化后的值
+func (v *TemplateVariable) Validate(value string) (string, error) {
+	// 非必需的字符串变量可以为空
+	if value == "" && !v.Required && v.Type == VarTypeString {
+		return "", nil
+	}
+
+	switch v.Type {
+	case VarTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", i18n.Errorf("template_manifest.not_bool", v.Name, value)
+		}
+		value = strconv.FormatBool(b)
+	case VarTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", i18n.Errorf("template_manifest.not_int", v.Name, value)
+		}
+	}
+
+	if len(v.Choices) > 0 {
+		valid := false
+		for _, choice := range v.Choices {
+			if value == choice {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", i18n.Errorf("template_manifest.not_choice", v.Name, strings.Join(v.Choices, i18n.T("template_manifest.separator")), value)
+		}
+	}
+
+	if v.pattern != nil && !v.pattern.MatchString(value) {
+		return "", i18n.Errorf("template_manifest.pattern_mismatch", v.Name, value, v.Pattern)
+	}
+	return value, nil
+}
+
+// evalWhen 计算条件，空条件为真
+func evalWhen(when string, vars map[string]string) bool {
+	if when == "" {
+		return true
+	}
+	if name, negated := strings.CutPrefix(when, "!"); negated {
+		return vars[name] != "true"
+	}
+	return vars[when] == "true"
+}
+
+// expandFiles 根据文件映射展开需要生成的文件，目标路径已渲染
+func (m *TemplateManifest) expandFiles(templates TemplateManager, vars map[string]string) ([]generatedFile, error) {
+	all, err := templates.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := m.Files
+	if len(mappings) == 0 {
+		mappings = []TemplateFileMapping{{Src: "."}}
+	}
+
+	var files []generatedFile
+	for _, mapping := range mappings {
+		if !evalWhen(mapping.When, vars) {
+			continue
+		}
+
+		src := path.Clean(mapping.Src)
+		dst := mapping.Dst
+		if dst == "" {
+			dst = src
+		}
+		render := mapping.Render == nil || *mapping.Render
+
+		matched := false
+		for _, name := range all {
+			target := ""
+			switch {
+			case name == src:
+				target = dst
+			case src == ".":
+				target = path.Join(dst, name)
+			case strings.HasPrefix(name, src+"/"):
+				target = path.Join(dst, strings.TrimPrefix(name, src+"/"))
+			default:
+				continue
+			}
+
+			rendered, err := templates.RenderPath(target, vars)
+			if err != nil {
+				return nil, err
+			}
+			rendered, err = confineDst(rendered)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, generatedFile{Src: name, Dst: rendered, Render: render})
+			matched = true
+		}
+		if !matched {
+			return nil, i18n.Errorf("template_manifest.file_missing", mapping.Src)
+		}
+	}
+	return files, nil
+}
+
+// confineDst 规范化渲染后的目标路径，拒绝绝对路径和指向项目目录之外的路径
+func confineDst(dst string) (string, error) {
+	clean := path.Clean(dst)
+	if path.IsAbs(clean) || filepath.IsAbs(filepath.FromSlash(clean)) || filepath.VolumeName(filepath.FromSlash(clean)) != "" ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", i18n.Errorf("template_manifest.bad_dst", dst)
+	}
+	return clean, nil
+}
+
+// resolveTemplate 读取语言模板的清单，根据项目名称和用户提供的值确定变量取值
+func (b *BaseInitializer) resolveTemplate() (*TemplateManifest, map[string]string, error) {
+	manifest, err := LoadTemplateManifest(b.TemplateFS, templateManifestPath(b.TemplateCodePath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 配置文件中的 values.<变量名> 覆盖模板声明的默认值，未声明的变量忽略
+	for name, def := range settings.Values() {
+		if v, ok := manifest.Variable(name); ok {
+			v.Default = def
+		}
+	}
+
+	values := map[string]string{"ProjectName": b.ProjectName}
+	for k, v := range b.templateValues {
+		values[k] = v
+	}
+	vars, err := manifest.Resolve(values, b.prompter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest, vars, nil
+}
+
+// generateProjectFiles 根据模板清单生成项目文件
+func (b *BaseInitializer) generateProjectFiles(templates TemplateManager, manifest *TemplateManifest, vars map[string]string) error {
+	files, err := manifest.expandFiles(templates, vars)
+	if err != nil {
+		return err
+	}
+
+	b.report().Progress("📄", i18n.T("template_manifest.creating"))
+
+	for _, file := range files {
+		b.report().Detail(i18n.T("template_manifest.create", file.Dst))
+
+		var content string
+		if file.Render {
+			content, err = templates.RenderTemplateCode(file.Src, vars)
+		} else {
+			content, err = templates.LoadTemplateCode(file.Src)
+		}
+		if err != nil {
+			return i18n.Errorf("template_manifest.generate_failed", file.Dst, err)
+		}
+
+		mode := os.FileMode(0644)
+		if strings.HasPrefix(content, "#!") {
+			mode = 0755
+		}
+
+		filePath := filepath.Join(b.FilePath, filepath.FromSlash(file.Dst))
+		if err := b.mkdirAll(filepath.Dir(filePath)); err != nil {
+			return i18n.Errorf("template_manifest.mkdir_failed", err)
+		}
+		if err := b.writeFile(filePath, []byte(content), mode, ""); err != nil {
+			return i18n.Errorf("template_manifest.create_failed", file.Dst, err)
+		}
+	}
+	return nil
+}
+
+// postGenerateCommands 返回满足条件的生成后命令，Run 为渲染后的命令
+func (m *TemplateManifest) postGenerateCommands(vars map[string]string) ([]TemplateCommand, error) {
+	var commands []TemplateCommand
+	for _, command := range m.PostGenerate {
+		if !evalWhen(command.When, vars) {
+			continue
+		}
+		run, err := renderTemplate("post_generate", command.Run, vars, true)
+		if err != nil {
+			return nil, WithDefaultCode(ErrTemplate, err)
+		}
+		command.Run = run
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// runPostGenerate 在项目目录中执行 postGenerateCommands 返回的生成后命令，执行前输出命令，预演模式下只输出命令
+// 外部模板目录和模板源中的命令需要 --trust-template 或注册时标记为可信，否则跳过并提示手动执行
+func (b *BaseInitializer) runPostGenerate(commands []TemplateCommand) error {
+	allowed := postGenerateAllowed()
+	skipped := false
+	for _, command := range commands {
+		run := command.Run
+		if description := command.Description.String(); description != "" {
+			b.report().Info(i18n.T("template_manifest.running", description))
+		}
+		if b.plan != nil {
+			b.report().Detail(i18n.T("template_manifest.dry_run_skip", run))
+			continue
+		}
+		if !allowed {
+			b.report().Warn(WarnUntrustedTemplate, i18n.T("template_manifest.untrusted", run))
+			skipped = true
+			continue
+		}
+		b.report().Detail(i18n.T("template_manifest.command", run))
+
+		cmd := exec.Command("sh", "-c", run)
+		cmd.Dir = b.FilePath
+
+		// 捕获命令的输出
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = io.MultiWriter(b.report().Output(), &stdout)
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+		if err := cmd.Run(); err != nil {
+			return Errorf(ErrTemplate, "template_manifest.run_failed",
+				run, err, stdout.String(), stderr.String())
+		}
+	}
+	if skipped {
+		b.report().Hint(i18n.T("template_manifest.trust_hint"))
+	}
+	return nil
+}
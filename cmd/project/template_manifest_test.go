@@ -0,0 +1,250 @@
+package project
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"devex/cmd/i18n"
+	devextemplate "devex/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfineDst(t *testing.T) {
+	tests := []struct {
+		dst     string
+		want    string
+		wantErr bool
+	}{
+		{dst: "build.gradle.kts", want: "build.gradle.kts"},
+		{dst: "src/main/../main/App.kt", want: "src/main/App.kt"},
+		{dst: "./README.md", want: "README.md"},
+		{dst: "a/../../b", wantErr: true},
+		{dst: "../outside", wantErr: true},
+		{dst: "..", wantErr: true},
+		{dst: "/etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dst, func(t *testing.T) {
+			got, err := confineDst(tt.dst)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandFilesRejectsEscapingDst(t *testing.T) {
+	fsys := fstest.MapFS{"lang/code/hook.sh": {Data: []byte("#!/bin/sh\n")}}
+	templates := NewFileTemplateManager(fsys, "lang/code")
+
+	tests := []struct {
+		name    string
+		dst     string
+		wantErr bool
+	}{
+		{name: "inside project", dst: "scripts/{{.Name}}.sh"},
+		{name: "rendered parent path", dst: "{{.Name}}/../../.git/hooks/pre-commit", wantErr: true},
+		{name: "rendered absolute path", dst: "/tmp/{{.Name}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &TemplateManifest{Files: []TemplateFileMapping{{Src: "hook.sh", Dst: tt.dst}}}
+			files, err := manifest.expandFiles(templates, map[string]string{"Name": "demo"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v (files %v)", err, tt.wantErr, files)
+			}
+		})
+	}
+}
+
+func TestRunPostGenerateTrust(t *testing.T) {
+	tests := []struct {
+		name        string
+		templateDir string
+		trusted     bool
+		wantRun     bool
+	}{
+		{name: "builtin template", wantRun: true},
+		{name: "external template", templateDir: "templates", wantRun: false},
+		{name: "trusted external template", templateDir: "templates", trusted: true, wantRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldDir, oldTrusted := templateDir, templateTrusted
+			t.Cleanup(func() { templateDir, templateTrusted = oldDir, oldTrusted })
+			templateDir, templateTrusted = tt.templateDir, tt.trusted
+
+			dir := t.TempDir()
+			b := &BaseInitializer{FilePath: dir, reporter: NewHumanReporter(io.Discard)}
+			manifest := &TemplateManifest{PostGenerate: []TemplateCommand{{Run: "touch {{.Name}}"}}}
+			commands, err := manifest.postGenerateCommands(map[string]string{"Name": "marker"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.runPostGenerate(commands); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, "marker"))
+			if ran := err == nil; ran != tt.wantRun {
+				t.Errorf("command ran = %v, want %v", ran, tt.wantRun)
+			}
+		})
+	}
+}
+
+// TestRunPostGenerateFailure 生成后命令失败属于模板错误，而不是 Git 错误
+func TestRunPostGenerateFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		run  string
+	}{
+		{name: "command fails", run: "echo boom >&2; exit 3"},
+		{name: "undefined variable", run: "echo {{.Missing}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &BaseInitializer{FilePath: t.TempDir(), reporter: NewHumanReporter(io.Discard)}
+			manifest := &TemplateManifest{PostGenerate: []TemplateCommand{{Run: tt.run}}}
+			commands, err := manifest.postGenerateCommands(map[string]string{})
+			if err == nil {
+				err = b.runPostGenerate(commands)
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if code := ErrorCodeOf(err); code != ErrTemplate {
+				t.Errorf("code = %s, want %s", code, ErrTemplate)
+			}
+		})
+	}
+}
+
+// TestResolve --set、--values 提供的值优先于默认值和交互式输入；标准输入不是终端时缺少必需变量直接报错
+func TestResolve(t *testing.T) {
+	manifest := &TemplateManifest{Name: "demo", Variables: []TemplateVariable{
+		{Name: "Bundle", Required: true},
+		{Name: "Name", Default: "demo"},
+		{Name: "Team", Default: "{{.Name}}-team"},
+	}}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		input   string // 交互式输入，为空表示标准输入不是终端
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "defaults", values: map[string]string{"Bundle": "com.demo"}, want: map[string]string{"Bundle": "com.demo", "Name": "demo", "Team": "demo-team"}},
+		{name: "values override defaults", values: map[string]string{"Bundle": "com.app", "Name": "app"}, want: map[string]string{"Bundle": "com.app", "Name": "app", "Team": "app-team"}},
+		{name: "values are not prompted", values: map[string]string{"Name": "app"}, input: "com.app\n\n", want: map[string]string{"Bundle": "com.app", "Name": "app", "Team": "app-team"}},
+		{name: "prompt uses defaults", input: "com.demo\n\n\n", want: map[string]string{"Bundle": "com.demo", "Name": "demo", "Team": "demo-team"}},
+		{name: "required without terminal", values: map[string]string{"Name": "app"}, wantErr: "--set Bundle=<value>"},
+		{name: "undeclared value", values: map[string]string{"Bundle": "com.app", "Other": "x"}, wantErr: "Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := i18n.Locale()
+			t.Cleanup(func() { i18n.SetLocale(old) })
+			i18n.SetLocale(i18n.English)
+
+			var prompter *Prompter
+			if tt.input != "" {
+				prompter = NewPrompter(strings.NewReader(tt.input), io.Discard)
+			}
+			got, err := manifest.Resolve(tt.values, prompter)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizedText(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		locale string
+		want   string
+	}{
+		{name: "plain string", yaml: "description: 项目名称\n", locale: "en", want: "项目名称"},
+		{name: "current locale", yaml: "description:\n  en: Project name\n  zh-CN: 项目名称\n", locale: "zh-CN", want: "项目名称"},
+		{name: "normalized locale", yaml: "description:\n  en: Project name\n  zh_CN: 项目名称\n", locale: "zh-CN", want: "项目名称"},
+		{name: "english fallback", yaml: "description:\n  en: Project name\n  ja: プロジェクト名\n", locale: "zh-CN", want: "Project name"},
+		{name: "any locale", yaml: "description:\n  zh-CN: 项目名称\n", locale: "en", want: "项目名称"},
+	}
+	defer i18n.SetLocale(i18n.Locale())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := i18n.SetLocale(tt.locale); err != nil {
+				t.Fatal(err)
+			}
+			var v struct {
+				Description LocalizedText `yaml:"description"`
+			}
+			if err := yaml.Unmarshal([]byte(tt.yaml), &v); err != nil {
+				t.Fatal(err)
+			}
+			if got := v.Description.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if data, _ := json.Marshal(v.Description); string(data) != `"`+tt.want+`"` {
+				t.Errorf("json = %s", data)
+			}
+		})
+	}
+}
+
+// TestBuiltinTemplateTexts 内置模板的说明和提示都有每种界面语言的文字
+func TestBuiltinTemplateTexts(t *testing.T) {
+	for _, lang := range GetSupportedLanguages() {
+		config, _ := GetLanguageConfig(lang)
+		manifest, err := LoadTemplateManifest(devextemplate.FS, templateManifestPath(config.TemplateCodePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts := map[string]LocalizedText{"description": manifest.Description}
+		for _, v := range manifest.Variables {
+			texts[v.Name+".description"] = v.Description
+			if v.Prompt != nil {
+				texts[v.Name+".prompt"] = v.Prompt
+			}
+		}
+		for i, command := range manifest.PostGenerate {
+			texts["post_generate."+strconv.Itoa(i)] = command.Description
+		}
+		for name, text := range texts {
+			for _, locale := range i18n.Locales() {
+				if text[locale] == "" {
+					t.Errorf("%s: %s has no %s text", lang, name, locale)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package project
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devex/cmd/i18n"
+	devextemplate "devex/template"
+)
+
+// BuiltinTemplateSource 内置模板的来源名称
+const BuiltinTemplateSource = "builtin"
+
+// templateSourceNamePattern 注册的模板源名称
+var templateSourceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// TemplateRegistration 配置文件中注册的模板源
+type TemplateRegistration struct {
+	Source      string `yaml:"source"`                // Git 仓库、压缩包或本地路径，写法见 template_fetch.go
+	SHA256      string `yaml:"sha256,omitempty"`      // 固定的内容摘要
+	Description string `yaml:"description,omitempty"` // 说明
+	Trusted     bool   `yaml:"trusted,omitempty"`     // 是否信任模板中的生成后命令（post_generate）
+}
+
+// TemplateInfo 模板源中一种语言的模板
+type TemplateInfo struct {
+	Name        string            // 模板名：内置模板为语言名，注册的模板为 <模板源>/<语言名>
+	Language    string            // 语言
+	Description string            // 说明，来自 template.yaml
+	Source      string            // builtin 或模板源名称
+	Dir         string            // 语言模板目录（相对于模板根目录），如 swift
+	Manifest    *TemplateManifest // 模板清单，语言模板没有 template.yaml 时为 nil
+	FS          fs.FS             // 模板根文件系统
+}
+
+// RegisteredTemplateSource 注册的模板源及其中的模板
+type RegisteredTemplateSource struct {
+	Name         string
+	Registration TemplateRegistration
+	Digest       string         // 获取到的内容摘要
+	Templates    []TemplateInfo // 获取失败时为空
+	Err          error          // 获取失败的原因
+}
+
+// BuiltinTemplates 列出内置模板
+func BuiltinTemplates() ([]TemplateInfo, error) {
+	return listTemplates(devextemplate.FS, BuiltinTemplateSource)
+}
+
+// RegisteredTemplateSources 列出用户配置和仓库配置中注册的模板源，按名称排序
+// 每个模板源都会被获取（已缓存时直接使用缓存），获取失败记录在 Err 中
+func RegisteredTemplateSources() ([]RegisteredTemplateSource, error) {
+	templates, err := settings.Templates()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sources []RegisteredTemplateSource
+	for _, name := range names {
+		source := RegisteredTemplateSource{Name: name, Registration: templates[name]}
+		dir, digest, err := fetchRegisteredTemplate(source.Registration, "")
+		if err != nil {
+			source.Err = err
+		} else {
+			source.Digest = digest
+			source.Templates, source.Err = listTemplates(os.DirFS(dir), name)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// FindTemplate 根据模板名查找模板：内置模板为语言名（如 swift），注册的模板为 <模板源>/<语言名>
+func FindTemplate(name string) (*TemplateInfo, error) {
+	var templates []TemplateInfo
+	var err error
+
+	sourceName, lang, registered := strings.Cut(name, "/")
+	if !registered {
+		lang = name
+		templates, err = BuiltinTemplates()
+	} else {
+		sources, loadErr := settings.Templates()
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		registration, ok := sources[sourceName]
+		if !ok {
+			return nil, i18n.Errorf("template_registry.not_registered", sourceName)
+		}
+		dir, _, fetchErr := fetchRegisteredTemplate(registration, "")
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		templates, err = listTemplates(os.DirFS(dir), sourceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		if templates[i].Language == lang {
+			return &templates[i], nil
+		}
+	}
+	return nil, i18n.Errorf("template_registry.not_found", name)
+}
+
+// listTemplates 列出模板根目录中支持的语言模板
+func listTemplates(fsys fs.FS, source string) ([]TemplateInfo, error) {
+	var templates []TemplateInfo
+	for _, lang := range GetSupportedLanguages() {
+		config, err := GetLanguageConfig(lang)
+		if err != nil {
+			return nil, err
+		}
+		dir := path.Dir(config.ConfigPath)
+		if info, err := fs.Stat(fsys, dir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		name := lang
+		if source != BuiltinTemplateSource {
+			name = source + "/" + lang
+		}
+		info := TemplateInfo{
+			Name:        name,
+			Language:    lang,
+			Description: config.DisplayName,
+			Source:      source,
+			Dir:         dir,
+			FS:          fsys,
+		}
+
+		manifestPath := templateManifestPath(config.TemplateCodePath)
+		if _, err := fs.Stat(fsys, manifestPath); err == nil {
+			manifest, err := LoadTemplateManifest(fsys, manifestPath)
+			if err != nil {
+				return nil, i18n.Errorf("template_registry.template_error", name, err)
+			}
+			info.Manifest = manifest
+			if description := manifest.Description.String(); description != "" {
+				info.Description = description
+			}
+		}
+		templates = append(templates, info)
+	}
+	return templates, nil
+}
+
+// Files 列出模板目录中的所有文件和目录（目录以 / 结尾），按路径排序
+func (t *TemplateInfo) Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(t.FS, t.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == t.Dir {
+			return nil
+		}
+		rel := strings.TrimPrefix(p, t.Dir+"/")
+		if d.IsDir() {
+			rel += "/"
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("template_registry.read_dir", err)
+	}
+	return files, nil
+}
+
+// RegisterTemplateSource 在用户配置中注册模板源
+// 注册前会获取一次模板源以确认可用；pin 为 true 时把获取到的内容摘要固定下来
+func RegisterTemplateSource(name string, registration TemplateRegistration, pin bool) (string, error) {
+	if !templateSourceNamePattern.MatchString(name) {
+		return "", i18n.Errorf("template_registry.invalid_name", name)
+	}
+	if name == BuiltinTemplateSource {
+		return "", i18n.Errorf("template_registry.reserved", name)
+	}
+
+	source, err := ParseTemplateSource(registration.Source)
+	if err != nil {
+		return "", err
+	}
+	// 本地路径保存为绝对路径，在任意目录下都能使用
+	if !strings.Contains(source.Location, "://") && !isSCPLikeURL(source.Location) {
+		abs, err := filepath.Abs(source.Location)
+		if err != nil {
+			return "", err
+		}
+		registration.Source = strings.Replace(registration.Source, source.Location, abs, 1)
+	}
+
+	dir, digest, err := fetchRegisteredTemplate(registration, "")
+	if err != nil {
+		return "", err
+	}
+	templates, err := listTemplates(os.DirFS(dir), name)
+	if err != nil {
+		return "", err
+	}
+	if len(templates) == 0 {
+		return "", i18n.Errorf("template_registry.no_language", registration.Source)
+	}
+	if pin {
+		registration.SHA256 = digest
+	}
+
+	config, err := LoadUserConfig()
+	if err != nil {
+		return "", err
+	}
+	if err := config.SetTemplate(name, registration); err != nil {
+		return "", err
+	}
+	if err := config.Save(); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// UnregisterTemplateSource 从用户配置中删除模板源，缓存的模板内容保留
+func UnregisterTemplateSource(name string) error {
+	config, err := LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	if !config.DeleteTemplate(name) {
+		return i18n.Errorf("template_registry.not_in_user_config", name)
+	}
+	return config.Save()
+}
+
+// lookupRegisteredTemplate 按名称查找注册的模板源
+func lookupRegisteredTemplate(name string) (TemplateRegistration, bool) {
+	if !templateSourceNamePattern.MatchString(name) {
+		return TemplateRegistration{}, false
+	}
+	templates, err := settings.Templates()
+	if err != nil {
+		return TemplateRegistration{}, false
+	}
+	registration, ok := templates[name]
+	return registration, ok
+}
+
+// fetchRegisteredTemplate 获取注册的模板源，checksum 不为空时覆盖注册时固定的摘要
+func fetchRegisteredTemplate(registration TemplateRegistration, checksum string) (string, string, error) {
+	source, err := ParseTemplateSource(registration.Source)
+	if err != nil {
+		return "", "", err
+	}
+	if checksum == "" {
+		checksum = registration.SHA256
+	}
+	return source.Fetch(checksum)
+}
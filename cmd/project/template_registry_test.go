@@ -0,0 +1,106 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRegisterTemplateSource template add 把模板源写入用户配置，template remove 从用户配置中删除
+func TestRegisterTemplateSource(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	source := t.TempDir()
+	writeTestFile(t, filepath.Join(source, "swift", "config", ".swiftlint.yml"), "disabled_rules: []\n")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := filepath.Rel(wd, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		registration TemplateRegistration
+		pin          bool
+	}{
+		{name: "team", registration: TemplateRegistration{Source: source, Description: "team templates"}},
+		{name: "pinned", registration: TemplateRegistration{Source: source, Trusted: true}, pin: true},
+		{name: "relative", registration: TemplateRegistration{Source: relative}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest, err := RegisterTemplateSource(tt.name, tt.registration, tt.pin)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 重新读取用户配置文件，确认注册已写入
+			config, err := LoadUserConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			templates, err := config.Templates()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.registration
+			want.Source = source // 本地路径保存为绝对路径
+			if tt.pin {
+				want.SHA256 = digest
+			}
+			if got := templates[tt.name]; got != want {
+				t.Errorf("registered %+v, want %+v", got, want)
+			}
+
+			if err := UnregisterTemplateSource(tt.name); err != nil {
+				t.Fatal(err)
+			}
+			if config, err = LoadUserConfig(); err != nil {
+				t.Fatal(err)
+			}
+			if templates, err = config.Templates(); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := templates[tt.name]; ok {
+				t.Errorf("%s still registered after remove", tt.name)
+			}
+			if err := UnregisterTemplateSource(tt.name); err == nil {
+				t.Error("removing an unregistered source should fail")
+			}
+		})
+	}
+}
+
+// TestRegisterTemplateSourceRejected 无效的注册不写入用户配置
+func TestRegisterTemplateSourceRejected(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "bad/name", source: t.TempDir()},
+		{name: BuiltinTemplateSource, source: t.TempDir()},
+		{name: "empty", source: t.TempDir()}, // 没有支持的语言模板
+		{name: "missing", source: filepath.Join(t.TempDir(), "missing")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RegisterTemplateSource(tt.name, TemplateRegistration{Source: tt.source}, false); err == nil {
+				t.Fatal("expected an error")
+			}
+			path, err := UserConfigPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("user config written: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package project
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"devex/cmd/i18n"
+)
+
+// 模板语法：
+//   - {{.ProjectName}}、{{.ProjectName | snakeCase}} 等 text/template 语法，支持条件和循环
+//   - ${PROJECT_NAME} 为兼容旧模板的变量简写，变量名可以是 vars 中的键或其大写下划线形式
+//   - $${...} 输出字面量 ${...}，用于生成的 shell 脚本、Gradle 脚本中的变量（如 $${PODS_ROOT}）
+//
+// 严格模式下引用未定义的变量会报错，非严格模式下 {{.X}} 输出空字符串，${X} 原样保留
+
+// legacyVarPattern 旧模板变量 ${KEY}，前面的 $ 用于识别 $${...} 转义
+var legacyVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// templateFuncs 模板中可用的辅助函数
+var templateFuncs = template.FuncMap{
+	"pascalCase": pascalCase,
+	"camelCase":  camelCase,
+	"snakeCase":  snakeCase,
+	"kebabCase":  kebabCase,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+}
+
+// renderTemplate 渲染模板文本，name 用于错误信息
+func renderTemplate(name, text string, vars map[string]string, strict bool) (string, error) {
+	data := templateData(vars)
+
+	text, err := expandLegacyVars(text, data, strict)
+	if err != nil {
+		return "", i18n.Errorf("template_render.render_failed", name, err)
+	}
+
+	missingKey := "missingkey=zero"
+	if strict {
+		missingKey = "missingkey=error"
+	}
+	tmpl, err := template.New(name).Option(missingKey).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", i18n.Errorf("template_render.parse_failed", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", i18n.Errorf("template_render.render_failed", name, err)
+	}
+	return sb.String(), nil
+}
+
+// templateData 生成模板数据，每个变量同时可以用其大写下划线形式引用（ProjectName -> PROJECT_NAME）
+func templateData(vars map[string]string) map[string]string {
+	data := make(map[string]string, len(vars)*2)
+	for k, v := range vars {
+		data[strings.ToUpper(snakeCase(k))] = v
+	}
+	for k, v := range vars {
+		data[k] = v
+	}
+	return data
+}
+
+// expandLegacyVars 把 ${KEY} 替换为变量值，$${...} 替换为字面量 ${...}
+// 替换结果中的 {{ 会被转义，避免变量值被当作模板语法解析
+func expandLegacyVars(text string, data map[string]string, strict bool) (string, error) {
+	var missing []string
+	text = legacyVarPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		key := legacyVarPattern.FindStringSubmatch(match)[1]
+		value, ok := data[key]
+		if !ok {
+			missing = append(missing, key)
+			return match
+		}
+		return strings.ReplaceAll(value, "{{", `{{"{{"}}`)
+	})
+
+	if strict && len(missing) > 0 {
+		return "", i18n.Errorf("template_render.undefined", strings.Join(missing, ", "))
+	}
+	return text, nil
+}
+
+// splitWords 把标识符拆分为单词，支持空格、下划线、连字符、点分隔和驼峰形式
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := current[len(current)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// fooBar -> foo Bar；HTTPServer -> HTTP Server
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// pascalCase 转换为大驼峰形式，如 my-app -> MyApp，常用于类名和 Bundle ID
+func pascalCase(s string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(s) {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// camelCase 转换为小驼峰形式，如 my-app -> myApp
+func camelCase(s string) string {
+	pascal := []rune(pascalCase(s))
+	if len(pascal) == 0 {
+		return ""
+	}
+	pascal[0] = unicode.ToLower(pascal[0])
+	return string(pascal)
+}
+
+// snakeCase 转换为小写下划线形式，如 MyApp -> my_app，常用于包名
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// kebabCase 转换为小写连字符形式，如 MyApp -> my-app
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
@@ -0,0 +1,125 @@
+package project
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"ProjectName": "my-app", "BundleID": "com.example"}
+	tests := []struct {
+		name    string
+		text    string
+		strict  bool
+		want    string
+		wantErr bool
+	}{
+		{name: "template syntax", text: "{{.ProjectName}}", strict: true, want: "my-app"},
+		{name: "pipeline", text: "{{.ProjectName | pascalCase}}.swift", strict: true, want: "MyApp.swift"},
+		{name: "legacy upper snake", text: "name=${PROJECT_NAME}", strict: true, want: "name=my-app"},
+		{name: "legacy original key", text: "${BundleID}.${ProjectName}", strict: true, want: "com.example.my-app"},
+		{name: "escape", text: "$${PODS_ROOT}/${PROJECT_NAME}", strict: true, want: "${PODS_ROOT}/my-app"},
+		{name: "escape of defined variable", text: "$${PROJECT_NAME}", strict: true, want: "${PROJECT_NAME}"},
+		{name: "conditional", text: "{{if .BundleID}}id={{.BundleID}}{{end}}", strict: true, want: "id=com.example"},
+		{name: "legacy unknown strict", text: "${MISSING}", strict: true, wantErr: true},
+		{name: "legacy unknown lenient", text: "${MISSING}-${PROJECT_NAME}", want: "${MISSING}-my-app"},
+		{name: "template unknown strict", text: "{{.Missing}}", strict: true, wantErr: true},
+		{name: "template unknown lenient", text: "[{{.Missing}}]", want: "[]"},
+		{name: "parse error", text: "{{.ProjectName", strict: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate(tt.name, tt.text, vars, tt.strict)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExpandLegacyVarsEscapesValues 变量值中的 {{ 不会被当作模板语法解析
+func TestExpandLegacyVarsEscapesValues(t *testing.T) {
+	vars := map[string]string{"Greeting": "{{.Secret}}"}
+	got, err := renderTemplate("value", "${GREETING}", vars, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "{{.Secret}}" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCaseHelpers(t *testing.T) {
+	tests := []struct {
+		in                          string
+		pascal, camel, snake, kebab string
+	}{
+		{in: "my-app", pascal: "MyApp", camel: "myApp", snake: "my_app", kebab: "my-app"},
+		{in: "MyApp", pascal: "MyApp", camel: "myApp", snake: "my_app", kebab: "my-app"},
+		{in: "HTTPServer", pascal: "HttpServer", camel: "httpServer", snake: "http_server", kebab: "http-server"},
+		{in: "com.example app_v2", pascal: "ComExampleAppV2", camel: "comExampleAppV2", snake: "com_example_app_v2", kebab: "com-example-app-v2"},
+		{in: "", pascal: "", camel: "", snake: "", kebab: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := pascalCase(tt.in); got != tt.pascal {
+				t.Errorf("pascalCase = %q, want %q", got, tt.pascal)
+			}
+			if got := camelCase(tt.in); got != tt.camel {
+				t.Errorf("camelCase = %q, want %q", got, tt.camel)
+			}
+			if got := snakeCase(tt.in); got != tt.snake {
+				t.Errorf("snakeCase = %q, want %q", got, tt.snake)
+			}
+			if got := kebabCase(tt.in); got != tt.kebab {
+				t.Errorf("kebabCase = %q, want %q", got, tt.kebab)
+			}
+		})
+	}
+}
+
+func TestRenderPath(t *testing.T) {
+	m := NewFileTemplateManager(fstest.MapFS{}, "")
+	vars := map[string]string{"ProjectName": "MyApp", "PackagePath": "com/example/app", "Escape": "../.."}
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", path: "Podfile", want: "Podfile"},
+		{name: "template directory", path: "{{.ProjectName}}/AppDelegate.swift", want: "MyApp/AppDelegate.swift"},
+		{name: "legacy variable", path: "${PROJECT_NAME}.xcodeproj", want: "MyApp.xcodeproj"},
+		{name: "value with slashes", path: "src/{{.PackagePath}}/Main.kt", want: "src/com/example/app/Main.kt"},
+		{name: "cleaned", path: "a/./b/../c", want: "a/c"},
+		{name: "escapes project", path: "{{.Escape}}/x", wantErr: true},
+		{name: "absolute", path: "/etc/passwd", wantErr: true},
+		{name: "undefined variable", path: "{{.Missing}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.RenderPath(tt.path, vars)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package project
+
+import (
+	"io/fs"
+	"os"
+
+	"devex/cmd/i18n"
+	devextemplate "devex/template"
+)
+
+// TemplateDirEnv 指定外部模板目录的环境变量
+const TemplateDirEnv = "DEVEX_TEMPLATE_DIR"
+
+// templateDir 外部模板目录，为空时使用内置模板
+var templateDir = os.Getenv(TemplateDirEnv)
+
+// templateSourceSpec 模板源（Git 仓库、压缩包或本地路径），为空时使用内置模板
+var templateSourceSpec = os.Getenv(TemplateSourceEnv)
+
+// templateChecksum 模板源的固定摘要，为空时不校验
+var templateChecksum = os.Getenv(TemplateChecksumEnv)
+
+// templateTrusted 是否信任外部模板中的生成后命令（--trust-template）
+var templateTrusted bool
+
+// resolvedTemplate 已获取的模板源，同一次运行只获取一次
+var resolvedTemplate struct {
+	source string // 实际的模板源地址，注册的模板源会被替换为其地址
+	dir    string
+	digest string
+	err    error
+	done   bool
+}
+
+// SetTemplateSource 设置模板源
+func SetTemplateSource(spec string) {
+	templateSourceSpec = spec
+	resolvedTemplate.done = false
+}
+
+// SetTemplateChecksum 设置模板源的固定摘要
+func SetTemplateChecksum(checksum string) {
+	templateChecksum = checksum
+	resolvedTemplate.done = false
+}
+
+// GetTemplateSource 获取当前使用的模板源，为空表示使用内置模板
+func GetTemplateSource() string {
+	return templateSourceSpec
+}
+
+// SetTemplateTrusted 设置是否信任外部模板目录或模板源中的生成后命令
+func SetTemplateTrusted(trusted bool) {
+	templateTrusted = trusted
+}
+
+// postGenerateAllowed 是否允许执行模板清单中的生成后命令
+// 内置模板始终允许；外部模板需要 --trust-template，注册的模板源也可以在注册时用 --trust 标记为可信
+// 可信标记只认用户配置，仓库中的 .devex.yaml 不能把自己注册的模板源标记为可信
+func postGenerateAllowed() bool {
+	switch {
+	case templateTrusted:
+		return true
+	case templateSourceSpec != "":
+		if settings.User == nil {
+			return false
+		}
+		// 仓库配置中的同名模板源会覆盖用户配置，只有实际使用的仍是用户注册的地址时才可信
+		templates, err := settings.User.Templates()
+		registration, registered := lookupRegisteredTemplate(templateSourceSpec)
+		return err == nil && registered && templates[templateSourceSpec].Trusted &&
+			templates[templateSourceSpec].Source == registration.Source
+	default:
+		return templateDir == ""
+	}
+}
+
+// SetTemplateDir 设置外部模板目录，覆盖内置模板（用于模板开发调试）
+func SetTemplateDir(dir string) {
+	templateDir = dir
+}
+
+// GetTemplateDir 获取当前使用的外部模板目录，为空表示使用内置模板
+func GetTemplateDir() string {
+	return templateDir
+}
+
+// templateRoot 获取模板根目录的文件系统
+func templateRoot() (fs.FS, error) {
+	if templateSourceSpec != "" {
+		if templateDir != "" {
+			return nil, Errorf(ErrUsage, "template_source.both")
+		}
+		dir, _, err := resolveTemplateSource()
+		if err != nil {
+			return nil, WithCode(ErrTemplate, err)
+		}
+		return os.DirFS(dir), nil
+	}
+
+	if templateDir == "" {
+		return devextemplate.FS, nil
+	}
+
+	info, err := os.Stat(templateDir)
+	if err != nil {
+		return nil, Errorf(ErrTemplate, "template_source.dir_unavailable", err)
+	}
+	if !info.IsDir() {
+		return nil, Errorf(ErrTemplate, "template_source.not_dir", templateDir)
+	}
+	return os.DirFS(templateDir), nil
+}
+
+// PrepareTemplates 提前获取模板源，批量处理多个仓库前调用，避免各仓库并发获取
+func PrepareTemplates() error {
+	_, err := templateRoot()
+	return err
+}
+
+// getTemplatePath 检查模板目录是否存在，返回模板根文件系统及模板目录名
+func getTemplatePath(templateName string) (fs.FS, string, error) {
+	root, err := templateRoot()
+	if err != nil {
+		return nil, "", err
+	}
+
+	info, err := fs.Stat(root, templateName)
+	if err != nil || !info.IsDir() {
+		return nil, "", Errorf(ErrTemplate, "template_source.not_found", templateName)
+	}
+	return root, templateName, nil
+}
+
+// resolveTemplateSource 获取模板源，返回模板根目录及内容摘要
+func resolveTemplateSource() (string, string, error) {
+	if resolvedTemplate.done {
+		return resolvedTemplate.dir, resolvedTemplate.digest, resolvedTemplate.err
+	}
+
+	r := &resolvedTemplate
+	r.done = true
+
+	// 模板源可以是用户配置中注册的名称（见 devex template add）
+	registration, registered := lookupRegisteredTemplate(templateSourceSpec)
+	if !registered {
+		registration = TemplateRegistration{Source: templateSourceSpec}
+	}
+	r.source = registration.Source
+	r.dir, r.digest, r.err = fetchRegisteredTemplate(registration, templateChecksum)
+	if r.err != nil {
+		return "", "", r.err
+	}
+
+	if registered {
+		defaultReporter.Progress("📦", i18n.T("template_source.using_registered", templateSourceSpec, registration.Source, r.digest))
+	} else {
+		defaultReporter.Progress("📦", i18n.T("template_source.using", templateSourceSpec, r.digest))
+	}
+	return r.dir, r.digest, nil
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	devextemplate "devex/template"
+)
+
+// TestEmbeddedTemplateDotfiles 内置模板使用 all: 前缀嵌入，以点开头的文件不能丢失
+func TestEmbeddedTemplateDotfiles(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "gitignore", path: "global_config/.gitignore"},
+		{name: "gitleaks config", path: "global_config/.gitleaks.toml"},
+		{name: "pre-commit config", path: "global_config/.pre-commit-config.yaml"},
+		{name: "hooks dir", path: "global_config/.git-hooks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fs.Stat(devextemplate.FS, tt.path); err != nil {
+				t.Errorf("%s not embedded: %v", tt.path, err)
+			}
+		})
+	}
+}
+
+// TestGetTemplatePath --template-dir 或 DEVEX_TEMPLATE_DIR 指定的目录优先于内置模板
+func TestGetTemplatePath(t *testing.T) {
+	external := t.TempDir()
+	writeTestFile(t, filepath.Join(external, "swift", "marker"), "external\n")
+
+	tests := []struct {
+		name     string
+		dir      string
+		template string
+		exists   string    // 返回的文件系统中应存在的文件
+		want     ErrorCode // 为空表示成功
+	}{
+		{name: "builtin", template: "swift", exists: "swift/template.yaml"},
+		{name: "template dir", dir: external, template: "swift", exists: "swift/marker"},
+		{name: "missing in template dir", dir: external, template: "kotlin", want: ErrTemplate},
+		{name: "template dir unavailable", dir: filepath.Join(external, "missing"), template: "swift", want: ErrTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldDir, oldSource := templateDir, templateSourceSpec
+			t.Cleanup(func() { templateDir, templateSourceSpec = oldDir, oldSource })
+			templateDir, templateSourceSpec = tt.dir, ""
+
+			root, name, err := getTemplatePath(tt.template)
+			if tt.want != "" {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if code := ErrorCodeOf(err); code != tt.want {
+					t.Errorf("code = %s, want %s: %v", code, tt.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.template {
+				t.Errorf("name = %s, want %s", name, tt.template)
+			}
+			if _, err := fs.Stat(root, tt.exists); err != nil {
+				t.Errorf("%s not found: %v", tt.exists, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+plugins {
+    id("org.jlleitschuh.gradle.ktlint") version "12.1.1"
+    id("io.gitlab.arturbosch.detekt") version "1.23.6"
+    kotlin("jvm") version "1.9.24"
+    application
+}
+
+group = "com.example.demo"
+version = "1.0"
+
+dependencies {
+    testImplementation(kotlin("test"))
+}
+
+kotlin {
+    jvmToolchain(17)
+}
+
+application {
+    mainClass.set("com.example.demo.MainKt")
+}
+
+tasks.test {
+    useJUnitPlatform()
+}
+
+ktlint {
+    android.set(false)
+}
+
+detekt {
+    config.setFrom(files("config/detekt/detekt.yml"))
+    buildUponDefaultConfig = true
+}
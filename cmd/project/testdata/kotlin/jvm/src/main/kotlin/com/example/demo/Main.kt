@@ -0,0 +1,5 @@
+package com.example.demo
+
+fun main() {
+    println("Hello, Demo!")
+}
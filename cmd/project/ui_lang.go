@@ -0,0 +1,16 @@
+package project
+
+import (
+	"devex/cmd/i18n"
+)
+
+// UILangFlag 指定界面语言的命令行参数
+const UILangFlag = "lang-ui"
+
+// SetUILang 设置界面语言，lang 为空时按系统语言环境变量 LC_ALL、LC_MESSAGES、LANG 确定
+func SetUILang(lang string) error {
+	if err := i18n.SetLocale(lang); err != nil {
+		return WithCode(ErrUsage, err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package project
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"devex/cmd/i18n"
+)
+
+// Upgrader 模板升级器
+// 专门用于处理 devex upgrade 命令：根据 .devex.lock 把项目中的模板文件升级到当前 devex 版本
+type Upgrader struct {
+	BaseInitializer
+	oldManifest *Manifest
+	restore     bool // 是否恢复清单中记录、但已被用户删除的文件
+}
+
+// NewUpgrader 创建模板升级器
+func NewUpgrader(projectPath string) (*Upgrader, error) {
+	oldManifest, err := LoadManifest(projectPath)
+	if os.IsNotExist(err) {
+		return nil, Errorf(ErrConfig, "upgrade.no_manifest", ManifestFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	templateFS, globalConfigPath, err := getTemplatePath("global_config")
+	if err != nil {
+		return nil, i18n.Errorf("factory.template_not_found", err)
+	}
+
+	// 新版本模板中已不存在的语言配置层直接跳过
+	var layers []string
+	for _, layer := range oldManifest.Layers {
+		if info, err := fs.Stat(templateFS, layer); err == nil && info.IsDir() {
+			layers = append(layers, layer)
+		} else {
+			defaultReporter.Hint(i18n.T("upgrade.layer_removed", layer))
+		}
+	}
+
+	// CI 配置按 devex add 时的平台和分支重新生成
+	var ci CIOptions
+	if oldManifest.CI != nil && oldManifest.CI.Enabled() {
+		if info, err := fs.Stat(templateFS, oldManifest.CI.Layer()); err == nil && info.IsDir() {
+			ci = *oldManifest.CI
+		} else {
+			defaultReporter.Hint(i18n.T("upgrade.layer_removed", oldManifest.CI.Layer()))
+		}
+	}
+
+	return &Upgrader{
+		BaseInitializer: BaseInitializer{
+			TemplateFS:       templateFS,
+			ProjectName:      filepath.Base(projectPath),
+			FilePath:         projectPath,
+			GlobalConfigPath: globalConfigPath,
+			ConfigLayers:     layers,
+			CI:               ci,
+		},
+		oldManifest: oldManifest,
+	}, nil
+}
+
+// SetRestore 设置是否恢复用户删除的模板文件，默认跳过这些文件
+func (u *Upgrader) SetRestore(restore bool) {
+	u.restore = restore
+}
+
+// Upgrade 升级模板文件，语言配置层和 CI 配置与 devex add 时记录在 .devex.lock 中的一致
+// 内容与旧模板相同的文件直接更新；有合并策略的文件按策略合并；其他本地修改过的文件以旧模板为基准三方合并，无法合并时写入 .devex-new 并提示
+func (u *Upgrader) Upgrade() error {
+	u.report().Progress("⬆️ ", i18n.T("upgrade.start",
+		u.oldManifest.Version, u.oldManifest.Source, Version, templateSource()))
+
+	u.manifest = NewManifest()
+	u.manifest.Layers = u.ConfigLayers
+	var updated, merged, unchanged, deleted []string
+
+	files, err := u.templateFiles()
+	if err != nil {
+		return err
+	}
+	if u.CI.Enabled() {
+		ci := u.CI
+		u.manifest.CI = &ci
+	}
+
+	current := make(map[string]bool)
+	for _, file := range files {
+		current[file.Path] = true
+		dst := filepath.Join(u.FilePath, filepath.FromSlash(file.Path))
+		if err := u.mkdirAll(filepath.Dir(dst)); err != nil {
+			return err
+		}
+
+		result, err := u.upgradeFile(file.Path, dst, file.Content, file.Mode)
+		if err != nil {
+			return i18n.Errorf("upgrade.file_failed", file.Path, err)
+		}
+		switch result {
+		case "updated":
+			updated = append(updated, file.Path)
+		case "merged":
+			merged = append(merged, file.Path)
+		case "unchanged":
+			unchanged = append(unchanged, file.Path)
+		case "deleted":
+			deleted = append(deleted, file.Path)
+		}
+	}
+
+	// 模板中已移除的文件只做提示，不删除项目中的文件
+	var removed []string
+	for rel := range u.oldManifest.Files {
+		if !current[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(removed)
+
+	if err := u.writeManifest(); err != nil {
+		return i18n.Errorf("upgrade.write_failed", ManifestFile, err)
+	}
+	if u.plan != nil {
+		return nil
+	}
+
+	for _, rel := range updated {
+		u.report().Detail(i18n.T("upgrade.updated", rel))
+	}
+	for _, rel := range merged {
+		u.report().Detail(i18n.T("upgrade.merged", rel))
+	}
+	for _, conflict := range u.conflicts {
+		u.report().Warn(WarnMergeConflict, i18n.T("upgrade.conflict",
+			conflict.Path, conflict.Reason, conflict.SideFile))
+	}
+	for _, rel := range deleted {
+		u.report().Hint(i18n.T("upgrade.deleted", rel))
+	}
+	for _, rel := range removed {
+		u.report().Hint(i18n.T("upgrade.removed", rel))
+	}
+	u.report().Success(i18n.T("upgrade.done",
+		len(updated), len(merged), len(u.conflicts), len(unchanged)))
+	return nil
+}
+
+// upgradeFile 升级单个文件，返回处理结果：updated、merged、conflict、unchanged 或 deleted（用户删除的文件，未恢复）
+func (u *Upgrader) upgradeFile(rel, dst string, newTemplate []byte, mode os.FileMode) (string, error) {
+	current, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	exists := err == nil
+	entry, tracked := u.oldManifest.Files[rel]
+
+	switch {
+	case !exists && tracked && !u.restore:
+		// 清单中记录过、但已被用户删除的文件：保留记录并跳过，以后的升级同样跳过
+		u.manifest.Files[rel] = entry
+		return "deleted", nil
+
+	case !exists:
+		// 新增的模板文件，或指定恢复的用户删除了的文件：写入新模板
+		u.manifest.Record(rel, newTemplate, newTemplate)
+		return "updated", u.writeFile(dst, newTemplate, mode, i18n.T("upgrade.note_new"))
+
+	case bytes.Equal(current, newTemplate):
+		u.manifest.Record(rel, current, newTemplate)
+		return "unchanged", nil
+
+	case !tracked:
+		// 清单中没有记录的文件，按 devex add 的方式合并
+		written, err := u.mergeFile(dst, current, newTemplate, mode)
+		if err != nil || written == nil {
+			return "conflict", err
+		}
+		u.manifest.Record(rel, written, newTemplate)
+		return "merged", nil
+
+	case entry.Template == string(newTemplate):
+		// 模板没有变化，保留本地修改和原有记录
+		u.manifest.Files[rel] = entry
+		return "unchanged", nil
+
+	case string(current) == entry.Template:
+		// 本地内容与旧模板完全相同，直接更新
+		// 不能只比较清单中的校验和：add 时合并过的文件记录的是合并结果，其中包含用户自己的内容
+		u.manifest.Record(rel, newTemplate, newTemplate)
+		return "updated", u.writeFile(dst, newTemplate, mode, i18n.T("upgrade.note_unmodified"))
+	}
+
+	// 有合并策略的文件（如 .gitignore）按策略合并，保留用户自己的内容
+	if merge, ok := mergeStrategies[filepath.Base(dst)]; ok {
+		merged, err := merge(current, newTemplate)
+		if err != nil {
+			u.manifest.Files[rel] = entry
+			return "conflict", u.writeConflict(dst, newTemplate, mode, err.Error())
+		}
+		u.manifest.Record(rel, merged, newTemplate)
+		if bytes.Equal(merged, current) {
+			return "unchanged", nil
+		}
+		return "merged", u.writeFile(dst, merged, mode, i18n.T("upgrade.note_merged"))
+	}
+
+	// 其他文件以旧模板为基准三方合并
+	result, ok := merge3(entry.Template, string(current), string(newTemplate))
+	if !ok {
+		// 保留旧的清单记录，下次升级仍以旧模板为基准
+		u.manifest.Files[rel] = entry
+		return "conflict", u.writeConflict(dst, newTemplate, mode, i18n.T("upgrade.note_conflict"))
+	}
+	u.manifest.Record(rel, []byte(result), newTemplate)
+	return "merged", u.writeFile(dst, []byte(result), mode, i18n.T("upgrade.note_merged"))
+}
@@ -0,0 +1,174 @@
+package project
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		rel         string
+		current     string // 项目中的文件内容，为空表示文件不存在
+		entry       *ManifestEntry
+		newTemplate string
+		restore     bool // --restore
+		wantResult  string
+		want        string
+		wantSide    bool // 是否写入 .devex-new
+	}{
+		{
+			name:        "missing file is written",
+			rel:         "README.md",
+			newTemplate: "new\n",
+			wantResult:  "updated",
+			want:        "new\n",
+		},
+		{
+			// 清单中记录过的文件被用户删除，视为有意删除
+			name:        "deleted file is skipped",
+			rel:         "README.md",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("old\n")), Template: "old\n"},
+			newTemplate: "new\n",
+			wantResult:  "deleted",
+		},
+		{
+			name:        "deleted file is restored",
+			rel:         "README.md",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("old\n")), Template: "old\n"},
+			newTemplate: "new\n",
+			restore:     true,
+			wantResult:  "updated",
+			want:        "new\n",
+		},
+		{
+			name:        "unmodified file is updated",
+			rel:         "README.md",
+			current:     "old\n",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("old\n")), Template: "old\n"},
+			newTemplate: "new\n",
+			wantResult:  "updated",
+			want:        "new\n",
+		},
+		{
+			// add 时合并过的 .gitignore 记录的是合并结果的校验和，升级时不能被新模板覆盖
+			name:    "merged gitignore keeps user rules",
+			rel:     ".gitignore",
+			current: "node_modules\n\n# Added by devex\n.DS_Store\n",
+			entry: &ManifestEntry{
+				Checksum: checksum([]byte("node_modules\n\n# Added by devex\n.DS_Store\n")),
+				Template: ".DS_Store\n",
+			},
+			newTemplate: ".DS_Store\n.idea\n",
+			wantResult:  "merged",
+			want:        "node_modules\n\n# Added by devex\n.DS_Store\n\n# Added by devex\n.idea\n",
+		},
+		{
+			name:        "local edit is merged with three-way merge",
+			rel:         "docs/guide.md",
+			current:     "title\nlocal\nbody\n",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("title\nbody\n")), Template: "title\nbody\n"},
+			newTemplate: "title\nbody\nfooter\n",
+			wantResult:  "merged",
+			want:        "title\nlocal\nbody\nfooter\n",
+		},
+		{
+			name:        "unchanged template keeps local edit",
+			rel:         "docs/guide.md",
+			current:     "local\n",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("base\n")), Template: "base\n"},
+			newTemplate: "base\n",
+			wantResult:  "unchanged",
+			want:        "local\n",
+		},
+		{
+			name:        "conflicting edit writes side file",
+			rel:         "docs/guide.md",
+			current:     "mine\n",
+			entry:       &ManifestEntry{Checksum: checksum([]byte("base\n")), Template: "base\n"},
+			newTemplate: "theirs\n",
+			wantResult:  "conflict",
+			want:        "mine\n",
+			wantSide:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dst := filepath.Join(dir, filepath.FromSlash(tt.rel))
+			if tt.current != "" {
+				writeTestFile(t, dst, tt.current)
+			} else {
+				mkdir(t, filepath.Dir(dst))
+			}
+
+			old := NewManifest()
+			if tt.entry != nil {
+				old.Files[tt.rel] = *tt.entry
+			}
+			u := &Upgrader{
+				BaseInitializer: BaseInitializer{FilePath: dir, reporter: NewHumanReporter(io.Discard), manifest: NewManifest()},
+				oldManifest:     old,
+				restore:         tt.restore,
+			}
+
+			result, err := u.upgradeFile(tt.rel, dst, []byte(tt.newTemplate), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.wantResult {
+				t.Errorf("result = %s, want %s", result, tt.wantResult)
+			}
+			got, _ := os.ReadFile(dst)
+			if string(got) != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(dst + conflictSuffix); (err == nil) != tt.wantSide {
+				t.Errorf("side file exists = %v, want %v", err == nil, tt.wantSide)
+			}
+
+			// 合并结果记录到新清单中，新模板作为下次升级的基准
+			entry := u.manifest.Files[tt.rel]
+			if (result == "updated" || result == "merged") && (entry.Checksum != checksum(got) || entry.Template != tt.newTemplate) {
+				t.Errorf("unexpected manifest entry: %+v", entry)
+			}
+			if result == "deleted" && entry != *tt.entry {
+				t.Errorf("deleted file should keep its manifest entry: %+v", entry)
+			}
+			if tt.wantSide && !strings.Contains(entry.Template, "base") {
+				t.Errorf("conflict should keep the old base: %+v", entry)
+			}
+		})
+	}
+}
+
+// TestUpgradeRefreshesHooks 升级时重新安装钩子，过期的 devex 钩子更新为当前版本，串联的原有钩子保留
+func TestUpgradeRefreshesHooks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DEVEX_HOOKS", "")
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeTestFile(t, filepath.Join(repo, RepoConfigFile), "hooks: [pre-commit, commit-msg]\n")
+	preCommit, _ := findGitHook("pre-commit")
+	commitMsg, _ := findGitHook("commit-msg")
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	writeTestFile(t, filepath.Join(hooksDir, "pre-commit"), string(renderHook(preCommit))+"# old\n")
+	writeTestFile(t, filepath.Join(hooksDir, "commit-msg"), "#!/bin/sh\nexit 0\n")
+
+	u := &Upgrader{BaseInitializer: BaseInitializer{FilePath: repo, reporter: NewHumanReporter(io.Discard)}}
+	if err := u.InstallGitHooks(); err != nil {
+		t.Fatal(err)
+	}
+	for _, hook := range []gitHook{preCommit, commitMsg} {
+		got, _ := os.ReadFile(filepath.Join(hooksDir, hook.Name))
+		if string(got) != string(renderHook(hook)) {
+			t.Errorf("%s not refreshed:\n%s", hook.Name, got)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(hooksDir, "commit-msg"+chainedHookSuffix)); string(got) != "#!/bin/sh\nexit 0\n" {
+		t.Errorf("the existing commit-msg hook was not chained: %q", got)
+	}
+}
@@ -1,53 +1,106 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+	"strings"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	templateDir      string
+	templateSource   string
+	templateChecksum string
+	uiLang           string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "devex",
-	Short: "DevEx CLI 是一个帮助开发者优化开发体验的工具",
-	Long: `DevEx CLI 是一个帮助开发者优化开发体验的工具。
+	Short: i18n.T("cmd.root.short"),
+	Long:  i18n.T("cmd.root.long"),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// 写入 .devex.lock 的版本号
+		project.SetVersion(Version)
 
-` + `
+		// 先按命令行参数设置输出格式，加载配置失败时也以该格式输出错误
+		if err := setOutput(outputFormat); err != nil {
+			fail(err)
+		}
 
-使用示例：
-  # 创建一个新的 Swift 项目
-  devex init --remote https://github.com/username/myapp.git
+		// 加载配置，未指定的参数使用环境变量或配置文件中的值
+		if err := loadSettings(cmd); err != nil {
+			fail(project.WithDefaultCode(project.ErrConfig, err))
+		}
+		if err := setOutput(outputFormat); err != nil {
+			fail(err)
+		}
+		if err := project.SetUILang(uiLang); err != nil {
+			fail(err)
+		}
 
-  # 为当前目录的项目添加代码审查功能
-  devex add 
+		// 命令行中明确指定的模板目录或模板源优先于环境变量和配置文件中的另一种
+		flags := cmd.Flags()
+		switch {
+		case flags.Changed("template-dir") && !flags.Changed("template"):
+			templateSource = ""
+			project.SetTemplateSource("")
+		case flags.Changed("template") && !flags.Changed("template-dir"):
+			templateDir = ""
+			project.SetTemplateDir("")
+		}
 
-  # 查看版本信息
-  devex version`,
+		// 指定了外部模板目录时覆盖内置模板
+		if templateDir != "" {
+			project.SetTemplateDir(templateDir)
+		}
+
+		// 指定了模板源（Git 仓库、压缩包或本地路径）时从模板源获取模板
+		if templateSource != "" {
+			project.SetTemplateSource(templateSource)
+		}
+		if templateChecksum != "" {
+			project.SetTemplateChecksum(templateChecksum)
+		}
+	},
 }
 
 func init() {
 	// 禁用默认的completion命令
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
-	// 自定义帮助模板
-	rootCmd.SetUsageTemplate(`使用方法：
-  {{.CommandPath}} [命令]
-
-命令列表：
-{{- range .Commands}}{{if .IsAvailableCommand}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}
+	// 错误由 Execute 按输出格式统一输出
+	rootCmd.SilenceErrors = true
 
-参数选项：
-{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+	// 自定义帮助模板（见 localizeCommands），参数说明中的默认值按界面语言输出
+	cobra.AddTemplateFunc("localFlagUsages", localFlagUsages)
 
-查看命令详细信息请使用: "{{.CommandPath}} [命令] --help"
-`)
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", project.OutputText, i18n.T("cmd.root.flag.output"))
+	rootCmd.PersistentFlags().StringVar(&uiLang, project.UILangFlag, "", i18n.T("cmd.root.flag.lang_ui", strings.Join(i18n.Locales(), ", ")))
+	rootCmd.PersistentFlags().StringVar(&templateDir, "template-dir", "", i18n.T("cmd.root.flag.template_dir", project.TemplateDirEnv))
+	rootCmd.PersistentFlags().StringVar(&templateSource, "template", "", i18n.T("cmd.root.flag.template", project.TemplateSourceEnv))
+	rootCmd.PersistentFlags().StringVar(&templateChecksum, "template-sha256", "", i18n.T("cmd.root.flag.template_sha256", project.TemplateChecksumEnv))
+	bindConfig(rootCmd.PersistentFlags(), "output", "output")
+	bindConfig(rootCmd.PersistentFlags(), project.UILangFlag, "ui.lang")
+	bindConfig(rootCmd.PersistentFlags(), "template-dir", "template.dir")
+	bindConfig(rootCmd.PersistentFlags(), "template", "template.source")
+	bindConfig(rootCmd.PersistentFlags(), "template-sha256", "template.sha256")
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	// 先确定界面语言，帮助文字和参数解析的错误按该语言输出；不支持的语言在 PersistentPreRun 中报错
+	if project.SetUILang(resolveUILang(os.Args[1:])) != nil {
+		project.SetUILang("")
+	}
+	localizeCommands(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		// 参数解析失败时 PersistentPreRun 没有执行，按已解析到的 --output 设置输出格式
+		if setOutput(outputFormat) != nil {
+			setOutput(project.OutputText)
+		}
+		fail(project.WithCode(project.ErrUsage, localizeCobraError(err)))
 	}
 }
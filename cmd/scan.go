@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanPath       string
+	scanConfig     string
+	scanEngine     string
+	scanReportPath string
+	scanSince      string
+	scanBaseline   string
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: i18n.T("cmd.scan.short"),
+	Long:  i18n.T("cmd.scan.long"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		scanner := project.NewSecretScanner(scanPath, scanConfig, scanEngine, scanReportPath)
+		scanner.Since = scanSince
+		scanner.BaselinePath = scanBaseline
+		result, err := scanner.Scan()
+		if err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			reporter.Result("scan", result)
+		} else if result.Engine == project.ScanEngineBuiltin {
+			printScanFindings(result)
+		}
+		if result.Leaks {
+			exitFindings(i18n.T("cmd.scan.leaks"))
+		}
+	},
+}
+
+// printScanFindings 输出内置扫描器的发现
+func printScanFindings(result *project.ScanResult) {
+	if scanEngine != project.ScanEngineBuiltin {
+		fmt.Println("ℹ️  " + i18n.T("cmd.scan.builtin"))
+	}
+	scope := i18n.T("cmd.scan.scope_staged")
+	switch scanSince {
+	case "":
+	case project.EmptyTreeID:
+		scope = i18n.T("cmd.scan.scope_history")
+	default:
+		scope = i18n.T("cmd.scan.scope_range", scanSince)
+	}
+	if result.Baselined > 0 {
+		fmt.Println("ℹ️  " + i18n.T("cmd.scan.baselined", result.Baselined))
+	}
+	if !result.Leaks {
+		fmt.Println("✅ " + i18n.T("cmd.scan.clean", scope))
+		return
+	}
+
+	for _, finding := range result.Findings {
+		fmt.Print("\n🔑 " + i18n.T("cmd.scan.rule", finding.RuleID))
+		if finding.Description != "" {
+			fmt.Printf(" (%s)", finding.Description)
+		}
+		fmt.Println("\n   " + i18n.T("cmd.scan.file", finding.File, finding.StartLine))
+		if finding.Commit != "" {
+			fmt.Println("   " + i18n.T("cmd.scan.commit", finding.Commit, finding.Author, finding.Email))
+		}
+		fmt.Println("   " + i18n.T("cmd.scan.match", finding.Match))
+		if finding.Secret != "" && finding.Secret != finding.Match {
+			fmt.Println("   " + i18n.T("cmd.scan.secret", finding.Secret))
+		}
+		if finding.Entropy != 0 {
+			fmt.Println("   " + i18n.T("cmd.scan.entropy", finding.Entropy))
+		}
+		fmt.Println("   " + i18n.T("cmd.scan.fingerprint", finding.Fingerprint))
+	}
+
+	fmt.Println("\n❌ " + i18n.T("cmd.scan.found", scope, len(result.Findings)))
+	fmt.Println("💡 " + i18n.T("cmd.scan.hint"))
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+
+	scanCmd.Flags().StringVarP(&scanPath, "path", "p", ".", i18n.T("cmd.flags.repo_path"))
+	scanCmd.Flags().StringVarP(&scanConfig, "config", "c", "", i18n.T("cmd.scan.flag.config"))
+	scanCmd.Flags().StringVar(&scanEngine, "engine", project.ScanEngineAuto, i18n.T("cmd.scan.flag.engine"))
+	scanCmd.Flags().StringVarP(&scanReportPath, "report-path", "r", "", i18n.T("cmd.scan.flag.report_path"))
+	scanCmd.Flags().StringVar(&scanSince, "since", "", i18n.T("cmd.scan.flag.since"))
+	scanCmd.Flags().StringVar(&scanBaseline, "baseline", project.SecretsBaselineFile, i18n.T("cmd.flags.baseline"))
+	bindConfig(scanCmd.Flags(), "path", "path")
+	bindConfig(scanCmd.Flags(), "baseline", "secrets.baseline")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"devex/cmd/gitleaks"
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretsPath     string
+	secretsBaseline string
+	secretsRule     string
+	secretsReason   string
+	secretsExpires  string
+	secretsExpired  bool
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: i18n.T("cmd.secrets.short"),
+	Long:  i18n.T("cmd.secrets.long"),
+}
+
+var secretsBaselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: i18n.T("cmd.secrets.baseline.short"),
+	Long:  i18n.T("cmd.secrets.baseline.long"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		scanner := project.NewSecretScanner(secretsPath, "", project.ScanEngineBuiltin, "")
+		scanner.BaselinePath = secretsBaseline
+		result, err := scanner.WriteBaseline()
+		if err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			reporter.Result("secrets_baseline", map[string]interface{}{"path": scanner.BaselinePath, "findings": result.Findings})
+			return
+		}
+
+		counts := make(map[string]int)
+		var rules []string
+		for _, finding := range result.Findings {
+			if counts[finding.RuleID] == 0 {
+				rules = append(rules, finding.RuleID)
+			}
+			counts[finding.RuleID]++
+		}
+		for _, rule := range rules {
+			fmt.Printf("  - %s: %d\n", rule, counts[rule])
+		}
+		fmt.Println("✅ " + i18n.T("cmd.secrets.baseline.done", len(result.Findings), scanner.BaselinePath))
+	},
+}
+
+var secretsIgnoreCmd = &cobra.Command{
+	Use:         "ignore",
+	Annotations: map[string]string{argsAnnotation: "cmd.secrets.ignore.args"},
+	Short:       i18n.T("cmd.secrets.ignore.short"),
+	Long:        i18n.T("cmd.secrets.ignore.long"),
+	Args:        cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		expires, err := project.ParseIgnoreExpiry(secretsExpires)
+		if err != nil {
+			fail(err)
+		}
+		ignore, err := secretsIgnoreArg(args)
+		if err != nil {
+			fail(err)
+		}
+		ignore.Reason = secretsReason
+		ignore.Expires = expires
+
+		ignores, err := project.NewSecretIgnores(secretsPath)
+		if err != nil {
+			fail(err)
+		}
+		if err := ignores.Add(ignore); err != nil {
+			fail(err)
+		}
+		if err := ignores.Save(); err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			reporter.Result("secrets_ignore", ignore)
+			return
+		}
+		fmt.Println("✅ " + i18n.T("cmd.secrets.ignore.added", project.DescribeIgnore(ignore)))
+		if expires != "" {
+			fmt.Println("   " + i18n.T("cmd.secrets.ignore.expires", expires))
+		}
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("cmd.secrets.list.short"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ignores, err := project.NewSecretIgnores(secretsPath)
+		if err != nil {
+			fail(err)
+		}
+
+		list := ignores.List()
+		if jsonOutput() {
+			reporter.Result("secrets_list", list)
+			return
+		}
+		if len(list) == 0 {
+			fmt.Println(i18n.T("cmd.secrets.list.empty"))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("cmd.secrets.list.header"))
+		for _, ignore := range list {
+			expires := ignore.Expires
+			if expires == "" {
+				expires = "-"
+			} else if project.IgnoreExpired(ignore) {
+				expires += " ⚠️ " + i18n.T("cmd.secrets.list.expired")
+			}
+			rule := ignore.Rule
+			if rule == "" {
+				rule = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", ignore.Kind, ignore.Value, rule, ignore.Reason, ignore.Added, expires)
+		}
+		w.Flush()
+
+		if expired := project.ExpiredIgnores(list); len(expired) > 0 {
+			fmt.Println("\n⚠️  " + i18n.T("cmd.secrets.list.expired_hint", len(expired)))
+		}
+	},
+}
+
+var secretsRemoveCmd = &cobra.Command{
+	Use:         "remove",
+	Annotations: map[string]string{argsAnnotation: "cmd.secrets.remove.args"},
+	Short:       i18n.T("cmd.secrets.remove.short"),
+	Long:        i18n.T("cmd.secrets.remove.long"),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if secretsExpired {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ignores, err := project.NewSecretIgnores(secretsPath)
+		if err != nil {
+			fail(err)
+		}
+
+		var removed []gitleaks.Ignore
+		if secretsExpired {
+			removed, err = ignores.RemoveExpired()
+		} else {
+			var ignore gitleaks.Ignore
+			if ignore, err = secretsIgnoreArg(args); err == nil {
+				err = ignores.Remove(ignore)
+				removed = append(removed, ignore)
+			}
+		}
+		if err != nil {
+			fail(err)
+		}
+		if err := ignores.Save(); err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			reporter.Result("secrets_remove", removed)
+			return
+		}
+		if len(removed) == 0 {
+			fmt.Println(i18n.T("cmd.secrets.remove.none_expired"))
+			return
+		}
+		for _, ignore := range removed {
+			fmt.Println("🗑️  " + i18n.T("cmd.secrets.remove.removed", project.DescribeIgnore(ignore)))
+		}
+	},
+}
+
+// secretsIgnoreArg 根据命令行参数构造忽略项
+func secretsIgnoreArg(args []string) (gitleaks.Ignore, error) {
+	ignore := gitleaks.Ignore{Kind: args[0], Value: args[1]}
+	switch ignore.Kind {
+	case project.IgnoreKindPath:
+		ignore.Rule = secretsRule
+	case project.IgnoreKindRule, project.IgnoreKindFingerprint:
+		if secretsRule != "" {
+			return ignore, project.Errorf(project.ErrUsage, "cmd.secrets.rule_path_only")
+		}
+	default:
+		return ignore, project.Errorf(project.ErrUsage, "cmd.secrets.unsupported_kind", ignore.Kind,
+			project.IgnoreKindPath, project.IgnoreKindRule, project.IgnoreKindFingerprint)
+	}
+	return ignore, nil
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsBaselineCmd, secretsIgnoreCmd, secretsListCmd, secretsRemoveCmd)
+
+	secretsCmd.PersistentFlags().StringVarP(&secretsPath, "path", "p", ".", i18n.T("cmd.flags.repo_path"))
+	bindConfig(secretsCmd.PersistentFlags(), "path", "path")
+
+	secretsBaselineCmd.Flags().StringVar(&secretsBaseline, "baseline", project.SecretsBaselineFile, i18n.T("cmd.secrets.flag.baseline"))
+	bindConfig(secretsBaselineCmd.Flags(), "baseline", "secrets.baseline")
+
+	secretsIgnoreCmd.Flags().StringVar(&secretsRule, "rule", "", i18n.T("cmd.secrets.flag.rule"))
+	secretsIgnoreCmd.Flags().StringVar(&secretsReason, "reason", "", i18n.T("cmd.secrets.flag.reason"))
+	secretsIgnoreCmd.Flags().StringVar(&secretsExpires, "expires", "", i18n.T("cmd.secrets.flag.expires"))
+	secretsIgnoreCmd.MarkFlagRequired("reason")
+
+	secretsRemoveCmd.Flags().StringVar(&secretsRule, "rule", "", i18n.T("cmd.secrets.flag.remove_rule"))
+	secretsRemoveCmd.Flags().BoolVar(&secretsExpired, "expired", false, i18n.T("cmd.secrets.flag.expired"))
+}
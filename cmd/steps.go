@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+)
+
+// step 命令执行流程中的一个步骤
+type step struct {
+	name string
+	fn   func() error
+}
+
+// runSteps 依次执行步骤，所有修改记录在 journal 中
+// 任一步骤失败或收到中断信号（Ctrl-C）时回滚全部修改，恢复原始文件和权限
+func runSteps(steps []step, journal *project.Journal) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	stop := watchInterrupt(signals, reporter, journal, func() {
+		failf(project.ErrInterrupted, "cmd.steps.aborted")
+	})
+	defer stop()
+
+	return execSteps(reporter, steps, journal)
+}
+
+// watchInterrupt 收到 signals 中的信号时回滚 journal，然后调用 abort 退出
+// 返回的函数停止监听并等待监听结束，步骤正常结束后调用
+func watchInterrupt(signals <-chan os.Signal, r project.Reporter, journal *project.Journal, abort func()) func() {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-signals:
+			r.Info("\n⚠️  " + i18n.T("cmd.steps.interrupted"))
+			rollback(r, journal)
+			abort()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// execSteps 依次执行步骤并通过 r 输出，任一步骤失败时回滚 journal
+// 不处理中断信号，批量执行时由调用方统一回滚各仓库
+func execSteps(r project.Reporter, steps []step, journal *project.Journal) error {
+	for _, s := range steps {
+		r.StepStart(s.name)
+		err := s.fn()
+		r.StepFinish(s.name, err)
+		if err != nil {
+			rollback(r, journal)
+			return i18n.Errorf("cmd.steps.failed", s.name, err)
+		}
+	}
+	return nil
+}
+
+// planSteps 在预演模式下执行步骤，变更只记录到 plan 中，最后输出预演计划
+func planSteps(steps []step, plan *project.Plan) error {
+	return execPlan(reporter, os.Stdout, steps, plan)
+}
+
+// execPlan 在预演模式下执行步骤并通过 r 输出，文本输出中预演计划写入 w
+func execPlan(r project.Reporter, w io.Writer, steps []step, plan *project.Plan) error {
+	for _, s := range steps {
+		plan.BeginStep(s.name)
+		r.StepStart(s.name)
+		err := s.fn()
+		r.StepFinish(s.name, err)
+		if err != nil {
+			return i18n.Errorf("cmd.steps.failed", s.name, err)
+		}
+	}
+
+	if jsonOutput() {
+		r.Result("plan", plan.Changes)
+	} else {
+		plan.Print(w)
+	}
+	return nil
+}
+
+// rollback 回滚 journal 中记录的修改并通过 r 输出结果
+func rollback(r project.Reporter, journal *project.Journal) {
+	if err := journal.Rollback(); err != nil {
+		r.Warn(project.WarnRollbackFailed, i18n.T("cmd.steps.rollback_failed", err))
+		return
+	}
+	r.Info("  ↩️  " + i18n.T("cmd.steps.rolled_back"))
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devex/cmd/project"
+)
+
+// writeStep 通过 journal 写入文件的步骤
+func writeStep(journal *project.Journal, path, content string) step {
+	return step{name: "write " + filepath.Base(path), fn: func() error {
+		return journal.Write(path, func() error {
+			return os.WriteFile(path, []byte(content), 0644)
+		})
+	}}
+}
+
+func TestExecStepsRollsBackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("original\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	newDir := filepath.Join(dir, "new", "nested")
+	created := filepath.Join(newDir, "created.txt")
+
+	journal := project.NewJournal()
+	ranAfterFailure := false
+	steps := []step{
+		writeStep(journal, existing, "changed\n"),
+		{name: "mkdir", fn: func() error {
+			if err := journal.TrackDir(newDir); err != nil {
+				return err
+			}
+			return os.MkdirAll(newDir, 0755)
+		}},
+		writeStep(journal, created, "created\n"),
+		{name: "fail", fn: func() error { return errors.New("boom") }},
+		{name: "after", fn: func() error { ranAfterFailure = true; return nil }},
+	}
+
+	err := execSteps(project.NewHumanReporter(io.Discard), steps, journal)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if ranAfterFailure {
+		t.Error("steps after the failure ran")
+	}
+	if got, _ := os.ReadFile(existing); string(got) != "original\n" {
+		t.Errorf("existing file not restored: %q", got)
+	}
+	if info, err := os.Stat(existing); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("existing file mode not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Errorf("created directory not removed: %v", err)
+	}
+}
+
+func TestWatchInterruptRollsBackBeforeAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "created.txt")
+	journal := project.NewJournal()
+	if err := writeStep(journal, path, "x\n").fn(); err != nil {
+		t.Fatal(err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	aborted := make(chan bool, 1)
+	stop := watchInterrupt(signals, project.NewHumanReporter(io.Discard), journal, func() {
+		// abort 调用时回滚已经完成
+		_, err := os.Stat(path)
+		aborted <- os.IsNotExist(err)
+	})
+	defer stop()
+
+	signals <- os.Interrupt
+	select {
+	case rolledBack := <-aborted:
+		if !rolledBack {
+			t.Error("abort ran before the rollback")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("abort was not called")
+	}
+
+	// 回滚后仍在执行的步骤不能再写入
+	if err := writeStep(journal, path, "late\n").fn(); err == nil {
+		t.Error("write after interrupt succeeded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file written after interrupt: %v", err)
+	}
+}
+
+func TestWatchInterruptStop(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	journal := project.NewJournal()
+	stop := watchInterrupt(signals, project.NewHumanReporter(io.Discard), journal, func() {
+		t.Error("abort called after stop")
+	})
+	stop()
+
+	// 停止监听后的信号不再触发回滚
+	signals <- os.Interrupt
+	time.Sleep(10 * time.Millisecond)
+	if err := journal.Write(filepath.Join(t.TempDir(), "f"), func() error { return nil }); err != nil {
+		t.Errorf("journal rolled back after stop: %v", err)
+	}
+}
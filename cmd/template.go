@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	templateAddSHA256      string
+	templateAddPin         bool
+	templateAddDescription string
+	templateAddTrust       bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: i18n.T("cmd.template.short"),
+	Long:  i18n.T("cmd.template.long"),
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("cmd.template.list.short"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		builtin, err := project.BuiltinTemplates()
+		if err != nil {
+			fail(err)
+		}
+		sources, err := project.RegisteredTemplateSources()
+		if err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			var entries []templateEntry
+			for _, t := range builtin {
+				entries = append(entries, newTemplateEntry(t))
+			}
+			for _, source := range sources {
+				if source.Err != nil {
+					reporter.Warn(project.WarnTemplateSource, i18n.T("cmd.template.list.unavailable", source.Name, source.Err))
+					continue
+				}
+				for _, t := range source.Templates {
+					entries = append(entries, newTemplateEntry(t))
+				}
+			}
+			reporter.Result("templates", entries)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("cmd.template.list.header"))
+		for _, t := range builtin {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, t.Language, i18n.T("cmd.template.builtin"), t.Description)
+		}
+		for _, source := range sources {
+			if source.Err != nil {
+				fmt.Fprintf(w, "%s/*\t-\t%s\t❌ %s\n", source.Name, source.Name, source.Err)
+				continue
+			}
+			for _, t := range source.Templates {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, t.Language, source.Name, t.Description)
+			}
+		}
+		w.Flush()
+
+		if len(sources) == 0 {
+			fmt.Println("\n💡 " + i18n.T("cmd.template.list.add_hint"))
+			return
+		}
+
+		fmt.Println("\n" + i18n.T("cmd.template.list.registered"))
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, source := range sources {
+			pinned := i18n.T("cmd.template.list.unpinned")
+			if source.Registration.SHA256 != "" {
+				pinned = i18n.T("cmd.template.list.pinned", source.Registration.SHA256)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", source.Name, source.Registration.Source, pinned, source.Registration.Description)
+		}
+		w.Flush()
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:         "show",
+	Annotations: map[string]string{argsAnnotation: "cmd.template.args.template"},
+	Short:       i18n.T("cmd.template.show.short"),
+	Long:        i18n.T("cmd.template.show.long"),
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		t, err := project.FindTemplate(args[0])
+		if err != nil {
+			fail(err)
+		}
+		files, err := t.Files()
+		if err != nil {
+			fail(err)
+		}
+
+		if jsonOutput() {
+			entry := newTemplateEntry(*t)
+			if t.Manifest != nil {
+				entry.Variables = t.Manifest.Variables
+			}
+			entry.Files = files
+			reporter.Result("template", entry)
+			return
+		}
+
+		source := i18n.T("cmd.template.builtin")
+		if t.Source != project.BuiltinTemplateSource {
+			source = t.Source
+		}
+		fmt.Println(i18n.T("cmd.template.show.name", t.Name))
+		fmt.Println(i18n.T("cmd.template.show.language", t.Language))
+		fmt.Println(i18n.T("cmd.template.show.source", source))
+		fmt.Println(i18n.T("cmd.template.show.description", t.Description))
+
+		fmt.Println("\n" + i18n.T("cmd.template.show.variables"))
+		if t.Manifest == nil || len(t.Manifest.Variables) == 0 {
+			fmt.Println("  " + i18n.T("cmd.template.show.none"))
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "  "+i18n.T("cmd.template.show.variables_header"))
+			for _, v := range t.Manifest.Variables {
+				varType := v.Type
+				if varType == "" {
+					varType = project.VarTypeString
+				}
+				if len(v.Choices) > 0 {
+					varType += " (" + strings.Join(v.Choices, "|") + ")"
+				}
+				required := ""
+				if v.Required {
+					required = i18n.T("cmd.template.show.required")
+				}
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", v.Name, varType, v.Default, required, v.Description.String())
+			}
+			w.Flush()
+		}
+
+		fmt.Println("\n" + i18n.T("cmd.template.show.files"))
+		fmt.Printf("  %s/\n", t.Dir)
+		for _, file := range files {
+			depth := strings.Count(strings.TrimSuffix(file, "/"), "/")
+			name := path.Base(file)
+			if strings.HasSuffix(file, "/") {
+				name += "/"
+			}
+			fmt.Printf("  %s%s\n", strings.Repeat("  ", depth+1), name)
+		}
+	},
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:         "add",
+	Annotations: map[string]string{argsAnnotation: "cmd.template.args.name_source"},
+	Short:       i18n.T("cmd.template.add.short"),
+	Long:        i18n.T("cmd.template.add.long"),
+	Args:        cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		registration := project.TemplateRegistration{
+			Source:      args[1],
+			SHA256:      templateAddSHA256,
+			Description: templateAddDescription,
+			Trusted:     templateAddTrust,
+		}
+
+		digest, err := project.RegisterTemplateSource(name, registration, templateAddPin)
+		if err != nil {
+			fail(err)
+		}
+
+		reporter.Success(i18n.T("cmd.template.add.done", name, digest))
+		if !templateAddPin && templateAddSHA256 == "" {
+			reporter.Hint(i18n.T("cmd.template.add.unpinned"))
+		}
+	},
+}
+
+var templateRemoveCmd = &cobra.Command{
+	Use:         "remove",
+	Annotations: map[string]string{argsAnnotation: "cmd.template.args.name"},
+	Short:       i18n.T("cmd.template.remove.short"),
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := project.UnregisterTemplateSource(args[0]); err != nil {
+			fail(err)
+		}
+		reporter.Success(i18n.T("cmd.template.remove.done", args[0]))
+	},
+}
+
+// templateEntry JSON 输出中的模板
+type templateEntry struct {
+	Name        string                     `json:"name"`
+	Language    string                     `json:"language"`
+	Source      string                     `json:"source"`
+	Description string                     `json:"description,omitempty"`
+	Variables   []project.TemplateVariable `json:"variables,omitempty"`
+	Files       []string                   `json:"files,omitempty"`
+}
+
+func newTemplateEntry(t project.TemplateInfo) templateEntry {
+	return templateEntry{Name: t.Name, Language: t.Language, Source: t.Source, Description: t.Description}
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd, templateShowCmd, templateAddCmd, templateRemoveCmd)
+
+	templateAddCmd.Flags().StringVar(&templateAddSHA256, "sha256", "", i18n.T("cmd.template.flag.sha256"))
+	templateAddCmd.Flags().BoolVar(&templateAddPin, "pin", false, i18n.T("cmd.template.flag.pin"))
+	templateAddCmd.Flags().StringVarP(&templateAddDescription, "description", "d", "", i18n.T("cmd.template.flag.description"))
+	templateAddCmd.Flags().BoolVar(&templateAddTrust, "trust", false, i18n.T("cmd.template.flag.trust"))
+}
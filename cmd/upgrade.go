@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"devex/cmd/i18n"
+	"devex/cmd/project"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradePath      string
+	upgradeDryRun    bool
+	upgradeRestore   bool
+	upgradeCIVersion string
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: i18n.T("cmd.upgrade.short"),
+	Long:  i18n.T("cmd.upgrade.long"),
+	Run: func(cmd *cobra.Command, args []string) {
+		upgrader, err := project.NewUpgrader(upgradePath)
+		if err != nil {
+			fail(err)
+		}
+		upgrader.SetReporter(reporter)
+		upgrader.SetRestore(upgradeRestore)
+		// 没有指定 --ci-version 时沿用 .devex.lock 中记录的版本
+		if upgradeCIVersion != "" && upgrader.CI.Enabled() {
+			upgrader.CI.Version = upgradeCIVersion
+		}
+		if err := upgrader.CI.Validate(); err != nil {
+			fail(err)
+		}
+
+		steps := []step{
+			{i18n.T("cmd.steps.upgrade_templates"), upgrader.Upgrade},
+			{i18n.T("cmd.steps.install_hooks"), upgrader.InstallGitHooks},
+		}
+
+		if upgradeDryRun {
+			plan := project.NewPlan(upgradePath)
+			upgrader.SetDryRun(plan)
+			if err := planSteps(steps, plan); err != nil {
+				fail(err)
+			}
+			return
+		}
+
+		journal := project.NewJournal()
+		upgrader.SetJournal(journal)
+		if err := runSteps(steps, journal); err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().StringVarP(&upgradePath, "path", "p", ".", i18n.T("cmd.flags.path"))
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, i18n.T("cmd.flags.dry_run"))
+	upgradeCmd.Flags().BoolVar(&upgradeRestore, "restore", false, i18n.T("cmd.upgrade.flag.restore"))
+	upgradeCmd.Flags().StringVar(&upgradeCIVersion, "ci-version", "", i18n.T("cmd.flags.ci_version"))
+	bindConfig(upgradeCmd.Flags(), "path", "path")
+	bindConfig(upgradeCmd.Flags(), "ci-version", "ci.version")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"devex/cmd/i18n"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadTemplateValues 读取模板变量取值：先读取 --values 文件，再用 --set 覆盖
+func loadTemplateValues(valuesFile string, sets []string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	if valuesFile != "" {
+		data, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, i18n.Errorf("cmd.values.read_failed", err)
+		}
+
+		// 解码为 yaml.Node 以保留标量的原始写法，如 15.0 不会变成 15
+		var raw map[string]yaml.Node
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, i18n.Errorf("cmd.values.parse_failed", valuesFile, err)
+		}
+		for key, node := range raw {
+			if node.Kind != yaml.ScalarNode {
+				return nil, i18n.Errorf("cmd.values.bad_value", valuesFile, key)
+			}
+			if node.Tag == "!!null" {
+				values[key] = ""
+				continue
+			}
+			values[key] = node.Value
+		}
+	}
+
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, i18n.Errorf("cmd.values.bad_set", set)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadTemplateValues --set 覆盖 --values 文件中的同名变量
+func TestLoadTemplateValues(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("Name: demo\nVersion: 15.0\nTeam: ~\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		sets    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "values file", file: valuesFile, want: map[string]string{"Name": "demo", "Version": "15.0", "Team": ""}},
+		{name: "set only", sets: []string{"Name=app"}, want: map[string]string{"Name": "app"}},
+		{name: "set overrides values", file: valuesFile, sets: []string{"Name=app", "Team=ios"}, want: map[string]string{"Name": "app", "Version": "15.0", "Team": "ios"}},
+		{name: "last set wins", sets: []string{"Name=a", "Name=b"}, want: map[string]string{"Name": "b"}},
+		{name: "value with equals", sets: []string{" Flags =a=b"}, want: map[string]string{"Flags": "a=b"}},
+		{name: "set without equals", sets: []string{"Name"}, wantErr: true},
+		{name: "set without key", sets: []string{"=app"}, wantErr: true},
+		{name: "missing values file", file: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTemplateValues(tt.file, tt.sets)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
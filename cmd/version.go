@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 
+	"devex/cmd/i18n"
+
 	"github.com/spf13/cobra"
 )
 
@@ -15,15 +17,20 @@ var (
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "显示版本信息",
-	Long:  "显示DevEx CLI的版本、构建时间和提交哈希信息。",
+	Short: i18n.T("cmd.version.short"),
+	Long:  i18n.T("cmd.version.long"),
 	Run: func(cmd *cobra.Command, args []string) {
+		if jsonOutput() {
+			reporter.Result("version", map[string]string{"version": Version, "build_time": BuildTime, "commit": CommitHash})
+			return
+		}
+
 		fmt.Printf("DevEx CLI %s\n", Version)
 		if BuildTime != "unknown" {
-			fmt.Printf("构建时间: %s\n", BuildTime)
+			fmt.Println(i18n.T("cmd.version.build_time", BuildTime))
 		}
 		if CommitHash != "unknown" {
-			fmt.Printf("提交哈希: %s\n", CommitHash)
+			fmt.Println(i18n.T("cmd.version.commit", CommitHash))
 		}
 	},
 }
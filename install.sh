@@ -8,6 +8,7 @@ set -e
 REPO="pandaBilbo/agora-cli"  # 请替换为您的实际仓库
 INSTALL_DIR="/usr/local/bin"
 BINARY_NAME="devex"
+VERSION=""  # 为空时安装最新版本
 
 # 颜色输出
 RED='\033[0;31m'
@@ -56,13 +57,38 @@ get_latest_version() {
     curl -s "https://api.github.com/repos/${REPO}/releases/latest" | grep '"tag_name":' | sed -E 's/.*"([^"]+)".*/\1/'
 }
 
+# 校验下载文件的 SHA-256，校验和来自同一发布中的 checksums.txt
+verify_checksum() {
+    local file="$1"
+    local name="$2"
+    local expected actual
+
+    expected=$(awk -v name="$name" '$2 == name || $2 == "*" name {print $1}' checksums.txt)
+    if [ -z "$expected" ]; then
+        print_red "checksums.txt 中没有 $name 的校验和"
+        exit 1
+    fi
+
+    if command -v sha256sum >/dev/null 2>&1; then
+        actual=$(sha256sum "$file" | awk '{print $1}')
+    else
+        actual=$(shasum -a 256 "$file" | awk '{print $1}')
+    fi
+    if [ "$expected" != "$actual" ]; then
+        print_red "校验和不匹配: $name (期望 $expected, 实际 $actual)"
+        exit 1
+    fi
+}
+
 # 主安装函数
 install_devex() {
     print_green "🚀 开始安装 DevEx CLI..."
     
     OS=$(get_os)
     ARCH=$(get_arch)
-    VERSION=$(get_latest_version)
+    if [ -z "$VERSION" ]; then
+        VERSION=$(get_latest_version)
+    fi
     
     if [ -z "$VERSION" ]; then
         print_red "无法获取最新版本信息"
@@ -70,7 +96,7 @@ install_devex() {
     fi
     
     print_yellow "检测到系统: $OS/$ARCH"
-    print_yellow "最新版本: $VERSION"
+    print_yellow "安装版本: $VERSION"
     
     # 构建下载URL
     FILENAME="${BINARY_NAME}-${OS}-${ARCH}"
@@ -87,11 +113,18 @@ install_devex() {
     
     # 下载并解压
     print_yellow "正在下载 $BINARY_NAME $VERSION..."
-    if ! curl -L "$DOWNLOAD_URL" -o "${BINARY_NAME}.tar.gz"; then
+    if ! curl -fL "$DOWNLOAD_URL" -o "${BINARY_NAME}.tar.gz"; then
         print_red "下载失败"
         exit 1
     fi
     
+    print_yellow "正在校验..."
+    if ! curl -fsSL "https://github.com/${REPO}/releases/download/${VERSION}/checksums.txt" -o checksums.txt; then
+        print_red "无法下载校验和文件 checksums.txt"
+        exit 1
+    fi
+    verify_checksum "${BINARY_NAME}.tar.gz" "${BINARY_NAME}-${OS}-${ARCH}.tar.gz"
+    
     print_yellow "正在解压..."
     tar -xzf "${BINARY_NAME}.tar.gz"
     
@@ -111,17 +144,9 @@ install_devex() {
     # 检查权限并安装
     if [ -w "$INSTALL_DIR" ]; then
         cp "$BINARY_PATH" "$INSTALL_DIR/$BINARY_NAME"
-        # 复制template目录到二进制文件同级目录
-        if [ -d "${BINARY_NAME}-${OS}-${ARCH}/template" ]; then
-            cp -r "${BINARY_NAME}-${OS}-${ARCH}/template" "$INSTALL_DIR/"
-        fi
     else
         print_yellow "需要管理员权限安装到 $INSTALL_DIR"
         sudo cp "$BINARY_PATH" "$INSTALL_DIR/$BINARY_NAME"
-        # 复制template目录到二进制文件同级目录
-        if [ -d "${BINARY_NAME}-${OS}-${ARCH}/template" ]; then
-            sudo cp -r "${BINARY_NAME}-${OS}-${ARCH}/template" "$INSTALL_DIR/"
-        fi
     fi
     
     # 设置可执行权限
@@ -172,6 +197,7 @@ DevEx CLI 安装脚本
 选项:
     -h, --help          显示此帮助信息
     -d, --dir DIR       指定安装目录 (默认: $INSTALL_DIR)
+    -v, --version VER   指定安装版本，如 v1.0.0 (默认: 最新版本)
     --repo REPO         指定GitHub仓库 (默认: $REPO)
 
 示例:
@@ -181,6 +207,9 @@ DevEx CLI 安装脚本
     # 安装到指定目录
     $0 --dir ~/bin
     
+    # 安装指定版本
+    $0 --version v1.0.0
+    
     # 从指定仓库安装
     $0 --repo username/repo-name
 
@@ -198,6 +227,10 @@ while [[ $# -gt 0 ]]; do
             INSTALL_DIR="$2"
             shift 2
             ;;
+        -v|--version)
+            VERSION="$2"
+            shift 2
+            ;;
         --repo)
             REPO="$2"
             shift 2
@@ -0,0 +1,10 @@
+// Package template 内置 devex 使用的模板文件
+package template
+
+import "embed"
+
+// FS 编译进二进制的模板目录树
+// 使用 all: 前缀以包含 .gitignore、.git-hooks 等以点开头的文件
+//
+//go:embed all:global_config all:swift all:kotlin all:ci
+var FS embed.FS
@@ -0,0 +1,5 @@
+package {{.PackageName}}
+
+fun main() {
+    println("Hello, {{.ProjectName}}!")
+}
@@ -0,0 +1,47 @@
+plugins {
+{{- if eq .CodeReview "true"}}
+    id("org.jlleitschuh.gradle.ktlint") version "12.1.1"
+    id("io.gitlab.arturbosch.detekt") version "1.23.6"
+{{- end}}
+    id("com.android.application") version "8.5.2"
+    id("org.jetbrains.kotlin.android") version "1.9.24"
+}
+
+android {
+    namespace = "{{.PackageName}}"
+    compileSdk = 34
+
+    defaultConfig {
+        applicationId = "{{.PackageName}}"
+        minSdk = {{.MinSdk}}
+        targetSdk = 34
+        versionCode = 1
+        versionName = "1.0"
+    }
+
+    buildTypes {
+        release {
+            isMinifyEnabled = false
+        }
+    }
+
+    compileOptions {
+        sourceCompatibility = JavaVersion.VERSION_17
+        targetCompatibility = JavaVersion.VERSION_17
+    }
+
+    kotlinOptions {
+        jvmTarget = "17"
+    }
+}
+{{- if eq .CodeReview "true"}}
+
+ktlint {
+    android.set(true)
+}
+
+detekt {
+    config.setFrom(files("config/detekt/detekt.yml"))
+    buildUponDefaultConfig = true
+}
+{{- end}}
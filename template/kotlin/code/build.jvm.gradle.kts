@@ -0,0 +1,38 @@
+plugins {
+{{- if eq .CodeReview "true"}}
+    id("org.jlleitschuh.gradle.ktlint") version "12.1.1"
+    id("io.gitlab.arturbosch.detekt") version "1.23.6"
+{{- end}}
+    kotlin("jvm") version "1.9.24"
+    application
+}
+
+group = "{{.PackageName}}"
+version = "1.0"
+
+dependencies {
+    testImplementation(kotlin("test"))
+}
+
+kotlin {
+    jvmToolchain(17)
+}
+
+application {
+    mainClass.set("{{.PackageName}}.MainKt")
+}
+
+tasks.test {
+    useJUnitPlatform()
+}
+{{- if eq .CodeReview "true"}}
+
+ktlint {
+    android.set(false)
+}
+
+detekt {
+    config.setFrom(files("config/detekt/detekt.yml"))
+    buildUponDefaultConfig = true
+}
+{{- end}}